@@ -0,0 +1,58 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// oidAttestationConfigHash is the extension carrying the SM3 hash of the plugin configuration.
+var oidAttestationConfigHash = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}
+
+// attestKey issues a self-signed certificate for key carrying the configuration hash and
+// appends it to the attestation log. It returns the id of the log entry.
+func (p *MyPlugin) attestKey(conn redisConn, key *sm2.PrivateKey) (string, error) {
+	extension, err := asn1.Marshal(p.configHash)
+	if err != nil {
+		return "", err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:       serial,
+		Subject:            pkix.Name{CommonName: "gmsmPlugin generated key"},
+		NotBefore:          now,
+		NotAfter:           now.AddDate(1, 0, 0),
+		SignatureAlgorithm: x509.SM2WithSM3,
+		ExtraExtensions:    []pkix.Extension{{Id: oidAttestationConfigHash, Value: extension}},
+	}
+	der, err := x509.CreateCertificate(template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", err
+	}
+
+	reply, err := redisDo(conn, "XADD", p.attestationLogKey, "*",
+		"cert", base64.StdEncoding.EncodeToString(der),
+		"ts", strconv.FormatInt(now.Unix(), 10),
+	)
+	if err != nil {
+		return "", err
+	}
+	id, ok := reply.([]byte)
+	if !ok {
+		return "", fmt.Errorf("unexpected XADD reply %v", reply)
+	}
+	return string(id), nil
+}
@@ -0,0 +1,60 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// batchResult is one entry of the batch hashing response, in input order.
+type batchResult struct {
+	Input string `json:"input"`
+	Hash  string `json:"hash"`
+}
+
+// serveBatchHash hashes each string of a JSON array body independently with SM3. With
+// BatchStoreInRedis each hash is also stored as <prefix>:batch:<hash> with the hash TTL.
+func (p *MyPlugin) serveBatchHash(conn redisConn, rw http.ResponseWriter, req *http.Request, body []byte) {
+	if !isJSONRequest(req) {
+		p.writeError(rw, http.StatusUnsupportedMediaType, "batch hashing requires application/json")
+		return
+	}
+	var inputs []string
+	if err := json.Unmarshal(body, &inputs); err != nil {
+		p.writeError(rw, http.StatusBadRequest, "body must be a JSON array of strings")
+		return
+	}
+	if len(inputs) > p.maxBatchSize {
+		p.writeError(rw, http.StatusBadRequest, "batch exceeds maxBatchSize of "+strconv.Itoa(p.maxBatchSize))
+		return
+	}
+
+	results := make([]batchResult, len(inputs))
+	for i, input := range inputs {
+		hasher := sm3.New()
+		hasher.Write([]byte(input))
+		results[i] = batchResult{Input: input, Hash: hex.EncodeToString(hasher.Sum(nil))}
+	}
+
+	if p.batchStoreInRedis {
+		for _, result := range results {
+			key := p.keyPrefix(req) + ":batch:" + result.Hash
+			var err error
+			if p.hashTTL > 0 {
+				_, err = conn.SetEx(key, p.hashTTL, "1")
+			} else {
+				_, err = conn.Set(key, "1")
+			}
+			if err != nil {
+				// 写入失败不影响返回结果
+				p.logger.Error("写入批量 hash 失败", logFields{"error": err})
+				break
+			}
+		}
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"results": results, "code": 0})
+}
@@ -0,0 +1,36 @@
+package gmsmPlugin
+
+import (
+	"encoding/binary"
+	"net/http"
+	"strconv"
+)
+
+// bloomPositions returns the bit positions of body: SM3(i || body) mod bits for each of the
+// hashCount hash functions, with i encoded as 4 bytes big-endian.
+func bloomPositions(body []byte, bits int64, hashCount int) []int64 {
+	positions := make([]int64, hashCount)
+	index := make([]byte, 4)
+	for i := range positions {
+		binary.BigEndian.PutUint32(index, uint32(i))
+		sum := sm3Sum(append(index, body...))
+		positions[i] = int64(binary.BigEndian.Uint64(sum[:8]) % uint64(bits))
+	}
+	return positions
+}
+
+// checkBloomFilter marks the body in the bloom filter and sets X-Bloom-Seen on the response.
+// SETBIT returns the previous bit, so the membership check and the insert share one round trip
+// per hash function: the body was possibly seen only if every bit was already set.
+func (p *MyPlugin) checkBloomFilter(conn redisConn, rw http.ResponseWriter, body []byte) {
+	seen := true
+	for _, position := range bloomPositions(body, p.bloomFilterBits, p.bloomFilterHashCount) {
+		previous, err := conn.SetBitWithBool(p.bloomFilterKey, position, true)
+		if err != nil {
+			p.logger.Error("写入布隆过滤器失败", logFields{"error": err})
+			return
+		}
+		seen = seen && previous
+	}
+	rw.Header().Set("X-Bloom-Seen", strconv.FormatBool(seen))
+}
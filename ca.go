@@ -0,0 +1,106 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/tjfoc/gmsm/x509"
+)
+
+const (
+	caIssuePath = "/ca/issue"
+	caCRLPath   = "/ca/crl"
+
+	// caIssuedKey is a sorted set of issued serial numbers scored by issue time.
+	caIssuedKey = "gmsm:ca:issued"
+	// caRevokedKey is a sorted set of revoked serial numbers scored by revocation time.
+	// Operators revoke a certificate by adding its serial (decimal) to this set.
+	caRevokedKey = "gmsm:ca:revoked"
+
+	caCRLValidity = 24 * time.Hour
+)
+
+// serveCAIssue signs a PEM encoded PKCS#10 CSR for an SM2 key with the CA key.
+func (p *MyPlugin) serveCAIssue(conn redisConn, rw http.ResponseWriter, body []byte) {
+	csr, err := x509.ReadCertificateRequestFromPem(body)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, "invalid csr")
+		return
+	}
+	if err := csr.CheckSignature(); err != nil {
+		p.writeError(rw, http.StatusBadRequest, "invalid csr signature")
+		return
+	}
+	publicKey, err := toSM2PublicKey(csr.PublicKey)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:       serial,
+		Subject:            csr.Subject,
+		DNSNames:           csr.DNSNames,
+		EmailAddresses:     csr.EmailAddresses,
+		IPAddresses:        csr.IPAddresses,
+		NotBefore:          now,
+		NotAfter:           now.AddDate(0, 0, p.caValidityDays),
+		SignatureAlgorithm: x509.SM2WithSM3,
+		KeyUsage:           x509.KeyUsageDigitalSignature,
+	}
+	certPEM, err := x509.CreateCertificateToPem(template, p.caCert, publicKey, p.caKey)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := conn.ZAdd(caIssuedKey, float64(now.Unix()), serial.String()); err != nil {
+		p.logger.Error("记录证书序列号失败", logFields{"error": err})
+	}
+
+	rw.Header().Set("Content-Type", "application/x-pem-file")
+	rw.Write(certPEM)
+}
+
+// serveCACRL returns a PEM CRL of the revoked serial numbers signed by the CA.
+func (p *MyPlugin) serveCACRL(conn redisConn, rw http.ResponseWriter) {
+	revoked, err := zrangeWithScores(conn, "ZRANGE", caRevokedKey, 0, -1)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var revokedCerts []pkix.RevokedCertificate
+	for _, entry := range revoked {
+		serial, ok := new(big.Int).SetString(entry.Member, 10)
+		if !ok {
+			p.logger.Warn("忽略无效的吊销序列号", logFields{"serial": entry.Member})
+			continue
+		}
+		revokedCerts = append(revokedCerts, pkix.RevokedCertificate{
+			SerialNumber:   serial,
+			RevocationTime: time.Unix(int64(entry.Score), 0),
+		})
+	}
+
+	now := time.Now()
+	der, err := p.caCert.CreateCRL(rand.Reader, p.caKey, revokedCerts, now, now.Add(caCRLValidity))
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/x-pem-file")
+	rw.Write(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}))
+}
@@ -0,0 +1,88 @@
+package gmsmPlugin
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// cacheHitHeader tells the client whether the response came from the response cache.
+const cacheHitHeader = "X-SM3-Cache"
+
+// cacheKey is <prefix>:cache:<hex SM3 of body>. With CacheVaryHeaders the digest is
+// SM3(SM3(body) || "name:value\n" for each vary header), so different header values get
+// different entries.
+func (p *MyPlugin) cacheKey(req *http.Request, body []byte) string {
+	digest := sm3Sum(body)
+	if len(p.cacheVaryHeaders) > 0 {
+		hasher := sm3.New()
+		hasher.Write(digest)
+		for _, name := range p.cacheVaryHeaders {
+			hasher.Write([]byte(http.CanonicalHeaderKey(name) + ":" + req.Header.Get(name) + "\n"))
+		}
+		digest = hasher.Sum(nil)
+	}
+	return p.keyPrefix(req) + ":cache:" + hex.EncodeToString(digest)
+}
+
+// serveCachedResponse writes the response cached under key and reports whether there was one.
+// Headers already set for this request (e.g. X-Request-ID) are kept.
+func (p *MyPlugin) serveCachedResponse(conn redisConn, rw http.ResponseWriter, key string) bool {
+	entry, err := conn.HGetAll(key)
+	if err != nil {
+		p.logger.Error("读取响应缓存失败", logFields{"error": err})
+		return false
+	}
+	if len(entry) == 0 {
+		return false
+	}
+
+	status, err := strconv.Atoi(entry["status"])
+	if err != nil {
+		return false
+	}
+	var headers http.Header
+	if err := json.Unmarshal([]byte(entry["headers"]), &headers); err != nil {
+		return false
+	}
+	body, err := base64.StdEncoding.DecodeString(entry["body"])
+	if err != nil {
+		return false
+	}
+
+	for name, values := range headers {
+		if _, ok := rw.Header()[name]; !ok {
+			rw.Header()[name] = values
+		}
+	}
+	rw.Header().Set(cacheHitHeader, "hit")
+	rw.WriteHeader(status)
+	rw.Write(body)
+	return true
+}
+
+// storeCachedResponse sends the captured response and, when it is a 2xx, caches its status,
+// headers and body under key for CacheTTLSeconds.
+func (p *MyPlugin) storeCachedResponse(conn redisConn, key string, capture *responseCapture) {
+	status := capture.statusCode()
+	if status >= 200 && status < 300 {
+		headers, _ := json.Marshal(capture.Header())
+		_, err := conn.HMSet(key, map[string]string{
+			"status":  strconv.Itoa(status),
+			"headers": string(headers),
+			"body":    base64.StdEncoding.EncodeToString(capture.body.Bytes()),
+		})
+		if err == nil && p.cacheTTL > 0 {
+			_, err = conn.Expire(key, p.cacheTTL)
+		}
+		if err != nil {
+			p.logger.Error("写入响应缓存失败", logFields{"error": err})
+		}
+	}
+	capture.Header().Set(cacheHitHeader, "miss")
+	capture.flush()
+}
@@ -0,0 +1,59 @@
+package gmsmPlugin
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// canaryWindow is the size of the response windows compared against the canary set. Windows are
+// aligned to multiples of canaryWindow: sliding over every offset would cost one SM3 and one
+// SIsMember per byte, and the set holds only SM3 hashes, so there is nothing cheaper to pre-filter
+// with. Canary content that is not aligned in the response is not detected.
+const canaryWindow = 1024
+
+// loadCanaries adds the configured canary hashes to the canary set.
+func (p *MyPlugin) loadCanaries(conn redisConn, hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	_, err := conn.SAdd(p.canarySetKey, hashes...)
+	return err
+}
+
+// inspectCanary checks every aligned 1 KiB window of the captured response, and the shorter tail,
+// against the canary set, then either sends the response on or blocks it.
+func (p *MyPlugin) inspectCanary(conn redisConn, capture *responseCapture, req *http.Request) {
+	body := capture.body.Bytes()
+	for start := 0; start < len(body); start += canaryWindow {
+		end := start + canaryWindow
+		if end > len(body) {
+			end = len(body)
+		}
+
+		hashHex := fmt.Sprintf("%x", sm3Sum(body[start:end]))
+		found, err := conn.SIsMember(p.canarySetKey, hashHex)
+		if err != nil {
+			p.logger.Error("查询 canary 集合失败", logFields{"error": err})
+			break
+		}
+		if !found {
+			continue
+		}
+
+		p.logger.Error("[CRITICAL] 响应中检测到 canary 数据", logFields{
+			"ip": clientIP(req), "path": req.URL.Path, "offset": start, "hash": hashHex,
+		})
+		if _, err := conn.Incr(p.canarySetKey + ":canarytriggers"); err != nil {
+			p.logger.Error("canary 计数失败", logFields{"error": err})
+		}
+
+		if p.canaryBlockOnMatch {
+			capture.rw.Header().Del("Content-Length")
+			p.writeError(capture.rw, http.StatusForbidden, "forbidden")
+			return
+		}
+		break
+	}
+
+	capture.flush()
+}
@@ -0,0 +1,62 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInspectCanary(t *testing.T) {
+	canary := bytes.Repeat([]byte("4111111111111111;"), 61)[:canaryWindow]
+	filler := bytes.Repeat([]byte("x"), canaryWindow)
+	tail := []byte("ssn=078-05-1120")
+
+	tests := []struct {
+		name        string
+		body        []byte
+		block       bool
+		wantStatus  int
+		wantTrigger bool
+	}{
+		{"no canary", append(append([]byte{}, filler...), filler...), true, http.StatusOK, false},
+		{"aligned first window", append(append([]byte{}, canary...), filler...), true, http.StatusForbidden, true},
+		{"aligned second window", append(append([]byte{}, filler...), canary...), true, http.StatusForbidden, true},
+		{"tail window", append(append([]byte{}, filler...), tail...), true, http.StatusForbidden, true},
+		{"logged only", append(append([]byte{}, canary...), filler...), false, http.StatusOK, true},
+		// 对齐要求: 偏移一个字节的敏感文档不会被发现
+		{"unaligned", append(append([]byte("!"), canary...), filler...), true, http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRedis(t)
+			conn := f.conn(t, 0)
+			p := &MyPlugin{
+				canarySetKey:       "gmsm:canary",
+				canaryBlockOnMatch: tt.block,
+				logger:             newLogger(io.Discard, "error"),
+			}
+			hashes := []string{fmt.Sprintf("%x", sm3Sum(canary)), fmt.Sprintf("%x", sm3Sum(tail))}
+			if err := p.loadCanaries(conn, hashes); err != nil {
+				t.Fatal(err)
+			}
+
+			rw := httptest.NewRecorder()
+			capture := newResponseCapture(rw)
+			capture.Write(tt.body)
+			p.inspectCanary(conn, capture, httptest.NewRequest(http.MethodGet, "/export", nil))
+
+			if rw.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rw.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && !bytes.Equal(rw.Body.Bytes(), tt.body) {
+				t.Error("response body was not passed on unchanged")
+			}
+			if _, triggered := f.get(0, "gmsm:canary:canarytriggers"); triggered != tt.wantTrigger {
+				t.Errorf("canarytriggers counted = %v, want %v", triggered, tt.wantTrigger)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// sm3HashHeader carries the SM3 hex of the request body: the client's claim for canonical
+// verification, or the plugin's result in the "header" and "both" output modes.
+const sm3HashHeader = "X-SM3-Hash"
+
+// canonicalizers maps each CanonicalHashFormats value to its body transformation.
+var canonicalizers = map[string]func([]byte) ([]byte, error){
+	"json-sorted":         canonicalJSON,
+	"whitespace-stripped": stripWhitespace,
+	"crlf-to-lf":          crlfToLF,
+}
+
+// canonicalJSON re-encodes a JSON body with sorted object keys and no insignificant whitespace.
+func canonicalJSON(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	// Encode 会追加换行
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// stripWhitespace removes every whitespace character from the body.
+func stripWhitespace(body []byte) ([]byte, error) {
+	return bytes.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, body), nil
+}
+
+// crlfToLF converts CRLF line endings to LF.
+func crlfToLF(body []byte) ([]byte, error) {
+	return bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n")), nil
+}
+
+// verifyBodyHash checks the X-SM3-Hash header against the SM3 of the raw body and, failing that,
+// of each configured canonical form. It writes a 400 and returns false when nothing matches.
+func (p *MyPlugin) verifyBodyHash(rw http.ResponseWriter, req *http.Request, body []byte) bool {
+	expected, err := hex.DecodeString(strings.TrimSpace(req.Header.Get(sm3HashHeader)))
+	if err != nil || len(expected) == 0 {
+		p.writeError(rw, http.StatusBadRequest, "invalid "+sm3HashHeader+" header")
+		return false
+	}
+
+	if subtle.ConstantTimeCompare(sm3Sum(body), expected) == 1 {
+		return true
+	}
+	for _, format := range p.canonicalHashFormats {
+		canonical, err := canonicalizers[format](body)
+		if err != nil {
+			continue
+		}
+		if subtle.ConstantTimeCompare(sm3Sum(canonical), expected) == 1 {
+			p.logger.Info("SM3 hash 规范化后校验通过", logFields{"format": format})
+			return true
+		}
+	}
+
+	p.writeError(rw, http.StatusBadRequest, "SM3 hash mismatch")
+	return false
+}
@@ -0,0 +1,156 @@
+package gmsmPlugin
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+const (
+	ccmNonceSize = 12
+	ccmTagSize   = 16
+	// ccmAADHeader carries the additional authenticated data for SM4CCM.
+	ccmAADHeader = "X-CCM-AAD"
+)
+
+// ccmSeal implements CCM (NIST SP 800-38C) over a 128-bit block cipher:
+// CBC-MAC over B0 || aad || plaintext, then CTR encryption of the plaintext and the tag.
+func ccmSeal(block cipher.Block, nonce, aad, plaintext []byte, tagSize int) (ciphertext, tag []byte, err error) {
+	const blockSize = 16
+	q := 15 - len(nonce)
+	if len(nonce) < 7 || len(nonce) > 13 {
+		return nil, nil, errors.New("CCM: invalid nonce size")
+	}
+	if tagSize < 4 || tagSize > 16 || tagSize%2 != 0 {
+		return nil, nil, errors.New("CCM: invalid tag size")
+	}
+	if q < 8 && uint64(len(plaintext)) >= 1<<(8*uint(q)) {
+		return nil, nil, errors.New("CCM: plaintext too long")
+	}
+
+	// B0 = flags || N || Q
+	b0 := make([]byte, blockSize)
+	b0[0] = byte((tagSize-2)/2)<<3 | byte(q-1)
+	if len(aad) > 0 {
+		b0[0] |= 0x40
+	}
+	copy(b0[1:], nonce)
+	length := uint64(len(plaintext))
+	for i := blockSize - 1; i > len(nonce); i-- {
+		b0[i] = byte(length)
+		length >>= 8
+	}
+
+	mac := make([]byte, blockSize)
+	block.Encrypt(mac, b0)
+	// data 已经补零到分组长度的整数倍
+	cbcMAC := func(data []byte) {
+		for off := 0; off < len(data); off += blockSize {
+			for i := 0; i < blockSize; i++ {
+				mac[i] ^= data[off+i]
+			}
+			block.Encrypt(mac, mac)
+		}
+	}
+
+	if len(aad) > 0 {
+		var encoded []byte
+		if len(aad) < 0xff00 {
+			encoded = make([]byte, 2)
+			binary.BigEndian.PutUint16(encoded, uint16(len(aad)))
+		} else {
+			encoded = make([]byte, 6)
+			encoded[0], encoded[1] = 0xff, 0xfe
+			binary.BigEndian.PutUint32(encoded[2:], uint32(len(aad)))
+		}
+		cbcMAC(zeroPad(append(encoded, aad...), blockSize))
+	}
+	cbcMAC(zeroPad(plaintext, blockSize))
+
+	// Ctr_i = flags' || N || [i]q
+	ctr := make([]byte, blockSize)
+	ctr[0] = byte(q - 1)
+	copy(ctr[1:], nonce)
+
+	s0 := make([]byte, blockSize)
+	block.Encrypt(s0, ctr)
+	tag = make([]byte, tagSize)
+	for i := range tag {
+		tag[i] = mac[i] ^ s0[i]
+	}
+
+	ctr[blockSize-1] = 1
+	ciphertext = make([]byte, len(plaintext))
+	cipher.NewCTR(block, ctr).XORKeyStream(ciphertext, plaintext)
+	return ciphertext, tag, nil
+}
+
+// ccmOpen decrypts ciphertext sealed by ccmSeal and checks its tag in constant time.
+func ccmOpen(block cipher.Block, nonce, aad, ciphertext, tag []byte) ([]byte, error) {
+	const blockSize = 16
+	if len(nonce) < 7 || len(nonce) > 13 {
+		return nil, errors.New("CCM: invalid nonce size")
+	}
+	ctr := make([]byte, blockSize)
+	ctr[0] = byte(15 - len(nonce) - 1)
+	copy(ctr[1:], nonce)
+	ctr[blockSize-1] = 1
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCTR(block, ctr).XORKeyStream(plaintext, ciphertext)
+
+	_, expected, err := ccmSeal(block, nonce, aad, plaintext, len(tag))
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(expected, tag) != 1 {
+		return nil, errDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+// zeroPad pads data with zero bytes to a multiple of size.
+func zeroPad(data []byte, size int) []byte {
+	if len(data)%size == 0 {
+		return data
+	}
+	return append(append([]byte{}, data...), make([]byte, size-len(data)%size)...)
+}
+
+// serveSM4CCM encrypts and authenticates the body with SM4-CCM, using X-CCM-AAD as additional data.
+func (p *MyPlugin) serveSM4CCM(rw http.ResponseWriter, req *http.Request, body []byte) {
+	key, err := p.sm4KeyFor(req)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	nonce := make([]byte, ccmNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ciphertext, tag, err := ccmSeal(block, nonce, []byte(req.Header.Get(ccmAADHeader)), body, ccmTagSize)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{
+		"nonce":      hex.EncodeToString(nonce),
+		"tag":        hex.EncodeToString(tag),
+		"ciphertext": base64.StdEncoding.EncodeToString(ciphertext),
+	})
+}
@@ -0,0 +1,98 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+// The SM4-CCM example of RFC 8998 appendix A.2.
+func TestCCMSealRFC8998(t *testing.T) {
+	decode := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	key := decode("0123456789abcdeffedcba9876543210")
+	nonce := decode("00001234567800000000abcd")
+	aad := decode("feedfacedeadbeeffeedfacedeadbeefabaddad2")
+	plaintext := decode("aaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbccccccccccccccccdddddddddddddddd" +
+		"eeeeeeeeeeeeeeeeffffffffffffffffeeeeeeeeeeeeeeeeaaaaaaaaaaaaaaaa")
+	wantCiphertext := decode("48af93501fa62adbcd414cce6034d895dda1bf8f132f042098661572e7483094" +
+		"fd12e518ce062c98acee28d95df4416bed31a2f04476c18bb40c84a74b97dc5b")
+	wantTag := decode("16842d4fa186f56ab33256971fa110f4")
+
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, tag, err := ccmSeal(block, nonce, aad, plaintext, ccmTagSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ciphertext, wantCiphertext) {
+		t.Errorf("ciphertext = %x, want %x", ciphertext, wantCiphertext)
+	}
+	if !bytes.Equal(tag, wantTag) {
+		t.Errorf("tag = %x, want %x", tag, wantTag)
+	}
+	if got, err := ccmOpen(block, nonce, aad, wantCiphertext, wantTag); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("ccmOpen() = %x, %v, want the plaintext", got, err)
+	}
+}
+
+func TestCCMOpen(t *testing.T) {
+	block, err := sm4.NewCipher(bytes.Repeat([]byte{0x42}, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := bytes.Repeat([]byte{0x07}, ccmNonceSize)
+	aad := []byte("order-42")
+	plaintext := []byte(`{"amount":100}`)
+	ciphertext, tag, err := ccmSeal(block, nonce, aad, plaintext, ccmTagSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flip returns a copy of b with the low bit of byte i flipped
+	flip := func(b []byte, i int) []byte {
+		out := append([]byte{}, b...)
+		out[i] ^= 1
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		nonce      []byte
+		aad        []byte
+		ciphertext []byte
+		tag        []byte
+		wantErr    bool
+	}{
+		{"untouched", nonce, aad, ciphertext, tag, false},
+		{"tampered ciphertext", nonce, aad, flip(ciphertext, 3), tag, true},
+		{"tampered tag", nonce, aad, ciphertext, flip(tag, 15), true},
+		{"truncated tag", nonce, aad, ciphertext, tag[:8], true},
+		{"other aad", nonce, []byte("order-43"), ciphertext, tag, true},
+		{"missing aad", nonce, nil, ciphertext, tag, true},
+		{"other nonce", flip(nonce, 0), aad, ciphertext, tag, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ccmOpen(block, tt.nonce, tt.aad, tt.ciphertext, tt.tag)
+			if tt.wantErr {
+				if !errors.Is(err, errDecryptionFailed) {
+					t.Errorf("ccmOpen() error = %v, want errDecryptionFailed", err)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Errorf("ccmOpen() = %q, %v, want %q", got, err, plaintext)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultChainHistoryCount is the number of chain entries returned when the request does not ask.
+const defaultChainHistoryCount = 10
+
+// chainMaxAttempts bounds how often extendChain retries when another request moved the head.
+const chainMaxAttempts = 10
+
+// errChainContention is returned when the chain head kept changing under extendChain.
+var errChainContention = errors.New("hash chain head changed too often")
+
+// appendChainScript moves the chain head at KEYS[1] from ARGV[1] to ARGV[2] and appends the
+// entry ARGV[3] to the history at KEYS[2], but only while the head is still ARGV[1] (empty for
+// a chain that has not started). It returns 0 when another request got there first.
+const appendChainScript = `
+local head = redis.call('GET', KEYS[1]) or ''
+if head ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2])
+redis.call('RPUSH', KEYS[2], ARGV[3])
+return 1
+`
+
+// chainEntry is one link of the hash chain as stored in the history list.
+type chainEntry struct {
+	Hash         string `json:"hash"`
+	PreviousHash string `json:"previousHash"`
+	TS           int64  `json:"ts"`
+	RequestID    string `json:"requestId,omitempty"`
+}
+
+// chainHeadKey is ChainRedisKey, or <prefix>:{chain}:head when it is empty. The hash tag keeps
+// the head and the history in one cluster slot, as appendChainScript touches both.
+func (p *MyPlugin) chainHeadKey(req *http.Request) string {
+	if p.chainRedisKey != "" {
+		return p.chainRedisKey
+	}
+	return p.keyPrefix(req) + ":{chain}:head"
+}
+
+// chainHistoryKey is the list of chain entries, next to the head so that the head's hash tag
+// keeps both in one cluster slot.
+func (p *MyPlugin) chainHistoryKey(req *http.Request) string {
+	return p.chainHeadKey(req) + ":history"
+}
+
+// extendChain hashes SM3(previous head || input), 32 zero bytes standing in for the head of an
+// empty chain, and makes the result the new head. The head is read and swapped optimistically:
+// when another request moves it in between, the hash is computed again over the new head.
+func (p *MyPlugin) extendChain(conn redisConn, req *http.Request, requestID string, input []byte) ([]byte, []byte, error) {
+	headKey, historyKey := p.chainHeadKey(req), p.chainHistoryKey(req)
+	for attempt := 0; attempt < chainMaxAttempts; attempt++ {
+		// 原始命令读取, 与脚本写入的值一致, 不经过 redis 加密
+		reply, err := redisDo(conn, "GET", headKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		head, _ := reply.([]byte)
+
+		previous := make([]byte, 32)
+		if len(head) > 0 {
+			if previous, err = hex.DecodeString(string(head)); err != nil || len(previous) != 32 {
+				return nil, nil, errors.New("invalid hash chain head")
+			}
+		}
+		hash := sm3Sum(append(append([]byte(nil), previous...), input...))
+
+		entry, _ := json.Marshal(chainEntry{
+			Hash:         hex.EncodeToString(hash),
+			PreviousHash: hex.EncodeToString(previous),
+			TS:           time.Now().Unix(),
+			RequestID:    requestID,
+		})
+		swapped, err := conn.Eval(appendChainScript, 2, headKey, historyKey, string(head), hex.EncodeToString(hash), string(entry))
+		if err != nil {
+			return nil, nil, err
+		}
+		if n, _ := swapped.(int64); n == 1 {
+			return hash, previous, nil
+		}
+	}
+	return nil, nil, errChainContention
+}
+
+// serveChainHistory answers GET <ChainHistoryPath>?count=<n> with the last n chain entries,
+// oldest first.
+func (p *MyPlugin) serveChainHistory(conn redisConn, rw http.ResponseWriter, req *http.Request) {
+	count := defaultChainHistoryCount
+	if c := req.URL.Query().Get("count"); c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n <= 0 {
+			p.writeError(rw, http.StatusBadRequest, "invalid count")
+			return
+		}
+		count = n
+	}
+
+	members, err := conn.LRange(p.chainHistoryKey(req), int64(-count), -1)
+	if err != nil {
+		p.logger.Error("读取 hash 链失败", logFields{"error": err})
+		p.writeError(rw, http.StatusServiceUnavailable, "redis unavailable")
+		return
+	}
+	entries := make([]chainEntry, 0, len(members))
+	for _, member := range members {
+		var entry chainEntry
+		if err := json.Unmarshal([]byte(member), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"entries": entries, "code": 0})
+}
@@ -0,0 +1,112 @@
+package gmsmPlugin
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// latencyKey is a sorted set of "<unix nano>:<duration ms>" members scored by unix milliseconds.
+	latencyKey = "gmsm:circuitbreaker:latency"
+	// latencyWindow is how far back the P95 latency is computed over.
+	latencyWindow = time.Minute
+	// p95RefreshInterval limits how often the P95 is recomputed from redis.
+	p95RefreshInterval = time.Second
+)
+
+// circuitBreaker sheds load once the P95 latency exceeds the threshold.
+// The rejected fraction grows linearly from 0 at the threshold to 1 at twice the threshold and is
+// enforced with a token bucket: every request adds (1 - fraction) tokens, capped at one, and is
+// admitted only if a whole token is available.
+type circuitBreaker struct {
+	mu          sync.Mutex
+	thresholdMs float64
+	p95Ms       float64
+	refreshedAt time.Time
+	tokens      float64
+}
+
+// rejectionRate returns the fraction of requests to reject for the given P95.
+func (cb *circuitBreaker) rejectionRate(p95Ms float64) float64 {
+	rate := (p95Ms - cb.thresholdMs) / cb.thresholdMs
+	return math.Max(0, math.Min(1, rate))
+}
+
+// admitRequest reports whether the request may proceed under the current P95 latency.
+func (p *MyPlugin) admitRequest(conn redisConn) bool {
+	cb := p.circuitBreaker
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if time.Since(cb.refreshedAt) >= p95RefreshInterval {
+		p95, err := p.latencyP95(conn)
+		if err != nil {
+			p.logger.Error("计算 P95 延迟失败", logFields{"error": err})
+		} else {
+			cb.p95Ms = p95
+		}
+		cb.refreshedAt = time.Now()
+	}
+
+	rate := cb.rejectionRate(cb.p95Ms)
+	if rate == 0 {
+		cb.tokens = 1
+		return true
+	}
+
+	cb.tokens = math.Min(1, cb.tokens+1-rate)
+	if cb.tokens < 1 {
+		return false
+	}
+	cb.tokens--
+	return true
+}
+
+// recordLatency adds the duration of a request started at start to the latency window
+// and drops entries that fell out of it.
+func (p *MyPlugin) recordLatency(conn redisConn, start time.Time) {
+	now := time.Now()
+	durationMs := now.Sub(start).Milliseconds()
+	member := strconv.FormatInt(now.UnixNano(), 10) + ":" + strconv.FormatInt(durationMs, 10)
+
+	if _, err := conn.ZAdd(latencyKey, float64(now.UnixMilli()), member); err != nil {
+		p.logger.Error("记录请求延迟失败", logFields{"error": err})
+		return
+	}
+	conn.ZRemRangeByScore(latencyKey, 0, float64(now.Add(-latencyWindow).UnixMilli()))
+}
+
+// latencyP95 returns the 95th percentile of the request durations in the latency window.
+func (p *MyPlugin) latencyP95(conn redisConn) (float64, error) {
+	now := time.Now()
+	members, err := conn.ZRangeByScore(latencyKey, float64(now.Add(-latencyWindow).UnixMilli()), float64(now.UnixMilli()))
+	if err != nil {
+		return 0, err
+	}
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	durations := make([]float64, 0, len(members))
+	for _, member := range members {
+		i := strings.IndexByte(member, ':')
+		if i < 0 {
+			continue
+		}
+		duration, err := strconv.ParseFloat(member[i+1:], 64)
+		if err != nil {
+			continue
+		}
+		durations = append(durations, duration)
+	}
+	if len(durations) == 0 {
+		return 0, nil
+	}
+
+	sort.Float64s(durations)
+	return durations[int(math.Ceil(0.95*float64(len(durations))))-1], nil
+}
@@ -0,0 +1,81 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// clientCertHeader carries the client's SM2 certificate as base64 of its PEM (or DER) encoding.
+const clientCertHeader = "X-Client-Cert"
+
+// clientCNHeader passes the subject CN of a verified client certificate to the next handler.
+const clientCNHeader = "X-Client-CN"
+
+// newCertPool builds a pool from a bundle of concatenated PEM certificates.
+func newCertPool(bundle string) (*x509.CertPool, error) {
+	certs, err := parseCertificates([]byte(bundle))
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+// parseClientCert decodes the X-Client-Cert header value.
+func parseClientCert(value string) (*x509.Certificate, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(raw, []byte("-----BEGIN")) {
+		return x509.ParseCertificate(raw)
+	}
+	certs, err := parseCertificates(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) != 1 {
+		return nil, errors.New("expected exactly one certificate")
+	}
+	return certs[0], nil
+}
+
+// checkClientCert verifies the certificate in X-Client-Cert against the trusted CA bundle and
+// sets X-Client-CN on the request. It writes a 403 and returns false when verification fails.
+func (p *MyPlugin) checkClientCert(rw http.ResponseWriter, req *http.Request) bool {
+	// 不信任客户端自带的 X-Client-CN
+	req.Header.Del(clientCNHeader)
+
+	value := req.Header.Get(clientCertHeader)
+	if value == "" {
+		p.writeError(rw, http.StatusForbidden, "missing client certificate")
+		return false
+	}
+	cert, err := parseClientCert(value)
+	if err != nil {
+		p.writeError(rw, http.StatusForbidden, "malformed client certificate")
+		return false
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:     p.clientCARoots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		p.writeError(rw, http.StatusForbidden, "client certificate verification failed: "+err.Error())
+		return false
+	}
+
+	req.Header.Set(clientCNHeader, cert.Subject.CommonName)
+	return true
+}
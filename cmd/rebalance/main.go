@@ -0,0 +1,47 @@
+// Command rebalance reads keys from stdin, one per line, and prints the keys that would move to
+// another shard if a shard were added to a consistent hash ring.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	gmsmPlugin "github.com/jack/gmsmPlugin"
+)
+
+func main() {
+	shards := flag.Int("shards", 4, "current number of shards")
+	vnodes := flag.Int("vnodes", 160, "virtual nodes per shard")
+	flag.Parse()
+
+	if *shards < 1 || *vnodes < 1 {
+		fmt.Fprintln(os.Stderr, "shards and vnodes must be at least 1")
+		os.Exit(2)
+	}
+
+	current := gmsmPlugin.NewHashRing(*shards, *vnodes)
+	grown := gmsmPlugin.NewHashRing(*shards+1, *vnodes)
+
+	total, moved := 0, 0
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		key := scanner.Text()
+		if key == "" {
+			continue
+		}
+		total++
+		from, to := current.FindShard(key), grown.FindShard(key)
+		if from != to {
+			moved++
+			fmt.Printf("%s\t%d -> %d\n", key, from, to)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d of %d keys move when growing from %d to %d shards\n", moved, total, *shards, *shards+1)
+}
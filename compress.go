@@ -0,0 +1,62 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+)
+
+// Compression flags prepended to the SM4 ciphertext when CompressBeforeEncrypt is enabled.
+const (
+	compressionNone byte = 0
+	compressionGzip byte = 1
+	compressionZstd byte = 2
+)
+
+// compressionFlags maps CompressionAlgorithm values to their flag byte.
+// zstd keeps its flag reserved, but no zstd implementation is vendored, so New rejects it and
+// decompress reports ciphertexts carrying the flag as unsupported.
+var compressionFlags = map[string]byte{
+	"none": compressionNone,
+	"gzip": compressionGzip,
+}
+
+// compress compresses data with the algorithm identified by flag.
+func compress(flag byte, data []byte) ([]byte, error) {
+	switch flag {
+	case compressionNone:
+		return data, nil
+	case compressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, errors.New("unsupported compression")
+	}
+}
+
+// decompress reverses compress for the algorithm identified by flag.
+func decompress(flag byte, data []byte) ([]byte, error) {
+	switch flag {
+	case compressionNone:
+		return data, nil
+	case compressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	case compressionZstd:
+		return nil, errors.New("zstd compression is not supported")
+	default:
+		return nil, errors.New("unsupported compression")
+	}
+}
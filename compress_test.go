@@ -0,0 +1,147 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// testJSONBody returns a JSON array of n order records, the kind of body the gateway encrypts.
+func testJSONBody(n int) []byte {
+	type order struct {
+		ID       int     `json:"id"`
+		Customer string  `json:"customer"`
+		Status   string  `json:"status"`
+		Amount   float64 `json:"amount"`
+		Currency string  `json:"currency"`
+	}
+	orders := make([]order, n)
+	for i := range orders {
+		orders[i] = order{ID: 100000 + i, Customer: fmt.Sprintf("customer-%04d", i%500), Status: "PAID", Amount: float64(i%1000) + 0.99, Currency: "CNY"}
+	}
+	body, _ := json.Marshal(orders)
+	return body
+}
+
+func testBinaryBody(t testing.TB, n int) []byte {
+	body := make([]byte, n)
+	if _, err := rand.Read(body); err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// Compression sits in front of SM4-CBC: the flag byte is prepended to the ciphertext and picks
+// the decompressor on the way back.
+func TestCompressRoundTrip(t *testing.T) {
+	key, iv := make([]byte, 16), make([]byte, 16)
+	bodies := map[string][]byte{
+		"empty":  {},
+		"json":   testJSONBody(100),
+		"binary": testBinaryBody(t, 4096),
+	}
+	for algorithm, flag := range compressionFlags {
+		for name, body := range bodies {
+			t.Run(algorithm+"/"+name, func(t *testing.T) {
+				compressed, err := compress(flag, body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ciphertext, err := sm4CBCEncrypt(key, iv, compressed)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ciphertext = append([]byte{flag}, ciphertext...)
+
+				plaintext, err := sm4CBCDecrypt(key, iv, ciphertext[1:])
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := decompress(ciphertext[0], plaintext)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, body) {
+					t.Errorf("round trip returned %d bytes, want the original %d", len(got), len(body))
+				}
+			})
+		}
+	}
+}
+
+func TestDecompressUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		flag byte
+	}{
+		{"zstd", compressionZstd},
+		{"unknown", 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decompress(tt.flag, []byte("data")); err == nil {
+				t.Errorf("decompress(%d) succeeded, want an error", tt.flag)
+			}
+			if _, err := compress(tt.flag, []byte("data")); err == nil {
+				t.Errorf("compress(%d) succeeded, want an error", tt.flag)
+			}
+		})
+	}
+}
+
+func TestCompressionAlgorithmConfig(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		wantErr   string
+	}{
+		{"gzip", ""},
+		{"none", ""},
+		{"zstd", "not supported"},
+		{"brotli", "unsupported compressionAlgorithm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			config := CreateConfig()
+			config.CompressBeforeEncrypt = true
+			config.CompressionAlgorithm = tt.algorithm
+
+			_, err := New(context.Background(), http.NotFoundHandler(), config, "test")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("New() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("New() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// BenchmarkCompress reports the compressed size as a percentage of the input (ratio_pct) next to
+// the CPU time: repetitive JSON bodies shrink several-fold, random binary bodies not at all.
+func BenchmarkCompress(b *testing.B) {
+	bodies := []struct {
+		name string
+		body []byte
+	}{
+		{"json", testJSONBody(1000)},
+		{"binary", testBinaryBody(b, len(testJSONBody(1000)))},
+	}
+	for _, body := range bodies {
+		b.Run("gzip/"+body.name, func(b *testing.B) {
+			b.SetBytes(int64(len(body.body)))
+			var compressed []byte
+			for i := 0; i < b.N; i++ {
+				var err error
+				if compressed, err = compress(compressionGzip, body.body); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(100*float64(len(compressed))/float64(len(body.body)), "ratio_pct")
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/piaohao/godis"
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// consistencyKey returns the redis hash under prefix holding the last consistency digest and
+// when it was taken.
+func consistencyKey(prefix string) string {
+	return prefix + ":consistency"
+}
+
+// consistencyDigest returns the SM3 of the values in key order. Each value is prefixed with its
+// 4-byte big-endian length so that moving bytes between adjacent values changes the digest.
+func consistencyDigest(values []string) string {
+	hasher := sm3.New()
+	length := make([]byte, 4)
+	for _, value := range values {
+		binary.BigEndian.PutUint32(length, uint32(len(value)))
+		hasher.Write(length)
+		hasher.Write([]byte(value))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// checkConsistency hashes the watched keys, logs a CONSISTENCY_VIOLATION when the digest differs
+// from the one stored under prefix and stores the new digest.
+func checkConsistency(r *godis.Redis, prefix string, keys []string, logger *logger) {
+	values, err := r.MGet(keys...)
+	if err != nil {
+		logger.Error("一致性检查读取失败", logFields{"error": err})
+		return
+	}
+	digest := consistencyDigest(values)
+
+	previous, err := r.HGet(consistencyKey(prefix), "hash")
+	if err != nil {
+		logger.Error("一致性检查读取失败", logFields{"error": err})
+		return
+	}
+	if previous != "" && previous != digest {
+		logger.Error("[CONSISTENCY_VIOLATION] 数据被修改", logFields{"previous": previous, "current": digest})
+	}
+
+	if _, err := r.HMSet(consistencyKey(prefix), map[string]string{
+		"hash": digest,
+		"ts":   strconv.FormatInt(time.Now().Unix(), 10),
+	}); err != nil {
+		logger.Error("保存一致性 hash 失败", logFields{"error": err})
+	}
+}
+
+// watchConsistency runs checkConsistency every interval until ctx is done, on its own connection.
+func watchConsistency(ctx context.Context, option godis.Option, prefix string, keys []string, interval time.Duration, logger *logger) {
+	r := newRedis(option, logger)
+	go func() {
+		defer r.Close()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		checkConsistency(r, prefix, keys, logger)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				checkConsistency(r, prefix, keys, logger)
+			}
+		}
+	}()
+}
@@ -0,0 +1,42 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// The digest is stored under the configured prefix, and a watched key changed between two checks
+// is reported.
+func TestCheckConsistency(t *testing.T) {
+	f := newFakeRedis(t)
+	r := f.conn(t, 0)
+	f.set(0, "config:a", "1")
+	f.set(0, "config:b", "2")
+	keys := []string{"config:a", "config:b"}
+	var logs bytes.Buffer
+	logger := newLogger(&logs, "info")
+
+	checkConsistency(r, "tenant", keys, logger)
+	first, ok := f.hget(0, "tenant:consistency", "hash")
+	if !ok || first != consistencyDigest([]string{"1", "2"}) {
+		t.Fatalf("tenant:consistency hash = %q, want the digest of the watched keys", first)
+	}
+	if _, ok := f.hget(0, "gmsm:consistency", "hash"); ok {
+		t.Error("digest stored under the default prefix")
+	}
+
+	checkConsistency(r, "tenant", keys, logger)
+	if strings.Contains(logs.String(), "CONSISTENCY_VIOLATION") {
+		t.Fatalf("unchanged keys reported: %s", logs.String())
+	}
+
+	f.set(0, "config:b", "3")
+	checkConsistency(r, "tenant", keys, logger)
+	if !strings.Contains(logs.String(), "CONSISTENCY_VIOLATION") {
+		t.Error("changed key not reported")
+	}
+	if got, _ := f.hget(0, "tenant:consistency", "hash"); got != consistencyDigest([]string{"1", "3"}) {
+		t.Errorf("tenant:consistency hash = %q, want the new digest", got)
+	}
+}
@@ -0,0 +1,85 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// decodeContent decompresses a response body sent with Content-Encoding encoding.
+func decodeContent(encoding string, data []byte) ([]byte, error) {
+	var r io.ReadCloser
+	switch encoding {
+	case "gzip":
+		var err error
+		if r, err = gzip.NewReader(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	default:
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// encodeContent compresses data again for Content-Encoding encoding.
+func encodeContent(encoding string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	default:
+		var err error
+		if w, err = flate.NewWriter(&buf, flate.DefaultCompression); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// finishHashing completes the response hash. With DecompressBeforeHash, a buffered gzip or
+// deflate response is hashed on its decompressed content, so the hash does not depend on how
+// upstream compressed it, and is compressed again with the same algorithm before it is sent.
+// A body that does not decompress is answered with 502.
+func (p *MyPlugin) finishHashing(h *hashingWriter) {
+	encoding := strings.ToLower(strings.TrimSpace(h.rw.Header().Get("Content-Encoding")))
+	if !p.decompressBeforeHash || h.streaming || (encoding != "gzip" && encoding != "deflate") {
+		h.finish()
+		return
+	}
+
+	header := h.rw.Header()
+	content, err := decodeContent(encoding, h.body.Bytes())
+	if err == nil {
+		header.Set(sm3ResponseHashHeader, hex.EncodeToString(sm3Sum(content)))
+		var body []byte
+		if body, err = encodeContent(encoding, content); err == nil {
+			header.Set("Content-Encoding", encoding)
+			header.Set("Content-Length", strconv.Itoa(len(body)))
+			if h.status == 0 {
+				h.status = http.StatusOK
+			}
+			h.rw.WriteHeader(h.status)
+			h.rw.Write(body)
+			return
+		}
+	}
+
+	p.logger.Error("解压响应体失败", logFields{"error": err, "contentEncoding": encoding})
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	header.Del(sm3ResponseHashHeader)
+	p.writeError(h.rw, http.StatusBadGateway, "invalid compressed response")
+}
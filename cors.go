@@ -0,0 +1,70 @@
+package gmsmPlugin
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// corsOriginAllowed reports whether origin matches one of CORSAllowedOrigins: "*" matches any
+// origin, a pattern with "*" such as "https://*.example.com" is matched with path.Match, and
+// anything else must be equal.
+func (p *MyPlugin) corsOriginAllowed(origin string) bool {
+	for _, allowed := range p.corsAllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		if strings.Contains(allowed, "*") {
+			if ok, _ := path.Match(allowed, origin); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setCORSHeaders adds the headers of an allowed cross-origin response. The origin is echoed
+// rather than "*", which browsers refuse for credentialed requests.
+func (p *MyPlugin) setCORSHeaders(rw http.ResponseWriter, origin string) {
+	header := rw.Header()
+	header.Set("Access-Control-Allow-Origin", origin)
+	header.Set("Access-Control-Allow-Credentials", "true")
+	header.Add("Vary", "Origin")
+}
+
+// handleCORS adds CORS headers for an allowed Origin and answers preflight requests itself with
+// 204, before the body is read. Requests from other origins get no CORS headers, which the
+// browser treats as a refusal. It reports whether the request was answered.
+func (p *MyPlugin) handleCORS(rw http.ResponseWriter, req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+	if origin == "" {
+		return false
+	}
+
+	allowed := p.corsOriginAllowed(origin)
+	if allowed {
+		p.setCORSHeaders(rw, origin)
+	}
+	if !preflight {
+		return false
+	}
+
+	if allowed {
+		header := rw.Header()
+		methods := p.corsAllowedMethods
+		if methods == "" {
+			methods = req.Header.Get("Access-Control-Request-Method")
+		}
+		header.Set("Access-Control-Allow-Methods", methods)
+		if p.corsAllowedHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", p.corsAllowedHeaders)
+		}
+		if p.corsMaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(p.corsMaxAge))
+		}
+	}
+	rw.WriteHeader(http.StatusNoContent)
+	return true
+}
@@ -0,0 +1,118 @@
+package gmsmPlugin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCORSOriginAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{"any origin", []string{"*"}, "https://evil.example", true},
+		{"exact", []string{"https://app.example.com"}, "https://app.example.com", true},
+		{"exact with another port", []string{"https://app.example.com"}, "https://app.example.com:8443", false},
+		{"subdomain wildcard", []string{"https://*.example.com"}, "https://app.example.com", true},
+		{"wildcard needs a subdomain", []string{"https://*.example.com"}, "https://example.com", false},
+		{"wildcard other scheme", []string{"https://*.example.com"}, "http://app.example.com", false},
+		{"suffix attack", []string{"https://*.example.com"}, "https://app.example.com.evil.io", false},
+		{"second entry", []string{"https://a.example", "https://b.example"}, "https://b.example", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &MyPlugin{corsAllowedOrigins: tt.allowed}
+			if got := p.corsOriginAllowed(tt.origin); got != tt.want {
+				t.Errorf("corsOriginAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeHTTPCORS(t *testing.T) {
+	f := newFakeRedis(t)
+	p := newTestPlugin(t, f, func(c *Config) {
+		c.CORSEnabled = true
+		c.CORSAllowedOrigins = []string{"https://app.example.com", "https://*.partner.example"}
+		c.CORSAllowedHeaders = []string{"Content-Type", "X-Request-Nonce"}
+		c.CORSMaxAgeSeconds = 600
+		c.DuplicateAction = "passthrough"
+	})
+
+	preflight := func(origin string) *http.Request {
+		// 预检请求不应读取请求体, 读取就会失败
+		req := httptest.NewRequest(http.MethodOptions, "/", errReader{})
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		return req
+	}
+	actual := func(origin, cookie string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hash me"))
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		return req
+	}
+
+	tests := []struct {
+		name        string
+		req         *http.Request
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{"preflight, exact origin", preflight("https://app.example.com"), http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":      "https://app.example.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     "POST, PUT, PATCH",
+			"Access-Control-Allow-Headers":     "Content-Type, X-Request-Nonce",
+			"Access-Control-Max-Age":           "600",
+			"Vary":                             "Origin",
+		}},
+		{"preflight, wildcard origin", preflight("https://eu.partner.example"), http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin": "https://eu.partner.example",
+		}},
+		{"preflight, disallowed origin", preflight("https://evil.example"), http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":  "",
+			"Access-Control-Allow-Methods": "",
+		}},
+		{"credentialed cross-origin", actual("https://app.example.com", "session=1"), http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin":      "https://app.example.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     "",
+		}},
+		{"cross-origin, disallowed origin", actual("https://evil.example", "session=1"), http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin":      "",
+			"Access-Control-Allow-Credentials": "",
+		}},
+		{"not CORS", actual("", ""), http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin": "",
+			"Vary":                        "",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			p.ServeHTTP(rw, tt.req)
+
+			if rw.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rw.Code, tt.wantStatus, rw.Body)
+			}
+			for name, want := range tt.wantHeaders {
+				if got := rw.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			if body, _ := io.ReadAll(rw.Body); tt.wantStatus == http.StatusNoContent && len(body) != 0 {
+				t.Errorf("preflight answered with a body: %s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// counterKey is the redis key counting the successful requests for algorithm.
+func (p *MyPlugin) counterKey(req *http.Request, algorithm string) string {
+	return p.keyPrefix(req) + ":counter:" + algorithm
+}
+
+// runCounterWorker increments the counter keys it receives until ctx is done.
+func (p *MyPlugin) runCounterWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case key := <-p.counterJobs:
+			p.incrCounter(key)
+		}
+	}
+}
+
+// incrCounter runs INCR on key and, with CounterTTLDays, pushes its expiry back.
+func (p *MyPlugin) incrCounter(key string) {
+	conn, err := p.getConn()
+	if err != nil {
+		p.logger.Error("获取 redis 连接失败", logFields{"error": err})
+		return
+	}
+	defer conn.Close()
+
+	if _, err := conn.Incr(key); err != nil {
+		p.logger.Error("更新算法计数失败", logFields{"error": err, "key": key})
+		return
+	}
+	if p.counterTTL > 0 {
+		if _, err := conn.Expire(key, p.counterTTL); err != nil {
+			p.logger.Error("设置算法计数过期时间失败", logFields{"error": err, "key": key})
+		}
+	}
+}
+
+// countAlgorithm hands the increment for algorithm to an idle counter worker without blocking.
+// When every worker is busy the increment is dropped.
+func (p *MyPlugin) countAlgorithm(req *http.Request, algorithm string) {
+	if p.counterJobs == nil {
+		return
+	}
+	select {
+	case p.counterJobs <- p.counterKey(req, algorithm):
+	default:
+		p.logger.Warn("计数 worker 都在忙, 丢弃本次计数", logFields{"algorithm": algorithm})
+	}
+}
+
+// serveCounters answers GET <CountersPath> with the counter of every algorithm used so far,
+// read with a single MGET.
+func (p *MyPlugin) serveCounters(conn redisConn, rw http.ResponseWriter, req *http.Request) {
+	algorithms := make([]string, 0, len(knownAlgorithms))
+	for algorithm := range knownAlgorithms {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+	keys := make([]string, len(algorithms))
+	for i, algorithm := range algorithms {
+		keys[i] = p.counterKey(req, algorithm)
+	}
+
+	values, err := conn.MGet(keys...)
+	if err != nil {
+		p.logger.Error("读取算法计数失败", logFields{"error": err})
+		p.writeError(rw, http.StatusServiceUnavailable, "redis unavailable")
+		return
+	}
+	counters := make(map[string]int64)
+	for i, value := range values {
+		// 从未计数的算法没有 key, MGET 返回空值
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			counters[algorithms[i]] = n
+		}
+	}
+	writeJSON(rw, http.StatusOK, counters)
+}
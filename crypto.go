@@ -0,0 +1,64 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// ParseSM2PublicKeyFromCertPEM returns the SM2 public key in the first PEM block of data, which
+// may be a CERTIFICATE or a PKIX PUBLIC KEY. The certificate's validity period is not checked:
+// an expired certificate still yields its key, and callers that care check NotAfter themselves.
+func ParseSM2PublicKeyFromCertPEM(data []byte) (*sm2.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var pub interface{}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pub = cert.PublicKey
+	case "PUBLIC KEY":
+		var err error
+		if pub, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	return toSM2PublicKey(pub)
+}
+
+// parseSM2PublicKeyHex parses a hex SM2 public key point, uncompressed (04 || X || Y) or
+// SEC1 compressed.
+func parseSM2PublicKeyHex(s string) (*sm2.PublicKey, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("public key must be hex")
+	}
+	curve := sm2.P256Sm2()
+
+	var x, y *big.Int
+	if len(data) == 65 && data[0] == 0x04 {
+		x, y = new(big.Int).SetBytes(data[1:33]), new(big.Int).SetBytes(data[33:])
+		if !curve.IsOnCurve(x, y) {
+			x, y = nil, nil
+		}
+	} else {
+		x, y = unmarshalPoint(data)
+	}
+	if x == nil {
+		return nil, errors.New("not an SM2 public key")
+	}
+	return &sm2.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
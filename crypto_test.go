@@ -0,0 +1,111 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+func TestParseSM2PublicKeyFromCertPEM(t *testing.T) {
+	selfSigned, selfSignedKey := testSM2Cert(t, testLeaf("self-signed"), nil, nil)
+	expiredTemplate := testLeaf("expired")
+	expiredTemplate.NotBefore, expiredTemplate.NotAfter = time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour)
+	expired, expiredKey := testSM2Cert(t, expiredTemplate, nil, nil)
+
+	sm2PKIX, err := x509.MarshalSm2PublicKey(&selfSignedKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaCert := testClientCertificate(t, "p256")
+	ecdsaPKIX, err := x509.MarshalPKIXPublicKey(ecdsaCert.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(blockType string, der []byte) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+	}
+
+	tests := []struct {
+		name    string
+		pem     string
+		want    *sm2.PrivateKey
+		wantErr bool
+	}{
+		{"self-signed certificate", encode("CERTIFICATE", selfSigned.Raw), selfSignedKey, false},
+		{"expired certificate", encode("CERTIFICATE", expired.Raw), expiredKey, false},
+		{"PUBLIC KEY", encode("PUBLIC KEY", sm2PKIX), selfSignedKey, false},
+		{"P-256 certificate", encode("CERTIFICATE", ecdsaCert.Raw), nil, true},
+		{"P-256 PUBLIC KEY", encode("PUBLIC KEY", ecdsaPKIX), nil, true},
+		{"other block type", encode("EC PRIVATE KEY", sm2PKIX), nil, true},
+		{"corrupt certificate", encode("CERTIFICATE", selfSigned.Raw[:40]), nil, true},
+		{"not PEM", "-----BEGIN", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSM2PublicKeyFromCertPEM([]byte(tt.pem))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSM2PublicKeyFromCertPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && (got.X.Cmp(tt.want.X) != 0 || got.Y.Cmp(tt.want.Y) != 0) {
+				t.Error("ParseSM2PublicKeyFromCertPEM() returned another key")
+			}
+		})
+	}
+}
+
+// The verify endpoint takes the key from a PEM certificate only with AcceptCertAsPublicKey.
+func TestVerifySignatureCert(t *testing.T) {
+	cert, key := testSM2Cert(t, testLeaf("signer"), nil, nil)
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	data := []byte("transfer 100 CNY")
+	signature, err := key.Sign(rand.Reader, data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedData := base64.StdEncoding.EncodeToString(data)
+
+	tests := []struct {
+		name       string
+		accept     bool
+		cert       string
+		data       string
+		wantStatus int
+		wantMatch  bool
+	}{
+		{"certificate accepted", true, certPEM, encodedData, http.StatusOK, true},
+		{"other data", true, certPEM, base64.StdEncoding.EncodeToString([]byte("transfer 900 CNY")), http.StatusOK, false},
+		{"certificate not accepted", false, certPEM, encodedData, http.StatusBadRequest, false},
+		{"not a certificate", true, "garbage", encodedData, http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &MyPlugin{acceptCertAsPublicKey: tt.accept, logger: newLogger(io.Discard, "error")}
+			request := &verifyRequest{Data: &tt.data, Signature: hex.EncodeToString(signature), Cert: tt.cert}
+			rw := httptest.NewRecorder()
+			p.verifySignature(rw, request)
+
+			if rw.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rw.Code, tt.wantStatus, rw.Body)
+			}
+			var response struct {
+				Match bool `json:"match"`
+			}
+			if err := json.Unmarshal(rw.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Match != tt.wantMatch {
+				t.Errorf("match = %v, want %v", response.Match, tt.wantMatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// duplicateHeader marks responses to duplicate requests when DuplicateAction is "passthrough".
+const duplicateHeader = "X-Duplicate-Request"
+
+// defaultFingerprintCount is how many fingerprints the fingerprints endpoint returns by default.
+const defaultFingerprintCount = 100
+
+// isDuplicate records the SM3 hash of body and reports whether it had been seen before.
+// Redis errors are logged and the request is treated as new. With the pipeline enabled the
+// SET NX is batched with those of concurrent requests.
+func (p *MyPlugin) isDuplicate(conn redisConn, req *http.Request, body []byte) bool {
+	hash := hex.EncodeToString(sm3Sum(body))
+	if p.storageMode == "zset" {
+		return p.recordFingerprint(conn, req, hash)
+	}
+	key := p.hashKey(req, hash)
+
+	if p.pipeline != nil {
+		set, err := p.pipeline.setNX(key, p.hashTTL)
+		if err != nil {
+			p.logger.Error("记录请求 hash 失败", logFields{"error": err})
+			return false
+		}
+		return !set
+	}
+
+	// SET NX 一步完成检查和写入, 并发的相同请求只有一个能写入成功
+	var reply string
+	var err error
+	if p.hashTTL > 0 {
+		reply, err = conn.SetWithParamsAndTime(key, "1", "NX", "EX", int64(p.hashTTL))
+	} else {
+		reply, err = conn.SetWithParams(key, "1", "NX")
+	}
+	if err != nil {
+		p.logger.Error("记录请求 hash 失败", logFields{"error": err})
+		return false
+	}
+	return reply != "OK"
+}
+
+// fingerprintsKey is the sorted set holding the fingerprints in "zset" storage mode.
+func (p *MyPlugin) fingerprintsKey(req *http.Request) string {
+	return p.keyPrefix(req) + ":fingerprints"
+}
+
+// recordFingerprint adds hash to the fingerprint sorted set scored by the current Unix time
+// and reports whether it was already a member. Entries older than the retention are removed first.
+func (p *MyPlugin) recordFingerprint(conn redisConn, req *http.Request, hash string) bool {
+	key := p.fingerprintsKey(req)
+	now := time.Now().Unix()
+
+	// 先清理过期的指纹, 过期后再出现不算重复
+	if p.fingerprintRetention > 0 {
+		if _, err := conn.ZRemRangeByScore(key, math.Inf(-1), float64(now-p.fingerprintRetention)); err != nil {
+			p.logger.Error("清理过期指纹失败", logFields{"error": err})
+		}
+	}
+
+	// ZADD 返回新增的成员数, 已存在时只更新时间
+	added, err := conn.ZAdd(key, float64(now), hash)
+	if err != nil {
+		p.logger.Error("记录请求指纹失败", logFields{"error": err})
+		return false
+	}
+	return added == 0
+}
+
+// serveFingerprints returns the most recent fingerprints, newest first. The "count" query
+// parameter limits the result.
+func (p *MyPlugin) serveFingerprints(conn redisConn, rw http.ResponseWriter, req *http.Request) {
+	count := defaultFingerprintCount
+	if c := req.URL.Query().Get("count"); c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n <= 0 {
+			p.writeError(rw, http.StatusBadRequest, "invalid count")
+			return
+		}
+		count = n
+	}
+
+	// godis 的 ZRevRangeWithScores 返回的 Tuple 不导出字段, 用 zrangeWithScores 解析
+	members, err := zrangeWithScores(conn, "ZREVRANGE", p.fingerprintsKey(req), 0, int64(count-1))
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	fingerprints := make([]map[string]interface{}, 0, len(members))
+	for _, m := range members {
+		fingerprints = append(fingerprints, map[string]interface{}{"hash": m.Member, "timestamp": int64(m.Score)})
+	}
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"fingerprints": fingerprints, "code": 0})
+}
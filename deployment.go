@@ -0,0 +1,47 @@
+package gmsmPlugin
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// checkDeployment looks up the SM3 hash of the body in the blue and green hash sets
+// and records unseen hashes in the set of the currently active deployment.
+func (p *MyPlugin) checkDeployment(conn redisConn, rw http.ResponseWriter, body []byte) {
+	hashHex := fmt.Sprintf("%x", sm3Sum(body))
+
+	blueKnown, err := conn.SIsMember(p.blueHashSetKey, hashHex)
+	if err != nil {
+		p.logger.Error("查询 blue 集合失败", logFields{"error": err})
+		return
+	}
+	greenKnown, err := conn.SIsMember(p.greenHashSetKey, hashHex)
+	if err != nil {
+		p.logger.Error("查询 green 集合失败", logFields{"error": err})
+		return
+	}
+
+	if blueKnown {
+		rw.Header().Set("X-Blue-Known", "true")
+	}
+	if greenKnown {
+		rw.Header().Set("X-Green-Known", "true")
+	}
+	if blueKnown || greenKnown {
+		return
+	}
+
+	// 新的请求签名, 记录到当前活跃的部署集合中
+	active, err := conn.Get(p.activeDeploymentKey)
+	if err != nil {
+		p.logger.Error("获取当前部署失败", logFields{"error": err})
+		return
+	}
+	setKey := p.blueHashSetKey
+	if active == "green" {
+		setKey = p.greenHashSetKey
+	}
+	if _, err := conn.SAdd(setKey, hashHex); err != nil {
+		p.logger.Error("写入部署集合失败", logFields{"error": err})
+	}
+}
@@ -0,0 +1,25 @@
+package gmsmPlugin
+
+import (
+	"fmt"
+	"os"
+)
+
+// secretFromEnv returns the value of the environment variable envVar for the config field
+// field, or configValue when envVar is empty. An unset or empty variable is an error so that a
+// typo never leaves the plugin running with no key. Only the variable name is logged.
+func secretFromEnv(field, configValue, envVar string, logger *logger) (string, error) {
+	if envVar == "" {
+		return configValue, nil
+	}
+	value := os.Getenv(envVar)
+	if value == "" {
+		return "", fmt.Errorf("environment variable %s for %s is not set or empty", envVar, field)
+	}
+	if configValue != "" {
+		logger.Warn("同时在配置和环境变量中设置, 使用环境变量", logFields{"field": field, "envVar": envVar})
+	} else {
+		logger.Warn("从环境变量读取", logFields{"field": field, "envVar": envVar})
+	}
+	return value, nil
+}
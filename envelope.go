@@ -0,0 +1,112 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+// envelopeKeySize is the key material wrapped with SM2: the SM4 session key followed by the
+// HMAC-SM3 key that authenticates the body ciphertext.
+const envelopeKeySize = 2 * sm4.BlockSize
+
+// Envelope is an SM4 encrypted body with its session key wrapped for an SM2 public key. All
+// fields are base64: KeyEnc is the SM2 (C1C3C2) ciphertext of the key material, BodyEnc the
+// SM4-CBC ciphertext followed by its HMAC-SM3 tag over IV || ciphertext.
+type Envelope struct {
+	KeyEnc  string `json:"keyEnc"`
+	BodyEnc string `json:"bodyEnc"`
+	IV      string `json:"iv"`
+}
+
+// SealEnvelope encrypts body under a fresh SM4 session key and wraps the key for pub.
+func SealEnvelope(pub *sm2.PublicKey, body []byte) (*Envelope, error) {
+	material := make([]byte, envelopeKeySize)
+	if _, err := rand.Read(material); err != nil {
+		return nil, err
+	}
+	keyEnc, err := sm2.Encrypt(pub, material, rand.Reader, sm2.C1C3C2)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, err := randomIV()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := sm4CBCEncrypt(material[:sm4.BlockSize], iv, body)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext = appendCBCTag(material[sm4.BlockSize:], iv, ciphertext)
+
+	return &Envelope{
+		KeyEnc:  base64.StdEncoding.EncodeToString(keyEnc),
+		BodyEnc: base64.StdEncoding.EncodeToString(ciphertext),
+		IV:      base64.StdEncoding.EncodeToString(iv),
+	}, nil
+}
+
+// OpenEnvelope unwraps the session key with priv and decrypts the body. Every failure, including
+// an envelope sealed for another key, is reported as errDecryptionFailed.
+func OpenEnvelope(priv *sm2.PrivateKey, envelope *Envelope) ([]byte, error) {
+	keyEnc, err := base64.StdEncoding.DecodeString(envelope.KeyEnc)
+	if err != nil {
+		return nil, errDecryptionFailed
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.BodyEnc)
+	if err != nil {
+		return nil, errDecryptionFailed
+	}
+	iv, err := base64.StdEncoding.DecodeString(envelope.IV)
+	if err != nil {
+		return nil, errDecryptionFailed
+	}
+
+	// 0x04 || C1(64) || C3(32) || C2
+	if len(keyEnc) < 97 {
+		return nil, errDecryptionFailed
+	}
+	material, err := sm2.Decrypt(priv, keyEnc, sm2.C1C3C2)
+	if err != nil || len(material) != envelopeKeySize {
+		return nil, errDecryptionFailed
+	}
+	if ciphertext, err = checkCBCTag(material[sm4.BlockSize:], iv, ciphertext); err != nil {
+		return nil, err
+	}
+	return sm4CBCDecrypt(material[:sm4.BlockSize], iv, ciphertext)
+}
+
+// serveEnvelopeEncrypt seals the body for the SM2 public key and answers with the envelope.
+func (p *MyPlugin) serveEnvelopeEncrypt(rw http.ResponseWriter, body []byte) {
+	envelope, err := SealEnvelope(p.sm2PublicKey, body)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(rw, http.StatusOK, envelope)
+}
+
+// forwardEnvelopeDecrypt opens a JSON envelope body with the SM2 private key and passes the
+// plaintext to the next handler.
+func (p *MyPlugin) forwardEnvelopeDecrypt(rw http.ResponseWriter, req *http.Request, body []byte) {
+	var envelope Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		p.writeError(rw, http.StatusBadRequest, "body must be a JSON envelope")
+		return
+	}
+	plaintext, err := OpenEnvelope(p.sm2PrivateKey, &envelope)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	restoreBody(req, plaintext)
+	req.Header.Set("Content-Length", strconv.Itoa(len(plaintext)))
+	p.next.ServeHTTP(rw, req)
+}
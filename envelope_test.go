@@ -0,0 +1,126 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	key := testSM2Key(t)
+
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"empty", nil},
+		{"short", []byte(`{"amount":100}`)},
+		{"one block", bytes.Repeat([]byte("x"), 16)},
+		{"large", testBinaryBody(t, 1<<20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := SealEnvelope(&key.PublicKey, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := OpenEnvelope(key, envelope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.body) {
+				t.Errorf("OpenEnvelope() returned %d bytes, want the %d sealed", len(got), len(tt.body))
+			}
+		})
+	}
+}
+
+func TestOpenEnvelopeFailures(t *testing.T) {
+	key, other := testSM2Key(t), testSM2Key(t)
+	sealed, err := SealEnvelope(&key.PublicKey, []byte(`{"amount":100}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flip flips a bit of byte i of the base64 field s, counting from the end when i < 0
+	flip := func(s string, i int) string {
+		raw, _ := base64.StdEncoding.DecodeString(s)
+		if i < 0 {
+			i += len(raw)
+		}
+		raw[i] ^= 1
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+
+	tests := []struct {
+		name     string
+		envelope Envelope
+		useOther bool
+	}{
+		{"different key pair", *sealed, true},
+		{"tampered body", Envelope{sealed.KeyEnc, flip(sealed.BodyEnc, 0), sealed.IV}, false},
+		{"tampered tag", Envelope{sealed.KeyEnc, flip(sealed.BodyEnc, -1), sealed.IV}, false},
+		{"tampered key", Envelope{flip(sealed.KeyEnc, 100), sealed.BodyEnc, sealed.IV}, false},
+		{"tampered iv", Envelope{sealed.KeyEnc, sealed.BodyEnc, flip(sealed.IV, 0)}, false},
+		{"truncated key", Envelope{sealed.KeyEnc[:64], sealed.BodyEnc, sealed.IV}, false},
+		{"not base64", Envelope{"!", sealed.BodyEnc, sealed.IV}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priv := key
+			if tt.useOther {
+				priv = other
+			}
+			if _, err := OpenEnvelope(priv, &tt.envelope); !errors.Is(err, errDecryptionFailed) {
+				t.Errorf("OpenEnvelope() error = %v, want errDecryptionFailed", err)
+			}
+		})
+	}
+}
+
+// An envelope sealed by one gateway opens at a gateway with the matching private key only.
+func TestServeHTTPEnvelope(t *testing.T) {
+	key, other := testSM2Key(t), testSM2Key(t)
+	f := newFakeRedis(t)
+	gateway := func(algorithm string, priv string) *MyPlugin {
+		return newTestPlugin(t, f, func(c *Config) {
+			c.SMAlgorithm = algorithm
+			c.SM2PrivateKeyPEM = priv
+			c.EnvelopeEncryptionEnabled = true
+			c.DuplicateAction = "passthrough"
+		})
+	}
+	body := []byte(`{"card":"6222020200112233"}`)
+
+	rw := httptest.NewRecorder()
+	gateway("SM2-ENCRYPT", testSM2PrivateKeyPEM(t, key)).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+	sealed := rw.Body.Bytes()
+	var envelope Envelope
+	if err := json.Unmarshal(sealed, &envelope); err != nil || envelope.KeyEnc == "" || envelope.BodyEnc == "" || envelope.IV == "" {
+		t.Fatalf("encrypt answered %d %s, want a JSON envelope", rw.Code, rw.Body)
+	}
+
+	tests := []struct {
+		name       string
+		priv       string
+		wantStatus int
+	}{
+		{"matching key", testSM2PrivateKeyPEM(t, key), http.StatusOK},
+		{"different key pair", testSM2PrivateKeyPEM(t, other), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			gateway("SM2-DECRYPT", tt.priv).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(sealed)))
+			if rw.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rw.Code, tt.wantStatus, rw.Body)
+			}
+			if tt.wantStatus == http.StatusOK && !bytes.Equal(rw.Body.Bytes(), body) {
+				t.Errorf("next handler got %s, want %s", rw.Body, body)
+			}
+		})
+	}
+}
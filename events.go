@@ -0,0 +1,73 @@
+package gmsmPlugin
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// eventsPath replays the event stream.
+const eventsPath = "/events"
+
+// appendEvent appends an immutable record of the request to the event stream.
+// Events are never deleted individually, the stream is bounded with MAXLEN instead.
+func (p *MyPlugin) appendEvent(conn redisConn, req *http.Request, body []byte, status int) {
+	_, err := redisDo(conn, "XADD", p.eventStreamKey,
+		"MAXLEN", "~", strconv.Itoa(p.eventMaxAge), "*",
+		"body_hash", fmt.Sprintf("%x", sm3Sum(body)),
+		"method", req.Method,
+		"path", req.URL.Path,
+		"ip", clientIP(req),
+		"ts", strconv.FormatInt(time.Now().UnixNano(), 10),
+		"status_code", strconv.Itoa(status),
+	)
+	if err != nil {
+		p.logger.Error("写入事件流失败", logFields{"error": err})
+	}
+}
+
+// serveEvents replays events with XRANGE starting at the "from" id.
+func (p *MyPlugin) serveEvents(conn redisConn, rw http.ResponseWriter, req *http.Request) {
+	from := req.URL.Query().Get("from")
+	if from == "" {
+		from = "-"
+	}
+	count := 100
+	if c := req.URL.Query().Get("count"); c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n <= 0 {
+			p.writeError(rw, http.StatusBadRequest, "invalid count")
+			return
+		}
+		count = n
+	}
+
+	reply, err := redisDo(conn, "XRANGE", p.eventStreamKey, from, "+", "COUNT", strconv.Itoa(count))
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// XRANGE 返回 [[id, [field, value, ...]], ...]
+	events := make([]map[string]interface{}, 0)
+	entries, _ := reply.([]interface{})
+	for _, entry := range entries {
+		pair, ok := entry.([]interface{})
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		id, _ := pair[0].([]byte)
+		values, _ := pair[1].([]interface{})
+
+		fields := make(map[string]string)
+		for i := 0; i+1 < len(values); i += 2 {
+			k, _ := values[i].([]byte)
+			v, _ := values[i+1].([]byte)
+			fields[string(k)] = string(v)
+		}
+		events = append(events, map[string]interface{}{"id": string(id), "fields": fields})
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"events": events, "code": 0})
+}
@@ -0,0 +1,51 @@
+package gmsmPlugin
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+)
+
+// featureKeyPrefix prefixes the redis keys holding each client's feature assignments:
+// gmsm:features:<feature>:<SM3 hex of client ID>.
+const featureKeyPrefix = "gmsm:features:"
+
+// featureClientID returns the client ID used for feature bucketing: the X-Client-ID header,
+// then the feature cookie, then the client IP.
+func (p *MyPlugin) featureClientID(req *http.Request) string {
+	if id := req.Header.Get("X-Client-ID"); id != "" {
+		return id
+	}
+	if cookie, err := req.Cookie(p.featureCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+	return clientIP(req)
+}
+
+// applyFeatureFlags sets X-Feature-<name> on the upstream request for every configured feature.
+// A client is in a feature when the first 4 bytes of SM3(client ID) mod 100 are below its
+// percentage. The first assignment is kept in redis with SETNX, so a client keeps its flags
+// when percentages change later.
+func (p *MyPlugin) applyFeatureFlags(conn redisConn, req *http.Request) {
+	sum := sm3Sum([]byte(p.featureClientID(req)))
+	bucket := binary.BigEndian.Uint32(sum[:4]) % 100
+	clientHash := hex.EncodeToString(sum)
+
+	for feature, percentage := range p.featureSegments {
+		state := "disabled"
+		if bucket < uint32(percentage) {
+			state = "enabled"
+		}
+
+		key := featureKeyPrefix + feature + ":" + clientHash
+		created, err := conn.SetNx(key, state)
+		if err != nil {
+			p.logger.Error("保存特性开关失败", logFields{"error": err})
+		} else if created == 0 {
+			if stored, err := conn.Get(key); err == nil && stored != "" {
+				state = stored
+			}
+		}
+		req.Header.Set("X-Feature-"+feature, state)
+	}
+}
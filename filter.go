@@ -0,0 +1,47 @@
+package gmsmPlugin
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ownsEndpoint reports whether req is for one of the plugin's own endpoints that are not POST
+// requests, or is a presigned request, which must keep working whatever AllowedMethods says.
+func (p *MyPlugin) ownsEndpoint(req *http.Request) bool {
+	if req.Method == http.MethodGet {
+		switch {
+		case p.eventSourcing && req.URL.Path == eventsPath,
+			p.fingerprintsPath != "" && req.URL.Path == p.fingerprintsPath,
+			p.caCert != nil && req.URL.Path == caCRLPath,
+			p.merklePath != "" && req.URL.Path == p.merklePath+merkleProofSuffix,
+			p.countersPath != "" && req.URL.Path == p.countersPath,
+			p.chainHistoryPath != "" && req.URL.Path == p.chainHistoryPath:
+			return true
+		}
+	}
+	if p.hashURL && isPresigned(req) {
+		return true
+	}
+	return p.hkdMasterKey != nil && req.Header.Get(hkdPathHeader) != ""
+}
+
+// activeFor reports whether the plugin processes req: its method must be in AllowedMethods and,
+// when AllowedPathPrefixes is set, its path must start with one of them. The plugin's own
+// endpoints are always active.
+func (p *MyPlugin) activeFor(req *http.Request) bool {
+	if p.ownsEndpoint(req) {
+		return true
+	}
+	if p.allowedMethods != nil && !p.allowedMethods[req.Method] {
+		return false
+	}
+	if len(p.allowedPathPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range p.allowedPathPrefixes {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+const (
+	fingerprintWindow = 512
+	fingerprintStep   = 256
+)
+
+// fingerprintSearchPath searches the fingerprint database for near-duplicates of the body.
+const fingerprintSearchPath = "/fingerprint/search"
+
+// fingerprints computes the SM3 hash of every 512-byte sliding window (step 256) of data.
+// Bodies shorter than one window produce a single fingerprint of the whole body.
+// Duplicate windows are returned only once.
+func fingerprints(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	if len(data) < fingerprintWindow {
+		return []string{fmt.Sprintf("%x", sm3Sum(data))}
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	for start := 0; start+fingerprintWindow <= len(data); start += fingerprintStep {
+		fp := fmt.Sprintf("%x", sm3Sum(data[start:start+fingerprintWindow]))
+		if !seen[fp] {
+			seen[fp] = true
+			result = append(result, fp)
+		}
+	}
+	return result
+}
+
+// storeFingerprints adds the fingerprints of the body to the fingerprint database.
+func (p *MyPlugin) storeFingerprints(conn redisConn, rw http.ResponseWriter, body []byte) {
+	fps := fingerprints(body)
+	if len(fps) > 0 {
+		if _, err := conn.SAdd(p.fingerprintSetKey, fps...); err != nil {
+			p.writeError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"fingerprintCount": len(fps), "bodyLen": len(body)})
+}
+
+// searchFingerprints intersects the fingerprints of a candidate document with the database.
+func (p *MyPlugin) searchFingerprints(conn redisConn, rw http.ResponseWriter, body []byte) {
+	fps := fingerprints(body)
+	if len(fps) == 0 {
+		writeJSON(rw, http.StatusOK, map[string]interface{}{"matchCount": 0, "similarity": 0.0})
+		return
+	}
+
+	// 候选文档的指纹先写入临时集合, 再与数据库求交集; hash tag 让临时集合与数据库在 cluster 的同一个槽位
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	tmpKey := "{" + p.fingerprintSetKey + "}:search:" + hex.EncodeToString(suffix)
+	defer conn.Del(tmpKey)
+
+	if _, err := conn.SAdd(tmpKey, fps...); err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	conn.Expire(tmpKey, 60)
+
+	matches, err := conn.SInter(p.fingerprintSetKey, tmpKey)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{
+		"matchCount": len(matches),
+		"similarity": float64(len(matches)) / float64(len(fps)),
+	})
+}
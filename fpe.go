@@ -0,0 +1,133 @@
+package gmsmPlugin
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// ff1Rounds is the number of Feistel rounds of FF1.
+const ff1Rounds = 10
+
+// ff1Decimal is FF1 format-preserving encryption (NIST SP 800-38G) over decimal strings,
+// with block as the underlying 128-bit block cipher. It decrypts when decrypt is true.
+func ff1Decimal(block cipher.Block, tweak []byte, digits string, decrypt bool) (string, error) {
+	const radix = 10
+	n := len(digits)
+	if n < 2 || strings.Trim(digits, "0123456789") != "" {
+		return "", errors.New("FF1: input must be at least 2 decimal digits")
+	}
+	if block.BlockSize() != 16 {
+		return "", errors.New("FF1: block cipher must have a 128-bit block")
+	}
+
+	u, v := n/2, n-n/2
+	modU := new(big.Int).Exp(big.NewInt(radix), big.NewInt(int64(u)), nil)
+	modV := new(big.Int).Exp(big.NewInt(radix), big.NewInt(int64(v)), nil)
+
+	// b = ceil(ceil(v * log2(radix)) / 8), 10^v 不是 2 的幂, 其位数即 ceil(v * log2(10))
+	b := (modV.BitLen() + 7) / 8
+	d := 4*((b+3)/4) + 4
+	t := len(tweak)
+
+	p := make([]byte, 16)
+	p[0], p[1], p[2] = 1, 2, 1
+	p[3], p[4], p[5] = 0, 0, radix
+	p[6] = 10
+	p[7] = byte(u % 256)
+	binary.BigEndian.PutUint32(p[8:], uint32(n))
+	binary.BigEndian.PutUint32(p[12:], uint32(t))
+
+	// Q = T || 0^((-t-b-1) mod 16) || [i] || [NUM(B)]^b
+	qLen := t + ((-t-b-1)%16+16)%16 + 1 + b
+	prf := func(i int, x *big.Int) *big.Int {
+		q := make([]byte, qLen)
+		copy(q, tweak)
+		q[qLen-b-1] = byte(i)
+		x.FillBytes(q[qLen-b:])
+
+		// R = PRF(P || Q), CBC-MAC with a zero IV
+		r := make([]byte, 16)
+		block.Encrypt(r, p)
+		for j := 0; j < qLen; j += 16 {
+			for k := 0; k < 16; k++ {
+				r[k] ^= q[j+k]
+			}
+			block.Encrypt(r, r)
+		}
+
+		// S = R || CIPH(R xor [1]) || CIPH(R xor [2]) ...
+		s := append([]byte{}, r...)
+		for j := 1; len(s) < d; j++ {
+			in := append([]byte{}, r...)
+			ctr := make([]byte, 16)
+			binary.BigEndian.PutUint64(ctr[8:], uint64(j))
+			for k := range in {
+				in[k] ^= ctr[k]
+			}
+			block.Encrypt(in, in)
+			s = append(s, in...)
+		}
+		return new(big.Int).SetBytes(s[:d])
+	}
+
+	a, _ := new(big.Int).SetString(digits[:u], radix)
+	bb, _ := new(big.Int).SetString(digits[u:], radix)
+
+	for round := 0; round < ff1Rounds; round++ {
+		i := round
+		if decrypt {
+			i = ff1Rounds - 1 - round
+		}
+		// 偶数轮 m = u, 奇数轮 m = v
+		mod := modU
+		if i%2 == 1 {
+			mod = modV
+		}
+
+		if !decrypt {
+			c := new(big.Int).Add(a, prf(i, bb))
+			c.Mod(c, mod)
+			a, bb = bb, c
+		} else {
+			c := new(big.Int).Sub(bb, prf(i, a))
+			c.Mod(c, mod)
+			a, bb = c, a
+		}
+	}
+
+	return padDigits(a, u) + padDigits(bb, v), nil
+}
+
+// padDigits formats x in decimal, left padded with zeros to width digits.
+func padDigits(x *big.Int, width int) string {
+	s := x.Text(10)
+	return strings.Repeat("0", width-len(s)) + s
+}
+
+// luhnCheckDigit returns the Luhn check digit for the decimal string payload.
+func luhnCheckDigit(payload string) byte {
+	sum := 0
+	for i := len(payload) - 1; i >= 0; i-- {
+		digit := int(payload[i] - '0')
+		// 从右往左数, 校验位左边第一位开始每隔一位乘 2
+		if (len(payload)-1-i)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// luhnValid reports whether the decimal string number passes the Luhn check.
+func luhnValid(number string) bool {
+	if len(number) < 2 || strings.Trim(number, "0123456789") != "" {
+		return false
+	}
+	return luhnCheckDigit(number[:len(number)-1]) == number[len(number)-1]
+}
@@ -0,0 +1,82 @@
+package gmsmPlugin
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+// The radix-10 FF1-AES samples of NIST SP 800-38G; samples 3, 6 and 9 are radix 36.
+func TestFF1DecimalNISTSamples(t *testing.T) {
+	const (
+		key128 = "2b7e151628aed2a6abf7158809cf4f3c"
+		key192 = key128 + "ef4359d8d580aa4f"
+		key256 = key192 + "7f036d6f04fc6a94"
+		tweak  = "39383736353433323130"
+	)
+
+	tests := []struct {
+		name   string
+		key    string
+		tweak  string
+		plain  string
+		cipher string
+	}{
+		{"sample 1", key128, "", "0123456789", "2433477484"},
+		{"sample 2", key128, tweak, "0123456789", "6124200773"},
+		{"sample 4", key192, "", "0123456789", "2830668132"},
+		{"sample 5", key192, tweak, "0123456789", "2496655549"},
+		{"sample 7", key256, "", "0123456789", "6657667009"},
+		{"sample 8", key256, tweak, "0123456789", "1001623463"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, _ := hex.DecodeString(tt.key)
+			tweak, _ := hex.DecodeString(tt.tweak)
+			block, err := aes.NewCipher(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ff1Decimal(block, tweak, tt.plain, false)
+			if err != nil || got != tt.cipher {
+				t.Errorf("encrypt %s = %s, %v, want %s", tt.plain, got, err, tt.cipher)
+			}
+			got, err = ff1Decimal(block, tweak, tt.cipher, true)
+			if err != nil || got != tt.plain {
+				t.Errorf("decrypt %s = %s, %v, want %s", tt.cipher, got, err, tt.plain)
+			}
+		})
+	}
+}
+
+func TestFF1DecimalInvalid(t *testing.T) {
+	block, _ := aes.NewCipher(make([]byte, 16))
+
+	for _, digits := range []string{"", "1", "12a4", "-123"} {
+		if _, err := ff1Decimal(block, nil, digits, false); err == nil {
+			t.Errorf("ff1Decimal(%q) succeeded", digits)
+		}
+	}
+}
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"4111111111111111", true},
+		{"79927398713", true},
+		{"4111111111111112", false},
+		{"79927398710", false},
+		{"0", false},
+		{"4111 1111 1111 1111", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if got := luhnValid(tt.number); got != tt.want {
+				t.Errorf("luhnValid(%q) = %v, want %v", tt.number, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package gmsmPlugin
+
+import (
+	"container/list"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// unknownCountry is the country code used when the client cannot be located.
+const unknownCountry = "XX"
+
+// geoIPStatsInterval is how often the lookup cache hit rate is logged and reset.
+const geoIPStatsInterval = time.Minute
+
+// geoIP resolves client IPs to ISO country codes through a MaxMind DB, caching recent results.
+type geoIP struct {
+	// db is nil when the database could not be opened; every client is then unknownCountry
+	db *mmdbReader
+
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+
+	hits   uint64
+	misses uint64
+	logger *logger
+}
+
+type geoIPEntry struct {
+	ip      string
+	country string
+}
+
+// newGeoIP opens the database at path and logs its hit rate every geoIPStatsInterval until ctx
+// is done. A database that cannot be opened is logged, not fatal.
+func newGeoIP(ctx context.Context, path string, cacheSize int, logger *logger) *geoIP {
+	g := &geoIP{size: cacheSize, order: list.New(), entries: map[string]*list.Element{}, logger: logger}
+	db, err := openMMDB(path)
+	if err != nil {
+		logger.Error("打开 GeoIP 数据库失败, 国家代码统一为 XX", logFields{"path": path, "error": err})
+	} else {
+		g.db = db
+	}
+
+	go func() {
+		ticker := time.NewTicker(geoIPStatsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				g.flushStats()
+			}
+		}
+	}()
+	return g
+}
+
+// flushStats logs the cache hits and misses since the last flush and resets them.
+func (g *geoIP) flushStats() {
+	hits, misses := atomic.SwapUint64(&g.hits, 0), atomic.SwapUint64(&g.misses, 0)
+	if hits+misses == 0 {
+		return
+	}
+	g.logger.Info("GeoIP 缓存命中率", logFields{
+		"hits": hits, "misses": misses, "hitRate": float64(hits) / float64(hits+misses),
+	})
+}
+
+// country returns the ISO country code of ip, or unknownCountry when it is not in the database.
+func (g *geoIP) country(ip string) string {
+	g.mu.Lock()
+	if elem, ok := g.entries[ip]; ok {
+		g.order.MoveToFront(elem)
+		g.mu.Unlock()
+		atomic.AddUint64(&g.hits, 1)
+		return elem.Value.(*geoIPEntry).country
+	}
+	g.mu.Unlock()
+	atomic.AddUint64(&g.misses, 1)
+
+	country := g.lookup(ip)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if elem, ok := g.entries[ip]; ok {
+		g.order.Remove(elem)
+	}
+	g.entries[ip] = g.order.PushFront(&geoIPEntry{ip: ip, country: country})
+	for g.order.Len() > g.size {
+		oldest := g.order.Back()
+		g.order.Remove(oldest)
+		delete(g.entries, oldest.Value.(*geoIPEntry).ip)
+	}
+	return country
+}
+
+// lookup reads the country of ip from the database, falling back to the registered country.
+func (g *geoIP) lookup(ip string) string {
+	parsed := net.ParseIP(ip)
+	if g.db == nil || parsed == nil {
+		return unknownCountry
+	}
+	record, err := g.db.lookup(parsed)
+	if err != nil {
+		g.logger.Error("查询 GeoIP 数据库失败", logFields{"ip": ip, "error": err})
+		return unknownCountry
+	}
+	fields, _ := record.(map[string]interface{})
+	for _, name := range []string{"country", "registered_country"} {
+		country, _ := fields[name].(map[string]interface{})
+		if code, ok := country["iso_code"].(string); ok && code != "" {
+			return code
+		}
+	}
+	return unknownCountry
+}
+
+// hashKey is the redis key of a body hash: <prefix>:<hash>, or <prefix>:<country>:<hash> with
+// GeoIP enabled.
+func (p *MyPlugin) hashKey(req *http.Request, hashHex string) string {
+	if p.geoIP != nil {
+		return p.keyPrefix(req) + ":" + p.geoIP.country(p.rateLimitClient(req)) + ":" + hashHex
+	}
+	return p.keyPrefix(req) + ":" + hashHex
+}
@@ -10,4 +10,9 @@ require (
 require (
 	github.com/jolestar/go-commons-pool v2.0.0+incompatible // indirect
 	github.com/stretchr/testify v1.8.4 // indirect
+	golang.org/x/crypto v0.0.0-20201012173705-84dcc777aaee // indirect
+	golang.org/x/sys v0.0.0-20200930185726-fdedc70b468f // indirect
 )
+
+// godis 增加了 Option.Dial, 用于 TLS 连接
+replace github.com/piaohao/godis => ./third_party/godis
@@ -0,0 +1,27 @@
+package gmsmPlugin
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// hashEncodings lists the HashEncoding values. "raw" has no text form: the digest is written
+// to the response as is.
+var hashEncodings = map[string]bool{"hex": true, "HEX": true, "base64": true, "base64url": true, "raw": true}
+
+// formatHash encodes an SM3 digest for output according to HashEncoding.
+func formatHash(hash []byte, encoding string) string {
+	switch encoding {
+	case "HEX":
+		return strings.ToUpper(hex.EncodeToString(hash))
+	case "base64":
+		return base64.StdEncoding.EncodeToString(hash)
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(hash)
+	case "raw":
+		return string(hash)
+	default:
+		return hex.EncodeToString(hash)
+	}
+}
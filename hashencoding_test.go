@@ -0,0 +1,84 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testSM3Input is the 64-byte example message of GB/T 32905-2016, chosen because the base64 of
+// its digest contains both '+' and '/'.
+var testSM3Input = strings.Repeat("abcd", 16)
+
+const testSM3Digest = "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"
+
+func TestServeHTTPHashEncoding(t *testing.T) {
+	digest, _ := hex.DecodeString(testSM3Digest)
+
+	tests := []struct {
+		encoding        string
+		wantContentType string
+		wantBody        []byte
+	}{
+		{"hex", "application/json", []byte(`{"code":0,"message":"ok","result":"debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"}`)},
+		{"HEX", "application/json", []byte(`{"code":0,"message":"ok","result":"DEBE9FF92275B8A138604889C18E5A4D6FDB70E5387E5765293DCBA39C0C5732"}`)},
+		{"base64", "application/json", []byte(`{"code":0,"message":"ok","result":"3r6f+SJ1uKE4YEiJwY5aTW/bcOU4fldlKT3Lo5wMVzI="}`)},
+		{"base64url", "application/json", []byte(`{"code":0,"message":"ok","result":"3r6f-SJ1uKE4YEiJwY5aTW_bcOU4fldlKT3Lo5wMVzI"}`)},
+		{"raw", "application/octet-stream", digest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			f := newFakeRedis(t)
+			p := newTestPlugin(t, f, func(c *Config) {
+				c.HashEncoding = tt.encoding
+				c.ForwardToNext = false
+			})
+
+			rw := httptest.NewRecorder()
+			p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testSM3Input)))
+
+			if got := rw.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %s, want %s", got, tt.wantContentType)
+			}
+			if !bytes.Equal(rw.Body.Bytes(), tt.wantBody) {
+				t.Errorf("body = %q, want %q", rw.Body.Bytes(), tt.wantBody)
+			}
+			// redis 里始终是小写 hex
+			key := p.hashKey(httptest.NewRequest(http.MethodPost, "/", nil), testSM3Digest)
+			if _, ok := f.get(0, key); !ok {
+				t.Errorf("%s was not stored under the hex digest", key)
+			}
+		})
+	}
+}
+
+func TestHashEncodingConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		encoding   string
+		outputMode string
+		wantErr    bool
+	}{
+		{"raw in the body", "raw", "body", false},
+		{"raw in a header", "raw", "header", true},
+		{"unknown", "base32", "body", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := CreateConfig()
+			config.HashEncoding = tt.encoding
+			config.HashOutputMode = tt.outputMode
+			handler, err := New(context.Background(), http.NotFoundHandler(), config, "test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				handler.(*MyPlugin).Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	// sm3HeaderHashHeader carries the SM3 hex of the listed response headers.
+	sm3HeaderHashHeader = "X-SM3-Header-Hash"
+	// sm3ReqHeaderHashHeader carries the SM3 hex of the listed request headers to the next handler.
+	sm3ReqHeaderHashHeader = "X-SM3-Req-Header-Hash"
+)
+
+// headerHashNames lowercases, sorts and deduplicates the configured header names.
+func headerHashNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// headerHash returns the SM3 hex of the canonical form of the named headers: one
+// "name: value\n" line per header present, names lowercased and sorted, multiple values joined
+// with commas. names must come from headerHashNames.
+func headerHash(header http.Header, names []string) string {
+	var b strings.Builder
+	for _, name := range names {
+		values := header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		b.WriteString(name + ": " + strings.Join(values, ",") + "\n")
+	}
+	return hex.EncodeToString(sm3Sum([]byte(b.String())))
+}
+
+// headerHashWriter sets X-SM3-Header-Hash from the response headers as they are when the status
+// line is written, so headers set by the next handler and the inner wrappers are all covered.
+type headerHashWriter struct {
+	rw      http.ResponseWriter
+	names   []string
+	written bool
+}
+
+func (h *headerHashWriter) Header() http.Header {
+	return h.rw.Header()
+}
+
+func (h *headerHashWriter) WriteHeader(code int) {
+	h.setHash()
+	h.rw.WriteHeader(code)
+}
+
+func (h *headerHashWriter) Write(b []byte) (int, error) {
+	h.setHash()
+	return h.rw.Write(b)
+}
+
+// finish sets the hash for a handler that wrote nothing.
+func (h *headerHashWriter) finish() {
+	h.setHash()
+}
+
+func (h *headerHashWriter) setHash() {
+	if h.written {
+		return
+	}
+	h.written = true
+	h.rw.Header().Set(sm3HeaderHashHeader, headerHash(h.rw.Header(), h.names))
+}
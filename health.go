@@ -0,0 +1,53 @@
+package gmsmPlugin
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// parseCIDRs parses the entries of the CIDR list config field name.
+func parseCIDRs(name string, cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", name, cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// containsIP reports whether addr is an IP address inside one of nets.
+func containsIP(nets []*net.IPNet, addr string) bool {
+	ip := net.ParseIP(addr)
+	for _, ipNet := range nets {
+		if ip != nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveHealth answers the health path with the state of the redis connection. When allowed
+// networks are configured, callers from other addresses get 403.
+func (p *MyPlugin) serveHealth(rw http.ResponseWriter, req *http.Request) {
+	if len(p.healthAllowedNets) > 0 {
+		if !containsIP(p.healthAllowedNets, clientIP(req)) {
+			p.writeError(rw, http.StatusForbidden, "forbidden")
+			return
+		}
+	}
+
+	conn, err := p.getConn()
+	if err == nil {
+		_, err = conn.Ping()
+		conn.Close()
+	}
+	if err != nil {
+		writeJSON(rw, http.StatusServiceUnavailable, map[string]string{"status": "degraded", "redis": "error", "error": err.Error()})
+		return
+	}
+	writeJSON(rw, http.StatusOK, map[string]string{"status": "ok", "redis": "ok"})
+}
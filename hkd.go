@@ -0,0 +1,99 @@
+package gmsmPlugin
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// hkdPathHeader carries the derivation path, e.g. "0/3/7".
+const hkdPathHeader = "X-HKD-Path"
+
+// sm3KDF is the SM3 based key derivation function of GB/T 32918.4:
+// SM3(z || ct) for ct = 1, 2, ... concatenated up to length bytes.
+func sm3KDF(z []byte, length int) []byte {
+	out := make([]byte, 0, length+32)
+	ct := make([]byte, 4)
+	for i := uint32(1); len(out) < length; i++ {
+		binary.BigEndian.PutUint32(ct, i)
+		out = append(out, sm3Sum(append(append([]byte{}, z...), ct...))...)
+	}
+	return out[:length]
+}
+
+// parseHKDPath parses a "/" separated list of child indexes.
+func parseHKDPath(path string, maxDepth int) ([]uint32, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) > maxDepth {
+		return nil, errors.New("derivation path too deep")
+	}
+
+	indexes := make([]uint32, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, errors.New("invalid derivation path")
+		}
+		indexes[i] = uint32(n)
+	}
+	return indexes, nil
+}
+
+// deriveChildKey derives the child private key at index from parent:
+// d_child = d_parent + KDF(d_parent || index) mod n.
+func deriveChildKey(parent *sm2.PrivateKey, index uint32) (*sm2.PrivateKey, error) {
+	curve := sm2.P256Sm2()
+	n := curve.Params().N
+
+	z := make([]byte, 36)
+	parent.D.FillBytes(z[:32])
+	binary.BigEndian.PutUint32(z[32:], index)
+
+	d := new(big.Int).SetBytes(sm3KDF(z, 32))
+	d.Add(d, parent.D)
+	d.Mod(d, n)
+	if d.Sign() == 0 {
+		return nil, errors.New("derived key is zero")
+	}
+
+	child := &sm2.PrivateKey{D: d}
+	child.Curve = curve
+	child.X, child.Y = curve.ScalarBaseMult(scalarBytes(d))
+	return child, nil
+}
+
+// serveHKD derives the key pair at the path from the X-HKD-Path header and returns its public key.
+func (p *MyPlugin) serveHKD(conn redisConn, rw http.ResponseWriter, req *http.Request) {
+	path := req.Header.Get(hkdPathHeader)
+	indexes, err := parseHKDPath(path, p.hkdDepth)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key := p.hkdMasterKey
+	for _, index := range indexes {
+		if key, err = deriveChildKey(key, index); err != nil {
+			p.writeError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	publicKey, err := x509.WritePublicKeyToPem(&key.PublicKey)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := conn.HSet("gmsm:hkd", path, string(publicKey)); err != nil {
+		p.logger.Error("保存派生公钥失败", logFields{"error": err})
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"path": path, "publicKey": string(publicKey), "code": 0})
+}
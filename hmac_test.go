@@ -0,0 +1,110 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	testHMACKeyOld = "000102030405060708090a0b0c0d0e0f"
+	testHMACKeyNew = "f0e0d0c0b0a090807060504030201000"
+)
+
+func TestVerifyRequestHMAC(t *testing.T) {
+	keys, err := parseHMACKeys(map[string]string{"2023": testHMACKeyOld, "2024": testHMACKeyNew, "2022": testHMACKeyOld})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &MyPlugin{logger: newLogger(io.Discard, "error")}
+	p.keys.Store(&runtimeKeys{hmacKeys: keys, hmacCurrentKeyID: "2024", hmacRetiredKeyIDs: map[string]bool{"2022": true}})
+
+	body := []byte(`{"amount":100}`)
+	sign := func(id string) string { return hex.EncodeToString(sm3HMAC(keys[id], body)) }
+
+	tests := []struct {
+		name   string
+		keyID  string
+		sig    string
+		body   string
+		wantOK bool
+	}{
+		{"current key", "2024", sign("2024"), string(body), true},
+		{"previous key still listed", "2023", sign("2023"), string(body), true},
+		{"retired key with a valid signature", "2022", sign("2022"), string(body), false},
+		{"unknown key ID", "2025", sign("2024"), string(body), false},
+		{"missing key ID", "", sign("2024"), string(body), false},
+		{"signature under another key", "2024", sign("2023"), string(body), false},
+		{"tampered body", "2024", sign("2024"), `{"amount":900}`, false},
+		{"malformed signature", "2024", "not hex", string(body), false},
+		{"missing signature", "2024", "", string(body), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set(hmacKeyIDHeader, tt.keyID)
+			req.Header.Set(hmacSigHeader, tt.sig)
+			rw := httptest.NewRecorder()
+			if got := p.verifyRequestHMAC(rw, req, []byte(tt.body)); got != tt.wantOK {
+				t.Fatalf("verifyRequestHMAC() = %v, want %v", got, tt.wantOK)
+			}
+			if !tt.wantOK && rw.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", rw.Code)
+			}
+		})
+	}
+}
+
+// A gateway signing with key 2023 and one verifying across a rotation: the signature is accepted
+// until 2023 is retired, and rejected afterwards although it is still valid under that key.
+func TestHMACKeyRotation(t *testing.T) {
+	f := newFakeRedis(t)
+	signer := newTestPlugin(t, f, func(c *Config) {
+		c.HMACKeyRotationEnabled = true
+		c.HMACRotationMode = "sign"
+		c.HMACKeys = map[string]string{"2023": testHMACKeyOld}
+		c.HMACCurrentKeyID = "2023"
+		c.DuplicateAction = "passthrough"
+		c.SMAlgorithm = ""
+	})
+	var signed http.Header
+	signer.next = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) { signed = req.Header.Clone() })
+	body := `{"amount":100}`
+	signer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if signed.Get(hmacKeyIDHeader) != "2023" || signed.Get(hmacSigHeader) == "" {
+		t.Fatalf("forwarded request carries %s=%q %s=%q", hmacKeyIDHeader, signed.Get(hmacKeyIDHeader), hmacSigHeader, signed.Get(hmacSigHeader))
+	}
+
+	tests := []struct {
+		name       string
+		retired    []string
+		wantStatus int
+	}{
+		{"before the rotation", nil, http.StatusOK},
+		{"after 2023 is retired", []string{"2023"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := newTestPlugin(t, f, func(c *Config) {
+				c.HMACKeyRotationEnabled = true
+				c.HMACRotationMode = "verify"
+				c.HMACKeys = map[string]string{"2023": testHMACKeyOld, "2024": testHMACKeyNew}
+				c.HMACCurrentKeyID = "2024"
+				c.HMACRetiredKeyIDs = tt.retired
+				c.DuplicateAction = "passthrough"
+				c.SMAlgorithm = ""
+			})
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req.Header.Set(hmacKeyIDHeader, signed.Get(hmacKeyIDHeader))
+			req.Header.Set(hmacSigHeader, signed.Get(hmacSigHeader))
+			rw := httptest.NewRecorder()
+			verifier.ServeHTTP(rw, req)
+			if rw.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rw.Code, tt.wantStatus, rw.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package gmsmPlugin
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// honeyTokenPattern matches strings shaped like the credentials that are planted as honey tokens:
+// UUIDs, hex strings of 32 to 128 characters and base64 strings of 20 characters or more.
+var honeyTokenPattern = regexp.MustCompile(
+	`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}` +
+		`|[0-9a-fA-F]{32,128}` +
+		`|[A-Za-z0-9+/_-]{20,}={0,2}`)
+
+// findHoneyToken returns the first candidate token in body whose SM3 is in the honey token set.
+func (p *MyPlugin) findHoneyToken(conn redisConn, body []byte) (string, bool) {
+	seen := make(map[string]bool)
+	for _, candidate := range honeyTokenPattern.FindAll(body, -1) {
+		if seen[string(candidate)] {
+			continue
+		}
+		seen[string(candidate)] = true
+
+		hashHex := fmt.Sprintf("%x", sm3Sum(candidate))
+		found, err := conn.SIsMember(p.honeyTokenSetKey, hashHex)
+		if err != nil {
+			p.logger.Error("查询蜜罐 token 集合失败", logFields{"error": err})
+			return "", false
+		}
+		if found {
+			return hashHex, true
+		}
+	}
+	return "", false
+}
+
+// serveHoneyToken alerts on a honey token hit, optionally stalls the client and answers with
+// the plausible fake response cached in redis.
+func (p *MyPlugin) serveHoneyToken(conn redisConn, rw http.ResponseWriter, req *http.Request, body []byte, hashHex string) {
+	p.logger.Error("[CRITICAL] 检测到蜜罐 token", logFields{
+		"hash": hashHex, "ip": clientIP(req), "method": req.Method, "path": req.URL.Path, "body": string(body),
+	})
+
+	if p.honeyTokenDelay > 0 {
+		time.Sleep(p.honeyTokenDelay)
+	}
+
+	template, err := conn.Get(p.honeyTokenTemplateKey)
+	if err != nil || template == "" {
+		template = `{"code":0,"message":"ok"}`
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	rw.Write([]byte(template))
+}
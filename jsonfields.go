@@ -0,0 +1,65 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// isJSONRequest reports whether the request declares an application/json body.
+func isJSONRequest(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// lookupJSONPath follows a dot separated path such as "user.id" through nested objects.
+func lookupJSONPath(v interface{}, path string) (interface{}, bool) {
+	for _, key := range strings.Split(path, ".") {
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// jsonFieldsInput builds the SM3 input for JSONHashFields: the configured paths in sorted order,
+// each present value appended in compact JSON with sorted object keys. JSON values delimit
+// themselves, so different field values cannot run together into the same input.
+func (p *MyPlugin) jsonFieldsInput(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	paths := append([]string(nil), p.jsonHashFields...)
+	sort.Strings(paths)
+
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	encoder.SetEscapeHTML(false)
+	for _, path := range paths {
+		value, ok := lookupJSONPath(document, path)
+		if !ok {
+			if p.requireAllJSONFields {
+				return nil, fmt.Errorf("missing field %q", path)
+			}
+			continue
+		}
+		if err := encoder.Encode(value); err != nil {
+			return nil, err
+		}
+		// Encode 会追加换行
+		input.Truncate(input.Len() - 1)
+	}
+	return input.Bytes(), nil
+}
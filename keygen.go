@@ -0,0 +1,82 @@
+package gmsmPlugin
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/asn1"
+	"encoding/pem"
+	"net/http"
+	"strings"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// oidNamedCurveSM2 identifies the SM2 curve (GB/T 33560).
+var oidNamedCurveSM2 = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+
+// sec1PrivateKey is the ECPrivateKey structure of RFC 5915.
+type sec1PrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// marshalECPrivateKey encodes an SM2 private key as SEC1 DER. gmsm only offers PKCS#8.
+func marshalECPrivateKey(key *sm2.PrivateKey) ([]byte, error) {
+	// 私钥按曲线阶长度左侧补零
+	d := make([]byte, (key.Curve.Params().N.BitLen()+7)/8)
+	key.D.FillBytes(d)
+	return asn1.Marshal(sec1PrivateKey{
+		Version:       1,
+		PrivateKey:    d,
+		NamedCurveOID: oidNamedCurveSM2,
+		PublicKey:     asn1.BitString{Bytes: elliptic.Marshal(key.Curve, key.X, key.Y)},
+	})
+}
+
+// serveKeyGen generates a fresh SM2 key pair and returns the private key as SEC1 PEM
+// and the public key as PKIX PEM. Callers must present KeyGenToken as a Bearer token.
+func (p *MyPlugin) serveKeyGen(conn redisConn, rw http.ResponseWriter, req *http.Request) {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if p.keyGenToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(p.keyGenToken)) != 1 {
+		p.logger.Warn("生成密钥对被拒绝", logFields{"ip": clientIP(req)})
+		p.writeError(rw, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	key, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	privateDER, err := marshalECPrivateKey(key)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateDER})
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	result := map[string]interface{}{"privateKey": string(privateKey), "publicKey": string(publicKey), "code": 0}
+	if p.attestationMode {
+		id, err := p.attestKey(conn, key)
+		if err != nil {
+			p.logger.Error("密钥证明失败", logFields{"error": err})
+			p.writeError(rw, http.StatusInternalServerError, "attestation failed")
+			return
+		}
+		result["attestationId"] = id
+	}
+
+	p.logger.Info("生成 SM2 密钥对", logFields{"ip": clientIP(req)})
+	writeJSON(rw, http.StatusOK, result)
+}
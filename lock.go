@@ -0,0 +1,62 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// mutexTTLMs bounds how long a request may hold the lock on its body hash.
+const mutexTTLMs = 5000
+
+// lockRetryInterval is the pause between attempts while waiting for a lock.
+const lockRetryInterval = 10 * time.Millisecond
+
+// releaseLockScript deletes KEYS[1] only while it still holds the caller's token,
+// so an expired lock taken over by another instance is left alone.
+const releaseLockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+// acquireLock tries once to take key for ttlMs milliseconds with SET NX PX. The random token
+// it returns must be passed to releaseLock.
+func (p *MyPlugin) acquireLock(conn redisConn, key string, ttlMs int64) (string, bool, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", false, err
+	}
+	token := hex.EncodeToString(raw)
+
+	reply, err := conn.SetWithParamsAndTime(key, token, "NX", "PX", ttlMs)
+	if err != nil {
+		return "", false, err
+	}
+	return token, reply == "OK", nil
+}
+
+// waitLock retries acquireLock until it succeeds or ttlMs has passed.
+func (p *MyPlugin) waitLock(conn redisConn, key string, ttlMs int64) (string, bool, error) {
+	deadline := time.Now().Add(time.Duration(ttlMs) * time.Millisecond)
+	for {
+		token, ok, err := p.acquireLock(conn, key, ttlMs)
+		if err != nil || ok || time.Now().After(deadline) {
+			return token, ok, err
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// releaseLock releases key if it is still held with token.
+func (p *MyPlugin) releaseLock(conn redisConn, key, token string) error {
+	_, err := conn.Eval(releaseLockScript, 1, key, token)
+	return err
+}
+
+// bodyLockKey is the lock key for requests with the given body hash.
+func (p *MyPlugin) bodyLockKey(req *http.Request, hash []byte) string {
+	return p.keyPrefix(req) + ":lock:" + hex.EncodeToString(hash)
+}
@@ -0,0 +1,124 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeReleaseLock is the fakeRedis stand-in for releaseLockScript.
+func fakeReleaseLock(call func(args ...string) interface{}, keys, argv []string) interface{} {
+	if value, ok := call("GET", keys[0]).([]byte); ok && string(value) == argv[0] {
+		return call("DEL", keys[0])
+	}
+	return int64(0)
+}
+
+func TestLock(t *testing.T) {
+	f := newFakeRedis(t)
+	f.script(releaseLockScript, fakeReleaseLock)
+	conn := f.conn(t, 0)
+	p := &MyPlugin{}
+
+	token, ok, err := p.acquireLock(conn, "lock", 1000)
+	if err != nil || !ok {
+		t.Fatalf("acquireLock() = %v, %v on a free key", ok, err)
+	}
+
+	tests := []struct {
+		name     string
+		step     func() error
+		wantHeld bool
+	}{
+		{"second acquire fails", func() error {
+			if _, ok, err := p.acquireLock(conn, "lock", 1000); err != nil || ok {
+				t.Errorf("acquireLock() = %v, %v on a held key", ok, err)
+			}
+			return nil
+		}, true},
+		{"release with another token", func() error { return p.releaseLock(conn, "lock", strings.Repeat("0", 32)) }, true},
+		{"release with the token", func() error { return p.releaseLock(conn, "lock", token) }, false},
+		{"release twice", func() error { return p.releaseLock(conn, "lock", token) }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.step(); err != nil {
+				t.Fatal(err)
+			}
+			if _, held := f.get(0, "lock"); held != tt.wantHeld {
+				t.Errorf("lock held = %v, want %v", held, tt.wantHeld)
+			}
+		})
+	}
+}
+
+func newMutexTestPlugin(t *testing.T, f *fakeRedis) *MyPlugin {
+	t.Helper()
+	f.script(releaseLockScript, fakeReleaseLock)
+	return newTestPlugin(t, f, func(c *Config) {
+		c.MutexEnabled = true
+		c.DuplicateAction = "reject"
+	})
+}
+
+// Two requests racing with the same body: one hashes and stores it, the other sees the
+// duplicate, and the lock is gone afterwards.
+func TestServeHTTPMutexRace(t *testing.T) {
+	f := newFakeRedis(t)
+	f.latency = time.Millisecond
+	p := newMutexTestPlugin(t, f)
+	body := []byte(`{"order":42}`)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rw := httptest.NewRecorder()
+			p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+			codes[i] = rw.Code
+		}(i)
+	}
+	wg.Wait()
+
+	if codes[0]+codes[1] != http.StatusOK+http.StatusConflict {
+		t.Errorf("status codes = %v, want one 200 and one 409", codes)
+	}
+	lockKey := p.bodyLockKey(httptest.NewRequest(http.MethodPost, "/", nil), sm3Sum(body))
+	if _, held := f.get(0, lockKey); held {
+		t.Error("lock was not released")
+	}
+}
+
+// A request whose body is locked by another instance waits for the release.
+func TestServeHTTPMutexWaits(t *testing.T) {
+	f := newFakeRedis(t)
+	p := newMutexTestPlugin(t, f)
+	body := []byte(`{"order":43}`)
+	lockKey := p.bodyLockKey(httptest.NewRequest(http.MethodPost, "/", nil), sm3Sum(body))
+	f.set(0, lockKey, "another instance")
+
+	done := make(chan int)
+	go func() {
+		rw := httptest.NewRecorder()
+		p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+		done <- rw.Code
+	}()
+
+	select {
+	case code := <-done:
+		t.Fatalf("request finished with %d while the body was locked", code)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := f.conn(t, 0).Del(lockKey); err != nil {
+		t.Fatal(err)
+	}
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("status = %d after the lock was released, want 200", code)
+	}
+}
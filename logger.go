@@ -0,0 +1,67 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// logLevels are the LogLevel values, least severe first.
+var logLevels = map[string]int{"debug": 0, "info": 1, "warn": 2, "error": 3}
+
+// logFields are the structured fields of a log entry. error values are logged as their message.
+type logFields map[string]interface{}
+
+// logEntry is one line of log output.
+type logEntry struct {
+	Level  string    `json:"level"`
+	TS     string    `json:"ts"`
+	Msg    string    `json:"msg"`
+	Fields logFields `json:"fields,omitempty"`
+}
+
+// logger writes entries at or above its level to out as one JSON object per line. Writes are
+// serialized, so entries from concurrent requests never interleave.
+type logger struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level int
+}
+
+// newLogger returns a logger writing to out; level must be a key of logLevels.
+func newLogger(out io.Writer, level string) *logger {
+	return &logger{out: out, level: logLevels[level]}
+}
+
+func (l *logger) log(level, msg string, fields logFields) {
+	if logLevels[level] < l.level {
+		return
+	}
+	for key, value := range fields {
+		if err, ok := value.(error); ok {
+			fields[key] = err.Error()
+		}
+	}
+	// 输出 UTF-8, 中文和请求体中的 <>& 都不转义; Encode 会在末尾加换行
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(logEntry{Level: level, TS: time.Now().UTC().Format(time.RFC3339), Msg: msg, Fields: fields}); err != nil {
+		line.Reset()
+		encoder.Encode(logEntry{Level: "error", TS: time.Now().UTC().Format(time.RFC3339), Msg: "日志字段无法编码: " + err.Error()})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(line.Bytes())
+}
+
+func (l *logger) Debug(msg string, fields logFields) { l.log("debug", msg, fields) }
+
+func (l *logger) Info(msg string, fields logFields) { l.log("info", msg, fields) }
+
+func (l *logger) Warn(msg string, fields logFields) { l.log("warn", msg, fields) }
+
+func (l *logger) Error(msg string, fields logFields) { l.log("error", msg, fields) }
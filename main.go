@@ -2,14 +2,25 @@ package gmsmPlugin
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/piaohao/godis"
+	"github.com/tjfoc/gmsm/sm2"
 	"github.com/tjfoc/gmsm/sm3"
+	"github.com/tjfoc/gmsm/x509"
 )
 
 // Config the plugin configuration.
@@ -19,6 +30,495 @@ type Config struct {
 	RedisPort     int    `json:"redisPort,omitempty"`
 	RedisDb       int    `json:"redisDb,omitempty"`
 	SMAlgorithm   string `json:"smAlgorithm,omitempty"`
+	// RedisPasswordEnvVar 非空时从该环境变量读取 redis 密码, 覆盖 RedisPassword
+	RedisPasswordEnvVar string `json:"redisPasswordEnvVar,omitempty"`
+
+	// DynamicConfigPath 非空时每 DynamicConfigReloadIntervalSeconds 秒重新读取该 JSON 文件(字段同本配置),
+	// 运行时只能修改 redisPassword(仅单节点)、sm4Key、sm3HMACKey、sm4CBCMACKey 和 hmacKeys/hmacCurrentKeyID/hmacRetiredKeyIDs,
+	// 其他字段的修改记录警告后忽略
+	DynamicConfigPath                  string `json:"dynamicConfigPath,omitempty"`
+	DynamicConfigReloadIntervalSeconds int    `json:"dynamicConfigReloadIntervalSeconds,omitempty"`
+
+	// RedisPool* redis 连接池: 最大连接数、最大空闲连接数、空闲连接的超时时间(秒)
+	RedisPoolMaxActive          int `json:"redisPoolMaxActive,omitempty"`
+	RedisPoolMaxIdle            int `json:"redisPoolMaxIdle,omitempty"`
+	RedisPoolIdleTimeoutSeconds int `json:"redisPoolIdleTimeoutSeconds,omitempty"`
+
+	// RedisSentinelAddrs 非空时通过 Sentinel("host:port", 也可用逗号分隔)查询 RedisMasterName 的主节点地址,
+	// 忽略 RedisHost/RedisPort; 主从切换后下一个请求重新查询
+	RedisSentinelAddrs []string `json:"redisSentinelAddrs,omitempty"`
+	RedisMasterName    string   `json:"redisMasterName,omitempty"`
+
+	// RedisTLSEnabled 使用 TLS 连接 redis; RedisTLSCACert 可以是 PEM 内容或文件路径, 为空时使用系统根证书
+	// RedisTLSClientCert/RedisTLSClientKey 为 PEM 格式的客户端证书和私钥, 服务端要求双向认证时配置
+	RedisTLSEnabled    bool   `json:"redisTLSEnabled,omitempty"`
+	RedisTLSCACert     string `json:"redisTLSCACert,omitempty"`
+	RedisTLSClientCert string `json:"redisTLSClientCert,omitempty"`
+	RedisTLSClientKey  string `json:"redisTLSClientKey,omitempty"`
+
+	// RedisClusterEnabled 使用 Redis Cluster, RedisClusterNodes("host:port")为初始节点, 忽略 RedisHost/RedisPort/RedisDb;
+	// 每个命令按 key 的槽位发往对应节点, 自动跟随 MOVED/ASK 重定向, 最多 RedisClusterMaxRedirects 次
+	RedisClusterEnabled      bool     `json:"redisClusterEnabled,omitempty"`
+	RedisClusterNodes        []string `json:"redisClusterNodes,omitempty"`
+	RedisClusterMaxRedirects int      `json:"redisClusterMaxRedirects,omitempty"`
+
+	// RedisPipelineEnabled 把并发请求的去重写入排队, 每 PipelineFlushIntervalMs 毫秒在独立连接上用一个 pipeline 发送
+	RedisPipelineEnabled    bool `json:"redisPipelineEnabled,omitempty"`
+	PipelineFlushIntervalMs int  `json:"pipelineFlushIntervalMs,omitempty"`
+
+	// PubSubEnabled 存储请求体 hash 后向 PubSubChannel 发布 {"hash","algorithm","ts","requestId"},
+	// 由后台 goroutine 在独立连接上发送; 队列(PubSubQueueSize 条)已满时丢弃消息并输出警告
+	PubSubEnabled   bool   `json:"pubSubEnabled,omitempty"`
+	PubSubChannel   string `json:"pubSubChannel,omitempty"`
+	PubSubQueueSize int    `json:"pubSubQueueSize,omitempty"`
+
+	// CountersPath 非空时每个算法成功处理的请求数记录在 redis <prefix>:counter:<算法>(INCR), GET CountersPath 返回
+	// {"SM3":12345,"SM4-CBC":678,...}; 计数由 CounterWorkers 个后台 goroutine 完成, 都在忙时丢弃本次计数并输出警告.
+	// CounterTTLDays 大于 0 时计数在最后一次更新 N 天后过期, 0 表示不过期
+	CountersPath   string `json:"countersPath,omitempty"`
+	CounterWorkers int    `json:"counterWorkers,omitempty"`
+	CounterTTLDays int    `json:"counterTTLDays,omitempty"`
+
+	// RedisEncryptionEnabled 用 SM4-GCM 和 RedisEncryptionKey(16 字节 hex)加密写入 redis 的值(SET/SETEX/SETNX/HSET/HMSET),
+	// 读取(GET/HGET/HGETALL)时解密; key、集合成员和 Lua 脚本参数不加密.
+	// 读到未加密的旧值时记录警告并按明文使用, RedisEncryptionStrictMode 为 true 时改为报错
+	RedisEncryptionEnabled    bool   `json:"redisEncryptionEnabled,omitempty"`
+	RedisEncryptionKey        string `json:"redisEncryptionKey,omitempty"`
+	RedisEncryptionStrictMode bool   `json:"redisEncryptionStrictMode,omitempty"`
+
+	// RedisKeyPrefix 请求体 SM3 hash 的 key 前缀, key 为 <prefix>:<hex-hash>; HashTTLSeconds 为 0 时不过期
+	// DuplicateAction 请求体重复时的处理: "reject" 返回 409, "passthrough" 照常处理
+	RedisKeyPrefix  string `json:"redisKeyPrefix,omitempty"`
+	HashTTLSeconds  int    `json:"hashTTLSeconds,omitempty"`
+	DuplicateAction string `json:"duplicateAction,omitempty"`
+
+	// NamespaceFromHeader 非空时取该请求头的值(只保留字母、数字、- 和 _, 最长 32 个字符)作为命名空间,
+	// 以 RedisKeyPrefix 为前缀的 key 变为 <namespace>:<prefix>:...; 其它单独配置的 key 不受影响.
+	// 请求未携带时 RequireNamespace 为 true 返回 400, 否则使用 RedisKeyPrefix
+	NamespaceFromHeader string `json:"namespaceFromHeader,omitempty"`
+	RequireNamespace    bool   `json:"requireNamespace,omitempty"`
+
+	// GeoIPEnabled 按客户端 IP(同限流, 见 TrustedProxyCIDRs)在 GeoIPDatabasePath(MaxMind MMDB 文件)中查询国家代码,
+	// 请求体 hash 的 key 变为 <prefix>:<国家代码>:<hex-hash>; 查不到或数据库无法打开时为 "XX".
+	// 最近 GeoIPCacheSize 个 IP 的结果缓存在内存中(LRU), 每分钟输出一次缓存命中率
+	GeoIPEnabled      bool   `json:"geoIPEnabled,omitempty"`
+	GeoIPDatabasePath string `json:"geoIPDatabasePath,omitempty"`
+	GeoIPCacheSize    int    `json:"geoIPCacheSize,omitempty"`
+
+	// StorageMode 请求 hash 的存储方式: "string" 每个 hash 一个 key, "zset" 写入有序集合 <prefix>:fingerprints, score 为 Unix 时间
+	// FingerprintRetentionSeconds zset 中保留的时长, 0 表示不清理; GET FingerprintsPath 返回最近的指纹
+	StorageMode                 string `json:"storageMode,omitempty"`
+	FingerprintRetentionSeconds int64  `json:"fingerprintRetentionSeconds,omitempty"`
+	FingerprintsPath            string `json:"fingerprintsPath,omitempty"`
+
+	// SM4Key SM4 密钥, 16 字节的 hex 字符串
+	SM4Key string `json:"sm4Key,omitempty"`
+	// SM4KeyEnvVar 非空时从该环境变量读取 SM4Key(hex), 避免把密钥写进 Traefik 配置; 变量未设置或为空时启动失败
+	SM4KeyEnvVar string `json:"sm4KeyEnvVar,omitempty"`
+	// SM4Passphrase/SM4Salt(hex) 用 SM3(salt || passphrase) 的前 16 字节作为 SM4 密钥, 配置后优先于 SM4Key;
+	// 只适合中等安全要求的场景, 生产环境应使用 HSM 保管密钥
+	SM4Passphrase string `json:"sm4Passphrase,omitempty"`
+	SM4Salt       string `json:"sm4Salt,omitempty"`
+	// SM4IV SM4-CBC/SM4-CBC-DECRYPT 使用的固定 IV, 16 字节的 hex 字符串
+	SM4IV string `json:"sm4IV,omitempty"`
+	// SM4CBCMACKey HMAC-SM3 密钥, 16 字节的 hex 字符串; 配置后 SM4-CBC 密文后附加 HMAC-SM3(iv || 密文) 标签,
+	// 解密前先校验标签. SM4-CBC-DECRYPT 必须配置
+	SM4CBCMACKey string `json:"sm4CBCMACKey,omitempty"`
+	// SM4DeterministicIV 由请求元数据和 redis 序列号(<prefix>:sm4:seq)派生 IV, 而不是每次读取 crypto/rand
+	SM4DeterministicIV bool `json:"sm4DeterministicIV,omitempty"`
+	// RejectWeakIV 为 true 时 SM4-OFB 拒绝请求头 X-SM4-IV 中字节全部相同(包括全零)或已经用过的 IV, 返回 400;
+	// 用过的 IV 记录在 redis <prefix>:ofbiv:<SM3(密钥 || IV)>, 过期时间同 HashTTLSeconds. 未携带 X-SM4-IV 时使用随机 IV
+	RejectWeakIV bool `json:"rejectWeakIV,omitempty"`
+	// CompressBeforeEncrypt SM4 加密前先压缩请求体, 密文前加 1 字节压缩标志(0=none, 1=gzip, 2 保留给 zstd)
+	CompressBeforeEncrypt bool `json:"compressBeforeEncrypt,omitempty"`
+	// CompressionAlgorithm 压缩算法: "gzip" 或 "none". 没有 vendor 的 zstd 实现, "zstd" 在启动时报错
+	CompressionAlgorithm string `json:"compressionAlgorithm,omitempty"`
+	// SM4PasswordDerived 用 SM4PasswordHeader 中的口令派生 SM4 密钥(scrypt, PRF 为 HMAC-SM3), 盐为 SM3(SM4PasswordSalt || 客户端 ID)
+	SM4PasswordDerived bool   `json:"sm4PasswordDerived,omitempty"`
+	SM4PasswordHeader  string `json:"sm4PasswordHeader,omitempty"`
+	SM4PasswordSalt    string `json:"sm4PasswordSalt,omitempty"`
+	SM4ScryptN         int    `json:"sm4ScryptN,omitempty"`
+	SM4ScryptR         int    `json:"sm4ScryptR,omitempty"`
+	SM4ScryptP         int    `json:"sm4ScryptP,omitempty"`
+	// DerivedKeyCacheSize/DerivedKeyCacheTTLSeconds 派生密钥 LRU 缓存的容量和过期时间
+	DerivedKeyCacheSize       int `json:"derivedKeyCacheSize,omitempty"`
+	DerivedKeyCacheTTLSeconds int `json:"derivedKeyCacheTTLSeconds,omitempty"`
+
+	// MultiAlgorithms SMAlgorithm 为 "MULTI" 时对同一请求体并行执行的算法("SM3", "SM4", "SM2"), 结果分别放在 sm3/sm4/sm2 字段;
+	// 未配置密钥的算法结果为 null, MultiFailOnMissing 为 true 时返回 400; MultiTimeoutMs 毫秒内未全部完成返回 504
+	MultiAlgorithms    []string `json:"multiAlgorithms,omitempty"`
+	MultiFailOnMissing bool     `json:"multiFailOnMissing,omitempty"`
+	MultiTimeoutMs     int      `json:"multiTimeoutMs,omitempty"`
+
+	// HashOutputMode SM3 结果的输出方式: "body" 以 JSON 替换响应体; "header" 写入请求头和响应头 X-SM3-Hash
+	// 后把原请求体转发给下游; "both" 写入响应头 X-SM3-Hash 并以 JSON 替换响应体.
+	// ForwardToNext 为 true 时 "body"/"both" 也把请求转发给下游, hash 放在请求头 X-SM3-Hash 中
+	HashOutputMode string `json:"hashOutputMode,omitempty"`
+	// HashEncoding SM3 结果的编码: "hex"(默认, 小写)、"HEX"(大写)、"base64"、"base64url"(URL 安全, 无填充),
+	// "raw" 以 application/octet-stream 直接输出 32 字节摘要, 不包装 JSON, 只能与 HashOutputMode "body" 一起使用;
+	// redis 中和日志里的 hash 仍为小写 hex
+	HashEncoding string `json:"hashEncoding,omitempty"`
+
+	// SM3HMACKey SM3-HMAC/SM3-HMAC-VERIFY 使用的 HMAC 密钥, 至少 16 字节的 hex 字符串
+	SM3HMACKey string `json:"sm3HMACKey,omitempty"`
+
+	// HMACKeyRotationEnabled 用可轮换的 HMAC-SM3 密钥对请求体签名. HMACKeys 为密钥 ID 到 hex 密钥(至少 16 字节)的映射.
+	// HMACRotationMode: "sign" 用 HMACCurrentKeyID 对应的密钥签名, 把 X-HMAC-KeyID 和 X-HMAC-Sig 加到转发的请求上;
+	// "verify" 按请求头 X-HMAC-KeyID 查找密钥并校验 X-HMAC-Sig, 失败返回 401.
+	// HMACRetiredKeyIDs 中的密钥 ID 即使签名正确也拒绝
+	HMACKeyRotationEnabled bool              `json:"hmacKeyRotationEnabled,omitempty"`
+	HMACCurrentKeyID       string            `json:"hmacCurrentKeyID,omitempty"`
+	HMACKeys               map[string]string `json:"hmacKeys,omitempty"`
+	HMACRotationMode       string            `json:"hmacRotationMode,omitempty"`
+	HMACRetiredKeyIDs      []string          `json:"hmacRetiredKeyIDs,omitempty"`
+
+	// SM2PrivateKeyPEM PKCS#8 PEM 格式的 SM2 私钥
+	SM2PrivateKeyPEM string `json:"sm2PrivateKeyPEM,omitempty"`
+	// SM2PrivateKeyEnvVar 非空时从该环境变量读取 SM2PrivateKeyPEM; 变量未设置或为空时启动失败
+	SM2PrivateKeyEnvVar string `json:"sm2PrivateKeyEnvVar,omitempty"`
+	// SM2PublicKeyPEM PEM 格式的 SM2 公钥, SM2-ENCRYPT 使用; 未配置时取 SM2PrivateKeyPEM 对应的公钥
+	SM2PublicKeyPEM string `json:"sm2PublicKeyPEM,omitempty"`
+	// EnvelopeEncryptionEnabled 为 true 时 SM2-ENCRYPT 改为数字信封: 随机 SM4 会话密钥加密请求体, SM2 公钥加密会话密钥,
+	// 返回 {"keyEnc","bodyEnc","iv"}(均为 base64); SM2-DECRYPT 接收同样的 JSON, 用 SM2 私钥解出会话密钥后解密请求体
+	EnvelopeEncryptionEnabled bool `json:"envelopeEncryptionEnabled,omitempty"`
+	// MaxRequestBodyBytes 请求体的最大字节数, 超过时返回 413, 默认 1MB; 0 表示不限制
+	// MaxBodyBytes 是旧的配置项, 非 0 时优先于 MaxRequestBodyBytes
+	MaxRequestBodyBytes int64 `json:"maxRequestBodyBytes,omitempty"`
+	MaxBodyBytes        int64 `json:"maxBodyBytes,omitempty"`
+	// StreamingThresholdBytes SM3 模式下 Content-Length 超过该值的请求体不整体读取, 边转发给下游边计算 SM3,
+	// 结果写入 redis 并放在 trailer SM3-Trailer-Hash 中(无法使用 trailer 的响应会被缓冲, 改为响应头); 0 表示关闭
+	StreamingThresholdBytes int64 `json:"streamingThresholdBytes,omitempty"`
+	// SM2SignResponse 用 SM2 私钥对响应体签名, 按 SM2SignatureFormat 编码后放在响应头 X-SM2-Signature 中
+	SM2SignResponse bool `json:"sm2SignResponse,omitempty"`
+	// SM2VerifyRequest 要求请求头 X-SM2-Signature 为请求体的 SM2 签名(SM2SignatureFormat 支持的任一编码, 以及 base64 DER),
+	// 用 SM2TrustedPublicKeyPEM 验证. 算法 "SM2VERIFY" 做同样的验证, 通过后返回 {"code":0}, 不转发
+	SM2VerifyRequest       bool   `json:"sm2VerifyRequest,omitempty"`
+	SM2TrustedPublicKeyPEM string `json:"sm2TrustedPublicKeyPEM,omitempty"`
+	// SM2SignatureFormat SM2 签名的编码: "der"(hex), "raw64"(base64url R||S), "raw_hex"(hex R||S), "cms"(base64 SignedData)
+	SM2SignatureFormat string `json:"sm2SignatureFormat,omitempty"`
+
+	// DeploymentValidationEnabled 校验请求签名是否已被 blue/green 部署处理过
+	DeploymentValidationEnabled bool   `json:"deploymentValidationEnabled,omitempty"`
+	BlueHashSetKey              string `json:"blueHashSetKey,omitempty"`
+	GreenHashSetKey             string `json:"greenHashSetKey,omitempty"`
+	// ActiveDeployment 保存当前活跃部署("blue" 或 "green")的 redis key
+	ActiveDeployment string `json:"activeDeployment,omitempty"`
+
+	// FingerprintDatabaseMode 将请求体的滑动窗口指纹写入 redis 集合, 用于近似重复检测
+	FingerprintDatabaseMode   bool   `json:"fingerprintDatabaseMode,omitempty"`
+	FingerprintDatabaseSetKey string `json:"fingerprintDatabaseSetKey,omitempty"`
+
+	// MIMEAlgorithmRouting 按 Content-Type 前缀选择算法, 未匹配时使用 SMAlgorithm
+	MIMEAlgorithmRouting map[string]string `json:"mimeAlgorithmRouting,omitempty"`
+
+	// HashSharding 按 hash 首字节把 SM3 结果分散到 ShardCount 个 redis 数据库(0 ~ ShardCount-1)
+	HashSharding             bool `json:"hashSharding,omitempty"`
+	ShardCount               int  `json:"shardCount,omitempty"`
+	MaintainShardConnections bool `json:"maintainShardConnections,omitempty"`
+	// ConsistentHashingEnabled 用一致性 hash 环(每个分片 ConsistentHashVNodes 个虚拟节点)代替取模分片
+	ConsistentHashingEnabled bool `json:"consistentHashingEnabled,omitempty"`
+	ConsistentHashVNodes     int  `json:"consistentHashVNodes,omitempty"`
+
+	// EventSourcingEnabled 把每个请求作为不可变事件写入 redis stream
+	EventSourcingEnabled bool   `json:"eventSourcingEnabled,omitempty"`
+	EventStreamKey       string `json:"eventStreamKey,omitempty"`
+	// EventMaxAge stream 中保留的最大(近似)事件数, 即 XADD 的 MAXLEN
+	EventMaxAge int `json:"eventMaxAge,omitempty"`
+
+	// HKDEnabled 按 X-HKD-Path 头从主密钥派生 SM2 子密钥, 派生的公钥按路径保存到 redis
+	HKDEnabled   bool   `json:"hkdEnabled,omitempty"`
+	HKDMasterKey string `json:"hkdMasterKey,omitempty"`
+	HKDDepth     int    `json:"hkdDepth,omitempty"`
+
+	// CanaryEnabled 检查响应体中是否出现已知敏感文档(CanaryHashes 为其 SM3 hex), 用于发现数据外泄.
+	// 响应体按 1 KiB 对齐切分, 只比较每个窗口 [k*1024, (k+1)*1024) 以及末尾不足 1 KiB 的部分;
+	// 所以 CanaryHashes 中应放敏感文档在响应中对齐位置的 1 KiB 窗口的 SM3, 不在对齐位置出现的文档不会被发现
+	CanaryEnabled      bool     `json:"canaryEnabled,omitempty"`
+	CanaryHashes       []string `json:"canaryHashes,omitempty"`
+	CanarySetKey       string   `json:"canarySetKey,omitempty"`
+	CanaryBlockOnMatch bool     `json:"canaryBlockOnMatch,omitempty"`
+
+	// TokenBindingEnabled 要求 TokenBindingHeader 中的值等于 TLS 客户端证书的 SM3 指纹, 成功的绑定记录在 redis hash <prefix>:tokenbinding
+	TokenBindingEnabled bool   `json:"tokenBindingEnabled,omitempty"`
+	TokenBindingHeader  string `json:"tokenBindingHeader,omitempty"`
+
+	// KeyGenPath POST 到该路径时生成新的 SM2 密钥对, 需要 Authorization: Bearer <KeyGenToken>
+	KeyGenPath  string `json:"keyGenPath,omitempty"`
+	KeyGenToken string `json:"keyGenToken,omitempty"`
+	// AttestationMode 为生成的密钥签发自签名证书(扩展中带配置的 SM3 hash), 并追加到 AttestationLogKey stream
+	AttestationMode   bool   `json:"attestationMode,omitempty"`
+	AttestationLogKey string `json:"attestationLogKey,omitempty"`
+
+	// CAMode 作为轻量级 SM2 CA: POST /ca/issue 签发证书, GET /ca/crl 获取 CRL
+	CAMode             bool   `json:"caMode,omitempty"`
+	CACertPEM          string `json:"caCertPEM,omitempty"`
+	CAKeyPEM           string `json:"caKeyPEM,omitempty"`
+	CACertValidityDays int    `json:"caCertValidityDays,omitempty"`
+
+	// HashBasedRouting 按请求体 SM3 hex 前缀(最长匹配优先)转发到上游 URL, 未匹配时交给 next
+	HashBasedRouting map[string]string `json:"hashBasedRouting,omitempty"`
+
+	// CanonicalHashVerification 校验请求头 X-SM3-Hash, 原始请求体不匹配时依次按 CanonicalHashFormats 规范化后重试
+	// 支持 "json-sorted", "whitespace-stripped", "crlf-to-lf"
+	CanonicalHashVerification bool     `json:"canonicalHashVerification,omitempty"`
+	CanonicalHashFormats      []string `json:"canonicalHashFormats,omitempty"`
+
+	// VotingMode POST /vote 保存用 VotingPublicKey 加密(SM2, C1C3C2)的投票, POST /tally 用 VotingPrivateKey 解密计票
+	// /tally 需要 Authorization: Bearer <VotingAdminToken>
+	VotingMode       bool   `json:"votingMode,omitempty"`
+	VotingPublicKey  string `json:"votingPublicKey,omitempty"`
+	VotingPrivateKey string `json:"votingPrivateKey,omitempty"`
+	VotingTallyKey   string `json:"votingTallyKey,omitempty"`
+	VotingAdminToken string `json:"votingAdminToken,omitempty"`
+
+	// AdaptiveCircuitBreaker 最近一分钟 P95 延迟超过 CircuitBreakerLatencyThresholdMs 时按比例返回 503,
+	// 拒绝比例从阈值处的 0% 线性增加到 2 倍阈值处的 100%
+	AdaptiveCircuitBreaker           bool `json:"adaptiveCircuitBreaker,omitempty"`
+	CircuitBreakerLatencyThresholdMs int  `json:"circuitBreakerLatencyThresholdMs,omitempty"`
+
+	// CircuitBreakerEnabled 连续 CircuitBreakerThreshold 次获取 redis 连接失败后熔断, CircuitBreakerTimeoutSeconds 秒内不再访问 redis,
+	// 之后放行一个请求试探(half-open), 成功则恢复. 熔断期间 CircuitBreakerFallthrough 为 true 时跳过 redis 照常计算 hash, 否则返回 503
+	CircuitBreakerEnabled        bool `json:"circuitBreakerEnabled,omitempty"`
+	CircuitBreakerThreshold      int  `json:"circuitBreakerThreshold,omitempty"`
+	CircuitBreakerTimeoutSeconds int  `json:"circuitBreakerTimeoutSeconds,omitempty"`
+	CircuitBreakerFallthrough    bool `json:"circuitBreakerFallthrough,omitempty"`
+
+	// BloomFilterEnabled 用 redis bitmap 实现的布隆过滤器做近似去重, 结果写入响应头 X-Bloom-Seen
+	// 第 i 个 hash 函数为 SM3(i || body) mod BloomFilterBits
+	BloomFilterEnabled   bool   `json:"bloomFilterEnabled,omitempty"`
+	BloomFilterKey       string `json:"bloomFilterKey,omitempty"`
+	BloomFilterBits      int    `json:"bloomFilterBits,omitempty"`
+	BloomFilterHashCount int    `json:"bloomFilterHashCount,omitempty"`
+
+	// PKIValidationEnabled 按 RFC 5280 校验 X-SM2-CertChain 头中的 SM2 证书链(URL 编码的 PEM, 叶子证书在前)
+	// PKITrustedRootsDir 下的 .pem/.crt/.cer 文件为信任根, 验证通过的链按 SM3(叶子证书 DER) 缓存 PKICacheTTLSeconds 秒
+	PKIValidationEnabled bool   `json:"pkiValidationEnabled,omitempty"`
+	PKITrustedRootsDir   string `json:"pkiTrustedRootsDir,omitempty"`
+	PKIMaxChainDepth     int    `json:"pkiMaxChainDepth,omitempty"`
+	PKICacheTTLSeconds   int    `json:"pkiCacheTTLSeconds,omitempty"`
+
+	// PIIMaskingEnabled 记录 JSON 请求体日志时把 PIIFields(支持 a.b.c 形式的嵌套路径)替换为 SM3 hex 的前 8 位
+	PIIMaskingEnabled bool     `json:"piiMaskingEnabled,omitempty"`
+	PIIFields         []string `json:"piiFields,omitempty"`
+
+	// PreimageSearchEnabled POST /preimage 随机搜索 SM3 前缀原像, 仅用于演示原像抗性的计算代价, 不要在生产环境开启
+	PreimageSearchEnabled       bool `json:"preimageSearchEnabled,omitempty"`
+	PreimageSearchMaxIterations int  `json:"preimageSearchMaxIterations,omitempty"`
+
+	// BatchHashPath 非空时 POST JSON 字符串数组到该路径, 逐个计算 SM3 并按输入顺序返回 {"results":[{"input","hash"}],"code":0};
+	// 数组长度超过 MaxBatchSize 返回 400; BatchStoreInRedis 为 true 时每个 hash 以 <prefix>:batch:<hash> 写入 redis
+	BatchHashPath     string `json:"batchHashPath,omitempty"`
+	MaxBatchSize      int    `json:"maxBatchSize,omitempty"`
+	BatchStoreInRedis bool   `json:"batchStoreInRedis,omitempty"`
+
+	// VerifyPath 非空时 POST {"data":"<base64>","hash":"<hex>"} 到该路径, 常量时间比较 data 的 SM3 与 hash,
+	// 返回 {"code":0,"match":true|false}; VerifyFromRedis 为 true 时可以用 "key" 代替 "data",
+	// 与 redis 中 <prefix>:<key> 存储的 hash(如 req:<请求 ID>)比较
+	VerifyPath      string `json:"verifyPath,omitempty"`
+	VerifyFromRedis bool   `json:"verifyFromRedis,omitempty"`
+	// 请求中带 "signature" 时改为校验 data 的 SM2 签名, 公钥由 "publicKey"(hex 曲线点)给出;
+	// AcceptCertAsPublicKey 为 true 时也可以用 "cert" 提供 PEM 证书或 PUBLIC KEY, 不检查证书有效期
+	AcceptCertAsPublicKey bool `json:"acceptCertAsPublicKey,omitempty"`
+
+	// MerklePath 非空时 POST {"hashes":["<hex>",...]} 到该路径, 以这些 SM3 hash 为叶子构建二叉 Merkle 树:
+	// 叶子节点为 SM3(0x00 || 叶子), 不足 2 的幂时补零 hash, 内部节点为 SM3(0x01 || 左 || 右);
+	// 返回 {"root","tree"} 并把叶子存入 redis <prefix>:merkle:<root>;
+	// GET <MerklePath>/proof?leaf=<hex>&root=<hex> 返回从叶子节点到根的兄弟节点路径
+	MerklePath string `json:"merklePath,omitempty"`
+
+	// TokenizationEnabled POST /tokenize 用 SM4-FF1 把卡号替换为同格式的 token, POST /detokenize 取回卡号
+	// TokenFormat: "luhn"(token 通过 Luhn 校验) 或 "digits"; TokenAuthKey 为 detokenize 的 HMAC-SM3 密钥(hex)
+	TokenizationEnabled bool   `json:"tokenizationEnabled,omitempty"`
+	TokenVaultKey       string `json:"tokenVaultKey,omitempty"`
+	TokenFormat         string `json:"tokenFormat,omitempty"`
+	TokenAuthKey        string `json:"tokenAuthKey,omitempty"`
+
+	// StreamSigningEnabled 读取 POST 请求体时每 StreamSigningWindowBytes 字节用 SM2 私钥签名当前的 SM3 摘要,
+	// 签名按 SM2SignatureFormat 编码后放在响应 trailer X-Stream-Sig-<offset> 中, 完整请求体的 SM3 放在 X-Stream-SM3 中
+	StreamSigningEnabled     bool `json:"streamSigningEnabled,omitempty"`
+	StreamSigningWindowBytes int  `json:"streamSigningWindowBytes,omitempty"`
+
+	// HoneyTokenEnabled 请求体中出现 HoneyTokenSetKey 集合里的蜜罐 token(保存其 SM3 hex)时告警,
+	// 延迟 HoneyTokenDelayMs 毫秒后返回 HoneyTokenTemplateKey 中缓存的假响应
+	HoneyTokenEnabled     bool   `json:"honeyTokenEnabled,omitempty"`
+	HoneyTokenSetKey      string `json:"honeyTokenSetKey,omitempty"`
+	HoneyTokenTemplateKey string `json:"honeyTokenTemplateKey,omitempty"`
+	HoneyTokenDelayMs     int    `json:"honeyTokenDelayMs,omitempty"`
+
+	// FeatureHashEnabled 按客户端 ID(X-Client-ID 头, FeatureClientCookie cookie 或 IP)的 SM3 分桶,
+	// 给上游请求加 X-Feature-<name>: enabled/disabled 头; FeatureHashSegments 为特性名 → 开启百分比
+	FeatureHashEnabled  bool           `json:"featureHashEnabled,omitempty"`
+	FeatureHashSegments map[string]int `json:"featureHashSegments,omitempty"`
+	FeatureClientCookie string         `json:"featureClientCookie,omitempty"`
+
+	// ConsistencyCheckEnabled 每 ConsistencyCheckInterval 秒计算 ConsistencyCheckKeys 值的 SM3,
+	// 与上一次不同时记录 CONSISTENCY_VIOLATION, 用于发现共享 redis 的其他客户端误改数据; 上一次的结果保存在 <RedisKeyPrefix>:consistency 中
+	ConsistencyCheckEnabled  bool     `json:"consistencyCheckEnabled,omitempty"`
+	ConsistencyCheckKeys     []string `json:"consistencyCheckKeys,omitempty"`
+	ConsistencyCheckInterval int      `json:"consistencyCheckInterval,omitempty"`
+
+	// SecretSharingEnabled 启动时把 SM2 私钥按 Shamir 门限方案拆成 SecretSharingTotal 份, 第 i 份存入 redis 数据库 SecretSharingFirstDB+i,
+	// 这些数据库不能与 RedisDb 或 hash 分片的数据库重叠. POST /recover-key 需要 Authorization: Bearer <SecretSharingAdminToken>,
+	// 用任意 SecretSharingThreshold 份恢复私钥, 签名一次请求体后立即清零
+	SecretSharingEnabled    bool   `json:"secretSharingEnabled,omitempty"`
+	SecretSharingThreshold  int    `json:"secretSharingThreshold,omitempty"`
+	SecretSharingTotal      int    `json:"secretSharingTotal,omitempty"`
+	SecretSharingFirstDB    int    `json:"secretSharingFirstDB,omitempty"`
+	SecretSharingAdminToken string `json:"secretSharingAdminToken,omitempty"`
+
+	// RateLimitEnabled 按客户端 IP 在 redis 中计数, RateLimitWindowSeconds 秒内超过 RateLimitRequests 次返回 429
+	RateLimitEnabled       bool `json:"rateLimitEnabled,omitempty"`
+	RateLimitRequests      int  `json:"rateLimitRequests,omitempty"`
+	RateLimitWindowSeconds int  `json:"rateLimitWindowSeconds,omitempty"`
+
+	// TokenBucketEnabled 按客户端 IP 在 redis 中维护令牌桶: 容量 TokenBucketCapacity, 每秒补充
+	// TokenBucketRefillRatePerSecond 个令牌, 桶空时返回 429 并在 Retry-After 中给出下一个令牌的等待秒数
+	TokenBucketEnabled             bool    `json:"tokenBucketEnabled,omitempty"`
+	TokenBucketCapacity            float64 `json:"tokenBucketCapacity,omitempty"`
+	TokenBucketRefillRatePerSecond float64 `json:"tokenBucketRefillRatePerSecond,omitempty"`
+
+	// TrustedProxyCIDRs 限流、令牌桶和 GeoIP 默认按连接的对端地址识别客户端; 对端在这些网段内时才读取 X-Forwarded-For,
+	// 从右往左取第一个不属于这些网段的地址. 客户端自己写入的 X-Forwarded-For 不被信任, 不能用来绕过限流
+	TrustedProxyCIDRs []string `json:"trustedProxyCIDRs,omitempty"`
+
+	// HashResponseBody 缓冲响应体并把 SM3 hash 写入响应头 X-SM3-Response-Hash;
+	// 超过 MaxResponseBuffer 字节时不再缓冲, 直接转发并把该头设为 skipped-oversized
+	HashResponseBody  bool `json:"hashResponseBody,omitempty"`
+	MaxResponseBuffer int  `json:"maxResponseBuffer,omitempty"`
+	// DecompressBeforeHash 需要 HashResponseBody: Content-Encoding 为 gzip 或 deflate 的响应按解压后的内容计算 hash,
+	// 再用原算法重新压缩发送, 并更新 Content-Length; 解压失败时返回 502
+	DecompressBeforeHash bool `json:"decompressBeforeHash,omitempty"`
+
+	// HashResponseHeaders 对列出的响应头计算 SM3 并写入 X-SM3-Header-Hash, 供客户端发现代理或 CDN 改写了响应头;
+	// HashRequestHeaders 对列出的请求头计算 SM3 并写入 X-SM3-Req-Header-Hash 转发给下游.
+	// 规范化: 头名小写并排序, 每行 "name: value\n", 同名多值以逗号连接, 不存在的头跳过
+	HashResponseHeaders []string `json:"hashResponseHeaders,omitempty"`
+	HashRequestHeaders  []string `json:"hashRequestHeaders,omitempty"`
+
+	// HashURLEnabled 计算 SM3(方法 + URL(路径和查询串) + 请求体), 写入 redis <prefix>:urlsig:<hash>(过期时间 URLSignTTLSeconds)
+	// 并放在响应头 X-SM3-URL-Hash 中, 用于生成预签名 URL. 查询串带 _sm3sig=<hash>&_sm3exp=<Unix 时间> 的请求按预签名 URL 验证:
+	// 未过期、去掉这两个参数后的 hash 等于 _sm3sig 且 redis 中仍有记录时去掉参数交给下游, 否则返回 403
+	HashURLEnabled    bool `json:"hashURLEnabled,omitempty"`
+	URLSignTTLSeconds int  `json:"urlSignTTLSeconds,omitempty"`
+
+	// HashChainingEnabled SM3 按 SM3(上一个 hash || 请求体) 计算, 形成 hash 链; 链头保存在 ChainRedisKey(为空时 <prefix>:{chain}:head),
+	// 第一个请求以 32 个零字节作为上一个 hash. 链头由 Lua 脚本比较后替换, 并发请求改动链头时重新计算; 响应中包含 "previousHash".
+	// 每个链节点 {"hash","previousHash","ts","requestId"} 追加到 <ChainRedisKey>:history, GET ChainHistoryPath?count=N 返回最近 N 个(默认 10).
+	// redis 集群中 ChainRedisKey 必须带 hash tag(如 "{gmsm}:chain:head"), 使链头和历史在同一个 slot
+	HashChainingEnabled bool   `json:"hashChainingEnabled,omitempty"`
+	ChainRedisKey       string `json:"chainRedisKey,omitempty"`
+	ChainHistoryPath    string `json:"chainHistoryPath,omitempty"`
+
+	// MutexEnabled 处理请求前按请求体 SM3 hash 在 redis 中加锁, 相同请求体的去重、hash 和写入串行执行
+	MutexEnabled bool `json:"mutexEnabled,omitempty"`
+
+	// VerifyClientCert 用 TrustedCABundlePEM(可包含多个 PEM 证书)验证 X-Client-Cert 头中的 SM2 客户端证书(PEM 的 base64),
+	// 通过后把证书的 CN 写入请求头 X-Client-CN, 否则返回 403
+	VerifyClientCert   bool   `json:"verifyClientCert,omitempty"`
+	TrustedCABundlePEM string `json:"trustedCABundlePEM,omitempty"`
+
+	// Response* SM3 结果 JSON 的字段名和成功码; ExtraResponseFields 为附加的固定字段, 例如 "version": "1.0"
+	ResponseResultField  string            `json:"responseResultField,omitempty"`
+	ResponseCodeField    string            `json:"responseCodeField,omitempty"`
+	ResponseMessageField string            `json:"responseMessageField,omitempty"`
+	ResponseCodeSuccess  int               `json:"responseCodeSuccess,omitempty"`
+	ExtraResponseFields  map[string]string `json:"extraResponseFields,omitempty"`
+
+	// ForwardToNext 未知算法(不做处理)及 SM3 时把请求连同原请求体交给下游, SM3 的结果放在请求头 X-SM3-Hash 中
+	// (HashEncoding "raw" 除外); 为 false 时保持旧行为, 直接把请求体原样输出或以 SM3 结果作为响应
+	ForwardToNext bool `json:"forwardToNext,omitempty"`
+
+	// MultipartPerField SM3 模式下对 multipart/form-data 请求逐个字段流式计算 hash, 返回字段名到 hash 的对象
+	MultipartPerField bool `json:"multipartPerField,omitempty"`
+
+	// JSONHashFields SM3 模式下对 application/json 请求只对这些字段(支持 "user.id" 形式的嵌套路径)计算 hash:
+	// 按路径排序后把各字段值的紧凑 JSON 依次拼接; RequireAllJSONFields 为 true 时缺少字段返回 400, 否则跳过
+	JSONHashFields       []string `json:"jsonHashFields,omitempty"`
+	RequireAllJSONFields bool     `json:"requireAllJSONFields,omitempty"`
+
+	// ReplayProtectionEnabled 要求请求头 NonceHeader 携带至少 16 个 hex 字符的 nonce(可以是 UUID),
+	// NonceTTLSeconds 内重复的 nonce 返回 409
+	ReplayProtectionEnabled bool   `json:"replayProtectionEnabled,omitempty"`
+	NonceTTLSeconds         int    `json:"nonceTTLSeconds,omitempty"`
+	NonceHeader             string `json:"nonceHeader,omitempty"`
+
+	// SessionEnabled POST 到 SessionIssuePath 时生成 32 字节随机令牌, 以 <prefix>:session:<令牌 SM3> 存入 redis,
+	// SessionTTLSeconds 秒后过期, 令牌通过 Cookie SessionCookieName 返回; 签发请求总要通过 nonce 检查.
+	// 之后携带该 Cookie 的请求会校验会话, 过期或不存在时返回 401
+	SessionEnabled    bool   `json:"sessionEnabled,omitempty"`
+	SessionTTLSeconds int    `json:"sessionTTLSeconds,omitempty"`
+	SessionCookieName string `json:"sessionCookieName,omitempty"`
+	SessionIssuePath  string `json:"sessionIssuePath,omitempty"`
+
+	// HealthPath 健康检查路径(精确匹配, 区分大小写), 只检查 redis 连接; 为空时关闭
+	// HealthAllowedCIDRs 非空时只允许这些网段访问健康检查, 其他来源返回 403
+	HealthPath         string   `json:"healthPath,omitempty"`
+	HealthAllowedCIDRs []string `json:"healthAllowedCIDRs,omitempty"`
+
+	// AllowedMethods 只处理这些方法的请求, 默认 POST/PUT/PATCH, 为空时处理所有方法;
+	// AllowedPathPrefixes 非空时只处理路径以其中之一开头的请求. 两个条件同时满足才处理,
+	// 否则不读取请求体直接交给下游. 插件自己的 GET 端点(事件、指纹、CRL)和 HKD 请求不受限制
+	AllowedMethods      []string `json:"allowedMethods,omitempty"`
+	AllowedPathPrefixes []string `json:"allowedPathPrefixes,omitempty"`
+
+	// CORSEnabled 为请求头 Origin 匹配 CORSAllowedOrigins 的请求加上 Access-Control-* 响应头(回显 Origin, 允许携带凭据);
+	// CORSAllowedOrigins 中 "*" 匹配任意来源, 含 "*" 的项按通配符匹配(如 "https://*.example.com"), 其余精确匹配.
+	// OPTIONS 预检请求直接返回 204, 不读取请求体, Access-Control-Allow-Methods 为 AllowedMethods(为空时回显请求的方法),
+	// Access-Control-Allow-Headers 为 CORSAllowedHeaders, Access-Control-Max-Age 为 CORSMaxAgeSeconds.
+	// 来源不匹配时不加 CORS 响应头, 由浏览器拒绝, 插件不返回 403
+	CORSEnabled        bool     `json:"corsEnabled,omitempty"`
+	CORSAllowedOrigins []string `json:"corsAllowedOrigins,omitempty"`
+	CORSAllowedHeaders []string `json:"corsAllowedHeaders,omitempty"`
+	CORSMaxAgeSeconds  int      `json:"corsMaxAgeSeconds,omitempty"`
+
+	// RequestIDHeader 请求 ID 头, 请求未携带时生成 UUID v4, 并在响应中原样返回; SM3 结果另存一份到 <prefix>:req:<请求 ID>
+	// LogRequestID 为 true 时按请求输出一条 info 日志, fields 为 {"requestId","algorithm","hash"}
+	RequestIDHeader string `json:"requestIDHeader,omitempty"`
+	LogRequestID    bool   `json:"logRequestID,omitempty"`
+
+	// InjectSM3Auth 交给下游前把请求头 Authorization 设为 "<SM3AuthScheme> <请求体 SM3 hex>", 原值保存到 X-Original-Authorization;
+	// 请求体为空时不修改, 除非 HashEmptyBody 为 true
+	InjectSM3Auth bool   `json:"injectSM3Auth,omitempty"`
+	SM3AuthScheme string `json:"sm3AuthScheme,omitempty"`
+	HashEmptyBody bool   `json:"hashEmptyBody,omitempty"`
+
+	// TimestampBinding 为 true 时 SM3 计算 SM3(请求体 || 大端 8 字节的 Unix 时间/TimestampGranularitySeconds),
+	// 结果只在一个时间窗口内有效; 响应的 "ts" 字段和响应头 X-Timestamp-Window 为所用的窗口编号.
+	// 请求头 X-Timestamp-Window 可以指定之前的窗口, 最多早 AcceptedWindowDrift 个, 否则返回 400
+	TimestampBinding            bool  `json:"timestampBinding,omitempty"`
+	TimestampGranularitySeconds int64 `json:"timestampGranularitySeconds,omitempty"`
+	AcceptedWindowDrift         int   `json:"acceptedWindowDrift,omitempty"`
+
+	// GenerateUUID 为 true 时 SM3 的 JSON 响应增加 "uuid" 字段: 取 SM3(UUIDNamespace 的 16 字节 || 请求体) 的前 16 字节,
+	// 按 RFC 9562 设置版本 8 和变体位, 相当于用 SM3 代替 SHA-1 的 UUID v5
+	GenerateUUID  bool   `json:"generateUUID,omitempty"`
+	UUIDNamespace string `json:"uuidNamespace,omitempty"`
+
+	// SM3AuthChallenge 为 true 时请求必须携带 "Authorization: <SM3AuthScheme> <请求体 SM3 hex>",
+	// 缺少或 hash 不一致时返回 401 和 WWW-Authenticate: <SM3AuthScheme> realm="<Realm>", 不交给下游
+	SM3AuthChallenge bool   `json:"sm3AuthChallenge,omitempty"`
+	Realm            string `json:"realm,omitempty"`
+
+	// ResponseCacheEnabled 按请求体 SM3 缓存 2xx 响应(状态码、响应头、响应体)到 redis hash <prefix>:cache:<hash>,
+	// 相同请求体直接返回缓存, 不再交给下游; CacheTTLSeconds 为 0 时不过期
+	// CacheVaryHeaders 中的请求头也参与缓存 key 的计算; 空请求体不缓存
+	ResponseCacheEnabled bool     `json:"responseCacheEnabled,omitempty"`
+	CacheTTLSeconds      int      `json:"cacheTTLSeconds,omitempty"`
+	CacheVaryHeaders     []string `json:"cacheVaryHeaders,omitempty"`
+
+	// ErrorFormat 错误响应的格式: "json", "text" 或 "xml"
+	ErrorFormat string `json:"errorFormat,omitempty"`
+
+	// LogLevel 日志级别: "debug", "info", "warn" 或 "error"; 日志按行输出 JSON 到 stdout:
+	// {"level","ts","msg","fields"}
+	LogLevel string `json:"logLevel,omitempty"`
+	// TracingEnabled 按 W3C Trace Context 继续请求头 traceparent 中的 trace(没有或无效时新建),
+	// 转发给下游的 traceparent 使用新生成的 span ID, tracestate 原样转发; hash 相关日志带 traceId 字段
+	TracingEnabled bool `json:"tracingEnabled,omitempty"`
+
+	// EncryptResponse 用 SM4-CBC(SM4Key/SM4IV)加密 2xx 响应体, 以 base64 的 application/octet-stream 返回;
+	// 非 2xx 响应原样返回
+	EncryptResponse bool `json:"encryptResponse,omitempty"`
 }
 
 // CreateConfig creates the default plugin configuration.
@@ -29,59 +529,2178 @@ func CreateConfig() *Config {
 		RedisPassword: "",
 		RedisPort:     6379,
 		RedisDb:       0,
+
+		RedisPoolMaxActive:          8,
+		RedisPoolMaxIdle:            8,
+		RedisPoolIdleTimeoutSeconds: 300,
+
+		RedisClusterMaxRedirects: 3,
+
+		DynamicConfigReloadIntervalSeconds: 30,
+
+		PipelineFlushIntervalMs: 2,
+
+		PubSubChannel:   "gmsm:hashes",
+		PubSubQueueSize: 256,
+
+		CounterWorkers: 4,
+
+		RedisKeyPrefix:  "gmsm",
+		DuplicateAction: "reject",
+
+		GeoIPCacheSize: 1024,
+
+		StorageMode:                 "string",
+		FingerprintRetentionSeconds: 86400,
+
+		HashOutputMode: "body",
+		HashEncoding:   "hex",
+
+		MultiAlgorithms: []string{"SM3", "SM4", "SM2"},
+		MultiTimeoutMs:  5000,
+
+		CompressionAlgorithm: "gzip",
+
+		SM2SignatureFormat:  "der",
+		MaxRequestBodyBytes: 1 << 20,
+
+		StreamingThresholdBytes: 64 << 10,
+
+		SM4PasswordHeader:         "X-SM4-Password",
+		SM4ScryptN:                16384,
+		SM4ScryptR:                8,
+		SM4ScryptP:                1,
+		DerivedKeyCacheSize:       1024,
+		DerivedKeyCacheTTLSeconds: 300,
+
+		BlueHashSetKey:   "gmsm:deployment:blue",
+		GreenHashSetKey:  "gmsm:deployment:green",
+		ActiveDeployment: "gmsm:deployment:active",
+
+		FingerprintDatabaseSetKey: "gmsm:fingerprints",
+
+		EventStreamKey: "gmsm:events",
+		EventMaxAge:    100000,
+
+		ConsistentHashVNodes: 160,
+
+		HKDDepth: 5,
+
+		CanarySetKey: "gmsm:canary",
+
+		TokenBindingHeader: "X-Token-Binding",
+
+		AttestationLogKey: "gmsm:attestations",
+
+		CACertValidityDays: 365,
+
+		VotingTallyKey: "gmsm:votes",
+
+		CircuitBreakerLatencyThresholdMs: 1000,
+
+		CircuitBreakerThreshold:      5,
+		CircuitBreakerTimeoutSeconds: 30,
+
+		BloomFilterKey:       "gmsm:bloom",
+		BloomFilterBits:      1 << 24,
+		BloomFilterHashCount: 7,
+
+		PKIMaxChainDepth:   5,
+		PKICacheTTLSeconds: 300,
+
+		PreimageSearchMaxIterations: 1000000,
+
+		MaxBatchSize: 100,
+
+		TokenVaultKey: "gmsm:tokenvault",
+		TokenFormat:   "luhn",
+
+		StreamSigningWindowBytes: 1 << 20,
+
+		HoneyTokenSetKey:      "gmsm:honeytokens",
+		HoneyTokenTemplateKey: "gmsm:honeytokens:template",
+
+		FeatureClientCookie: "gmsm_client_id",
+
+		ConsistencyCheckInterval: 60,
+
+		SecretSharingThreshold: 3,
+		SecretSharingTotal:     5,
+		SecretSharingFirstDB:   10,
+
+		RateLimitRequests:      100,
+		RateLimitWindowSeconds: 60,
+
+		TokenBucketCapacity:            100,
+		TokenBucketRefillRatePerSecond: 10,
+
+		MaxResponseBuffer: 10 << 20,
+
+		ResponseResultField:  "result",
+		ResponseCodeField:    "code",
+		ResponseMessageField: "message",
+		ResponseCodeSuccess:  0,
+
+		ForwardToNext: true,
+
+		NonceTTLSeconds: 300,
+		NonceHeader:     "X-Request-Nonce",
+
+		SessionTTLSeconds: 3600,
+		SessionCookieName: "gmsm_session",
+		SessionIssuePath:  "/session",
+
+		RequestIDHeader: "X-Request-ID",
+
+		AllowedMethods: []string{http.MethodPost, http.MethodPut, http.MethodPatch},
+
+		CORSAllowedHeaders: []string{"Content-Type", "Authorization"},
+		CORSMaxAgeSeconds:  600,
+
+		URLSignTTLSeconds: 3600,
+
+		SM3AuthScheme: "SM3",
+		Realm:         "gmsm-plugin",
+
+		TimestampGranularitySeconds: 60,
+
+		HMACRotationMode: "sign",
+
+		CacheTTLSeconds: 300,
+
+		ErrorFormat: "json",
+		LogLevel:    "info",
 	}
 }
 
+// knownAlgorithms lists the SMAlgorithm values handled by ServeHTTP.
+var knownAlgorithms = map[string]bool{
+	"SM3":    true,
+	"SM4":    true,
+	"SM2VRF": true,
+	"SM4CCM": true,
+
+	"SM2SIGN":   true,
+	"SM2VERIFY": true,
+
+	"SM4-ECB":         true,
+	"SM4-CBC":         true,
+	"SM4-CBC-DECRYPT": true,
+	"SM4-OFB":         true,
+
+	"SM2-ENCRYPT": true,
+	"SM2-DECRYPT": true,
+
+	"SM3-HMAC":        true,
+	"SM3-HMAC-VERIFY": true,
+
+	"MULTI": true,
+}
+
 // MyPlugin plugin.
 type MyPlugin struct {
 	next        http.Handler
 	smAlgorithm string
-	redis       *godis.Redis
+	mimeRouting map[string]string
+	// pool is the single-node or sentinel pool; a reload may swap it
+	pool    atomic.Pointer[trackedPool]
+	cluster *clusterClient
+	// newPool creates a pool with another password; nil unless redis is a single node
+	newPool     func(password string) redisPool
+	pipeline    *redisPipeline
+	publisher   *hashPublisher
+	redisCipher *redisCipher
+	shards      *shardedRedis
+
+	// counterJobs feeds counter keys to the counter workers; nil unless CountersPath is set
+	counterJobs  chan string
+	countersPath string
+	counterTTL   int
+
+	// keys holds the keys a reload may replace. It is swapped as a whole and requests never wait
+	// for a reload. config is the running configuration, only touched by New and the reload goroutine.
+	keys   atomic.Pointer[runtimeKeys]
+	config Config
+
+	redisKeyPrefix  string
+	hashTTL         int
+	duplicateAction string
+
+	namespaceFromHeader string
+	requireNamespace    bool
+
+	geoIP *geoIP
+
+	storageMode          string
+	fingerprintRetention int64
+	fingerprintsPath     string
+
+	sm4IV              []byte
+	sm4DeterministicIV bool
+	rejectWeakIV       bool
+	compressBeforeSM4  bool
+	compressionFlag    byte
+
+	sm4PasswordDerived bool
+	sm4PasswordHeader  string
+	sm4PasswordSalt    string
+	sm4ScryptN         int
+	sm4ScryptR         int
+	sm4ScryptP         int
+	derivedKeys        *derivedKeyCache
+
+	hashOutputMode string
+	hashEncoding   string
+
+	hmacKeyRotation bool
+	hmacVerify      bool
+
+	multiAlgorithms    map[string]bool
+	multiFailOnMissing bool
+	multiTimeout       time.Duration
+
+	sm2PrivateKey      *sm2.PrivateKey
+	sm2PublicKey       *sm2.PublicKey
+	envelopeEncryption bool
+	sm2SignatureFormat string
+	sm2SignResponse    bool
+	maxBodyBytes       int64
+
+	streamingThreshold int64
+
+	sm2VerifyRequest    bool
+	sm2TrustedPublicKey *sm2.PublicKey
+
+	deploymentValidation bool
+	blueHashSetKey       string
+	greenHashSetKey      string
+	activeDeploymentKey  string
+
+	fingerprintDatabase bool
+	fingerprintSetKey   string
+
+	eventSourcing  bool
+	eventStreamKey string
+	eventMaxAge    int
+
+	hkdMasterKey *sm2.PrivateKey
+	hkdDepth     int
+
+	canaryEnabled      bool
+	canarySetKey       string
+	canaryBlockOnMatch bool
+
+	tokenBinding       bool
+	tokenBindingHeader string
+
+	keyGenPath        string
+	keyGenToken       string
+	attestationMode   bool
+	attestationLogKey string
+	configHash        []byte
+
+	caCert         *x509.Certificate
+	caKey          *sm2.PrivateKey
+	caValidityDays int
+
+	hashRoutes []hashRoute
+
+	canonicalHashVerification bool
+	canonicalHashFormats      []string
+
+	votingMode       bool
+	votingPrivateKey *sm2.PrivateKey
+	votingTallyKey   string
+	votingAdminToken string
+
+	circuitBreaker *circuitBreaker
+
+	redisBreaker       *redisBreaker
+	breakerFallthrough bool
+
+	bloomFilter          bool
+	bloomFilterKey       string
+	bloomFilterBits      int64
+	bloomFilterHashCount int
+
+	pkiRoots         []*x509.Certificate
+	pkiMaxChainDepth int
+	pkiCacheTTL      time.Duration
+
+	piiMasking bool
+	piiFields  []string
+
+	preimageSearch        bool
+	preimageMaxIterations int
+
+	batchHashPath     string
+	maxBatchSize      int
+	batchStoreInRedis bool
+
+	verifyPath      string
+	verifyFromRedis bool
+
+	acceptCertAsPublicKey bool
+
+	merklePath string
+
+	tokenization  bool
+	tokenVaultKey string
+	tokenFormat   string
+	tokenAuthKey  []byte
+
+	streamSigning bool
+	streamWindow  int
+
+	honeyToken            bool
+	honeyTokenSetKey      string
+	honeyTokenTemplateKey string
+	honeyTokenDelay       time.Duration
+
+	featureHash     bool
+	featureSegments map[string]int
+	featureCookie   string
+
+	sharingThreshold  int
+	sharingAdminToken string
+	shareStores       *shardedRedis
+
+	rateLimit       bool
+	rateLimitMax    int
+	rateLimitWindow int
+
+	tokenBucket           bool
+	tokenBucketCapacity   float64
+	tokenBucketRefillRate float64
+
+	trustedProxyNets []*net.IPNet
+
+	hashResponseBody     bool
+	maxResponseBuffer    int
+	decompressBeforeHash bool
+
+	hashResponseHeaders []string
+	hashRequestHeaders  []string
+
+	hashURL    bool
+	urlSignTTL int
+
+	hashChaining     bool
+	chainRedisKey    string
+	chainHistoryPath string
+
+	mutex bool
+
+	clientCARoots *x509.CertPool
+
+	responseResultField  string
+	responseCodeField    string
+	responseMessageField string
+	responseCodeSuccess  int
+	extraResponseFields  map[string]string
+
+	forwardToNext bool
+
+	multipartPerField bool
+
+	jsonHashFields       []string
+	requireAllJSONFields bool
+
+	replayProtection bool
+	nonceTTL         int
+	nonceHeader      string
+
+	session           bool
+	sessionTTL        int
+	sessionCookieName string
+	sessionIssuePath  string
+
+	healthPath        string
+	healthAllowedNets []*net.IPNet
+
+	allowedMethods      map[string]bool
+	allowedPathPrefixes []string
+
+	corsEnabled        bool
+	corsAllowedOrigins []string
+	corsAllowedMethods string
+	corsAllowedHeaders string
+	corsMaxAge         int
+
+	requestIDHeader string
+	logRequestID    bool
+
+	injectSM3Auth bool
+	sm3AuthScheme string
+	hashEmptyBody bool
+
+	sm3AuthChallenge bool
+	realm            string
+
+	timestampBinding     bool
+	timestampGranularity int64
+	acceptedWindowDrift  int
+
+	// uuidNamespace 非空时 SM3 响应带 uuid
+	uuidNamespace []byte
+
+	responseCache    bool
+	cacheTTL         int
+	cacheVaryHeaders []string
+
+	errorFormat string
+	logger      *logger
+	tracing     bool
+
+	encryptResponse bool
 }
 
 // New created a new MyPlugin plugin.
 func New(ctx context.Context, next http.Handler, config *Config, name string) (http.Handler, error) {
-	// redis
-	redis := godis.NewRedis(&godis.Option{
-		Host:     config.RedisHost,
-		Port:     config.RedisPort,
-		Password: config.RedisPassword,
-		Db:       config.RedisDb,
-	})
+	if _, ok := logLevels[config.LogLevel]; !ok {
+		return nil, fmt.Errorf("unknown logLevel: %s", config.LogLevel)
+	}
+	logger := newLogger(os.Stdout, config.LogLevel)
 
-	return &MyPlugin{
-		smAlgorithm: config.SMAlgorithm,
-		redis:       redis,
-		next:        next,
-	}, nil
-}
+	sm4KeyHex, err := secretFromEnv("sm4Key", config.SM4Key, config.SM4KeyEnvVar, logger)
+	if err != nil {
+		return nil, err
+	}
+	sm2PrivateKeyPEM, err := secretFromEnv("sm2PrivateKeyPEM", config.SM2PrivateKeyPEM, config.SM2PrivateKeyEnvVar, logger)
+	if err != nil {
+		return nil, err
+	}
+	redisPassword, err := secretFromEnv("redisPassword", config.RedisPassword, config.RedisPasswordEnvVar, logger)
+	if err != nil {
+		return nil, err
+	}
 
-func (p *MyPlugin) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	p.redis.Set("godis", "1")
-	value, _ := p.redis.Get("godis")
+	var sm4Key []byte
+	if sm4KeyHex != "" {
+		key, err := hex.DecodeString(sm4KeyHex)
+		if err != nil || len(key) != 16 {
+			return nil, fmt.Errorf("sm4Key must be a 16-byte hex string")
+		}
+		sm4Key = key
+	}
+	if config.SM4Passphrase != "" {
+		salt, err := hex.DecodeString(config.SM4Salt)
+		if err != nil {
+			return nil, fmt.Errorf("sm4Salt must be a hex string")
+		}
+		sm4Key = passphraseSM4Key(config.SM4Passphrase, salt)
+	}
 
-	os.Stdout.WriteString("获取redis的值为: " + value + "\n")
+	var sm4IV []byte
+	if config.SM4IV != "" {
+		iv, err := hex.DecodeString(config.SM4IV)
+		if err != nil || len(iv) != 16 {
+			return nil, fmt.Errorf("sm4IV must be a 16-byte hex string")
+		}
+		sm4IV = iv
+	}
 
-	bytes, _ := io.ReadAll(req.Body)
+	var sm4CBCMACKey []byte
+	if config.SM4CBCMACKey != "" {
+		key, err := hex.DecodeString(config.SM4CBCMACKey)
+		if err != nil || len(key) != 16 {
+			return nil, fmt.Errorf("sm4CBCMACKey must be a 16-byte hex string")
+		}
+		sm4CBCMACKey = key
+	}
 
-	// 实现自己的逻辑
-	if p.smAlgorithm == "SM3" {
-		hasher := sm3.New()
-		hasher.Write(bytes)
-		hash := hasher.Sum(nil)
+	switch config.ErrorFormat {
+	case "json", "text", "xml":
+	default:
+		return nil, fmt.Errorf("unknown errorFormat: %s", config.ErrorFormat)
+	}
 
-		// 将字节切片转换为十六进制字符串表示
-		hashHex := fmt.Sprintf("%x", hash)
-		// 打印输出
+	switch config.HashOutputMode {
+	case "body", "header", "both":
+	default:
+		return nil, fmt.Errorf("unknown hashOutputMode: %s", config.HashOutputMode)
+	}
+	if !hashEncodings[config.HashEncoding] {
+		return nil, fmt.Errorf("unknown hashEncoding: %s", config.HashEncoding)
+	}
+	// 原始字节不能放进响应头
+	if config.HashEncoding == "raw" && config.HashOutputMode != "body" {
+		return nil, fmt.Errorf("hashEncoding raw requires hashOutputMode body")
+	}
+
+	multiAlgorithmSet := make(map[string]bool, len(config.MultiAlgorithms))
+	for _, algorithm := range config.MultiAlgorithms {
+		switch algorithm {
+		case "SM2", "SM3", "SM4":
+			multiAlgorithmSet[algorithm] = true
+		default:
+			return nil, fmt.Errorf("unknown multiAlgorithms value %q", algorithm)
+		}
+	}
+	if config.MultiTimeoutMs <= 0 {
+		return nil, fmt.Errorf("multiTimeoutMs must be positive")
+	}
 
-		os.Stdout.WriteString("加密后的值为: " + hashHex + "\n")
+	var sm3HMACKey []byte
+	if config.SM3HMACKey != "" {
+		key, err := hex.DecodeString(config.SM3HMACKey)
+		if err != nil || len(key) < 16 {
+			return nil, fmt.Errorf("sm3HMACKey must be a hex string of at least 16 bytes")
+		}
+		sm3HMACKey = key
+	}
 
-		m, _ := json.Marshal(map[string]interface{}{"result": hashHex, "code": 0, "message": "ok"})
+	var hmacKeys map[string][]byte
+	hmacRetiredKeyIDs := make(map[string]bool, len(config.HMACRetiredKeyIDs))
+	if config.HMACKeyRotationEnabled {
+		var err error
+		if hmacKeys, err = parseHMACKeys(config.HMACKeys); err != nil {
+			return nil, err
+		}
+		for _, id := range config.HMACRetiredKeyIDs {
+			hmacRetiredKeyIDs[id] = true
+		}
+		switch config.HMACRotationMode {
+		case "sign":
+			if _, ok := hmacKeys[config.HMACCurrentKeyID]; !ok || hmacRetiredKeyIDs[config.HMACCurrentKeyID] {
+				return nil, fmt.Errorf("hmacCurrentKeyID must name a key in hmacKeys that is not retired")
+			}
+		case "verify":
+		default:
+			return nil, fmt.Errorf("unknown hmacRotationMode %q", config.HMACRotationMode)
+		}
+	}
 
-		rw.Write(m)
-	} else {
-		// 原样输出
-		rw.Write(bytes)
+	var compressionFlag byte
+	if config.CompressBeforeEncrypt {
+		flag, ok := compressionFlags[config.CompressionAlgorithm]
+		if config.CompressionAlgorithm == "zstd" {
+			return nil, fmt.Errorf("compressionAlgorithm \"zstd\" is not supported: no zstd implementation is vendored, use \"gzip\"")
+		}
+		if !ok {
+			return nil, fmt.Errorf("unsupported compressionAlgorithm %q", config.CompressionAlgorithm)
+		}
+		compressionFlag = flag
+	}
+
+	var sm2PrivateKey *sm2.PrivateKey
+	if sm2PrivateKeyPEM != "" {
+		key, err := parseSM2PrivateKey(sm2PrivateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sm2PrivateKeyPEM: %w", err)
+		}
+		sm2PrivateKey = key
+	}
+
+	var sm2PublicKey *sm2.PublicKey
+	if config.SM2PublicKeyPEM != "" {
+		key, err := x509.ReadPublicKeyFromPem([]byte(config.SM2PublicKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sm2PublicKeyPEM: %w", err)
+		}
+		sm2PublicKey = key
+	} else if sm2PrivateKey != nil {
+		sm2PublicKey = &sm2PrivateKey.PublicKey
+	}
+
+	if config.SM2SignResponse && sm2PrivateKey == nil {
+		return nil, fmt.Errorf("sm2PrivateKeyPEM is required for sm2SignResponse")
+	}
+
+	var sm2TrustedPublicKey *sm2.PublicKey
+	if config.SM2VerifyRequest || config.SM2TrustedPublicKeyPEM != "" {
+		key, err := x509.ReadPublicKeyFromPem([]byte(config.SM2TrustedPublicKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sm2TrustedPublicKeyPEM: %w", err)
+		}
+		sm2TrustedPublicKey = key
 	}
-	// a.next.ServeHTTP(rw, req)
+
+	if config.MaxBodyBytes < 0 || config.MaxRequestBodyBytes < 0 {
+		return nil, fmt.Errorf("maxBodyBytes and maxRequestBodyBytes must not be negative")
+	}
+	if config.StreamingThresholdBytes < 0 {
+		return nil, fmt.Errorf("streamingThresholdBytes must not be negative")
+	}
+	maxBodyBytes := config.MaxRequestBodyBytes
+	if config.MaxBodyBytes > 0 {
+		maxBodyBytes = config.MaxBodyBytes
+	}
+
+	algorithms := []string{config.SMAlgorithm}
+	for prefix, algorithm := range config.MIMEAlgorithmRouting {
+		if !knownAlgorithms[algorithm] {
+			return nil, fmt.Errorf("unknown algorithm %q for content type %q", algorithm, prefix)
+		}
+		algorithms = append(algorithms, algorithm)
+	}
+	for _, algorithm := range algorithms {
+		switch {
+		case strings.HasPrefix(algorithm, "SM4") && sm4Key == nil && !config.SM4PasswordDerived:
+			return nil, fmt.Errorf("sm4Key is required for %s", algorithm)
+		case strings.HasPrefix(algorithm, "SM4-CBC") && sm4IV == nil:
+			return nil, fmt.Errorf("sm4IV is required for %s", algorithm)
+		case algorithm == "SM4-CBC-DECRYPT" && sm4CBCMACKey == nil:
+			// 没有认证的 CBC 解密会成为 padding oracle
+			return nil, fmt.Errorf("sm4CBCMACKey is required for SM4-CBC-DECRYPT")
+		case strings.HasPrefix(algorithm, "SM3-HMAC") && sm3HMACKey == nil:
+			return nil, fmt.Errorf("sm3HMACKey is required for %s", algorithm)
+		case algorithm == "SM2-ENCRYPT" && sm2PublicKey == nil:
+			return nil, fmt.Errorf("sm2PublicKeyPEM is required for SM2-ENCRYPT")
+		case (algorithm == "SM2VRF" || algorithm == "SM2SIGN" || algorithm == "SM2-DECRYPT") && sm2PrivateKey == nil:
+			return nil, fmt.Errorf("sm2PrivateKeyPEM is required for %s", algorithm)
+		case algorithm == "SM2VERIFY" && sm2TrustedPublicKey == nil:
+			return nil, fmt.Errorf("sm2TrustedPublicKeyPEM is required for SM2VERIFY")
+		}
+	}
+
+	if config.EncryptResponse {
+		if sm4Key == nil && !config.SM4PasswordDerived {
+			return nil, fmt.Errorf("sm4Key is required for encryptResponse")
+		}
+		if sm4IV == nil {
+			return nil, fmt.Errorf("sm4IV is required for encryptResponse")
+		}
+	}
+
+	var geoIP *geoIP
+	if config.GeoIPEnabled {
+		if config.GeoIPDatabasePath == "" {
+			return nil, fmt.Errorf("geoIPDatabasePath is required with geoIPEnabled")
+		}
+		if config.GeoIPCacheSize < 1 {
+			return nil, fmt.Errorf("geoIPCacheSize must be at least 1")
+		}
+		geoIP = newGeoIP(ctx, config.GeoIPDatabasePath, config.GeoIPCacheSize, logger)
+	}
+
+	var derivedKeys *derivedKeyCache
+	if config.SM4PasswordDerived {
+		n := config.SM4ScryptN
+		if n <= 1 || n&(n-1) != 0 || config.SM4ScryptR <= 0 || config.SM4ScryptP <= 0 {
+			return nil, fmt.Errorf("sm4ScryptN must be a power of two greater than 1 and sm4ScryptR, sm4ScryptP positive")
+		}
+		if config.DerivedKeyCacheSize <= 0 || config.DerivedKeyCacheTTLSeconds <= 0 {
+			return nil, fmt.Errorf("derivedKeyCacheSize and derivedKeyCacheTTLSeconds must be positive")
+		}
+		derivedKeys = newDerivedKeyCache(config.DerivedKeyCacheSize, time.Duration(config.DerivedKeyCacheTTLSeconds)*time.Second)
+	}
+
+	if !sm2SignatureFormats[config.SM2SignatureFormat] {
+		return nil, fmt.Errorf("unknown sm2SignatureFormat %q", config.SM2SignatureFormat)
+	}
+
+	if config.KeyGenPath != "" && config.KeyGenToken == "" {
+		return nil, fmt.Errorf("keyGenToken is required for keyGenPath")
+	}
+
+	if config.EventSourcingEnabled && config.EventMaxAge <= 0 {
+		return nil, fmt.Errorf("eventMaxAge must be positive")
+	}
+
+	var hkdMasterKey *sm2.PrivateKey
+	if config.HKDEnabled {
+		key, err := parseSM2PrivateKey(config.HKDMasterKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hkdMasterKey: %w", err)
+		}
+		if config.HKDDepth <= 0 {
+			return nil, fmt.Errorf("hkdDepth must be positive")
+		}
+		hkdMasterKey = key
+	}
+
+	var caCert *x509.Certificate
+	var caKey *sm2.PrivateKey
+	if config.CAMode {
+		cert, err := x509.ReadCertificateFromPem([]byte(config.CACertPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid caCertPEM: %w", err)
+		}
+		key, err := parseSM2PrivateKey(config.CAKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid caKeyPEM: %w", err)
+		}
+		if config.CACertValidityDays <= 0 {
+			return nil, fmt.Errorf("caCertValidityDays must be positive")
+		}
+		caCert, caKey = cert, key
+	}
+
+	for _, format := range config.CanonicalHashFormats {
+		if _, ok := canonicalizers[format]; !ok {
+			return nil, fmt.Errorf("unknown canonicalHashFormats value %q", format)
+		}
+	}
+
+	var votingPrivateKey *sm2.PrivateKey
+	if config.VotingMode {
+		publicKey, err := x509.ReadPublicKeyFromPem([]byte(config.VotingPublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid votingPublicKey: %w", err)
+		}
+		if config.VotingPrivateKey != "" {
+			key, err := parseSM2PrivateKey(config.VotingPrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid votingPrivateKey: %w", err)
+			}
+			if key.X.Cmp(publicKey.X) != 0 || key.Y.Cmp(publicKey.Y) != 0 {
+				return nil, fmt.Errorf("votingPrivateKey does not match votingPublicKey")
+			}
+			votingPrivateKey = key
+		}
+	}
+
+	var breaker *circuitBreaker
+	if config.AdaptiveCircuitBreaker {
+		if config.CircuitBreakerLatencyThresholdMs <= 0 {
+			return nil, fmt.Errorf("circuitBreakerLatencyThresholdMs must be positive")
+		}
+		breaker = &circuitBreaker{thresholdMs: float64(config.CircuitBreakerLatencyThresholdMs), tokens: 1}
+	}
+
+	var redisBreaker *redisBreaker
+	if config.CircuitBreakerEnabled {
+		if config.CircuitBreakerThreshold <= 0 || config.CircuitBreakerTimeoutSeconds <= 0 {
+			return nil, fmt.Errorf("circuitBreakerThreshold and circuitBreakerTimeoutSeconds must be positive")
+		}
+		redisBreaker = newRedisBreaker(config.CircuitBreakerThreshold, time.Duration(config.CircuitBreakerTimeoutSeconds)*time.Second, logger)
+	}
+
+	if config.BloomFilterEnabled {
+		// redis 字符串最大 512MB, 即 2^32 位
+		if config.BloomFilterBits <= 0 || int64(config.BloomFilterBits) > 1<<32 {
+			return nil, fmt.Errorf("bloomFilterBits must be between 1 and 2^32")
+		}
+		if config.BloomFilterHashCount <= 0 {
+			return nil, fmt.Errorf("bloomFilterHashCount must be positive")
+		}
+	}
+
+	var pkiRoots []*x509.Certificate
+	if config.PKIValidationEnabled {
+		roots, err := loadTrustedRoots(config.PKITrustedRootsDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pkiTrustedRootsDir: %w", err)
+		}
+		if config.PKIMaxChainDepth < 1 {
+			return nil, fmt.Errorf("pkiMaxChainDepth must be at least 1")
+		}
+		pkiRoots = roots
+	}
+
+	if config.PreimageSearchEnabled && config.PreimageSearchMaxIterations <= 0 {
+		return nil, fmt.Errorf("preimageSearchMaxIterations must be positive")
+	}
+
+	if config.BatchHashPath != "" && config.MaxBatchSize <= 0 {
+		return nil, fmt.Errorf("maxBatchSize must be positive")
+	}
+
+	var tokenAuthKey []byte
+	if config.TokenizationEnabled {
+		if sm4Key == nil {
+			return nil, fmt.Errorf("sm4Key is required for tokenization")
+		}
+		if !tokenFormats[config.TokenFormat] {
+			return nil, fmt.Errorf("unknown tokenFormat %q", config.TokenFormat)
+		}
+		key, err := hex.DecodeString(config.TokenAuthKey)
+		if err != nil || len(key) < 16 {
+			return nil, fmt.Errorf("tokenAuthKey must be a hex string of at least 16 bytes")
+		}
+		tokenAuthKey = key
+	}
+
+	if config.StreamSigningEnabled {
+		if sm2PrivateKey == nil {
+			return nil, fmt.Errorf("sm2PrivateKeyPEM is required for stream signing")
+		}
+		if config.StreamSigningWindowBytes <= 0 {
+			return nil, fmt.Errorf("streamSigningWindowBytes must be positive")
+		}
+	}
+
+	for feature, percentage := range config.FeatureHashSegments {
+		if percentage < 0 || percentage > 100 {
+			return nil, fmt.Errorf("percentage of feature %q must be between 0 and 100", feature)
+		}
+	}
+
+	hashRoutes, err := newHashRoutes(config.HashBasedRouting)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.HashTTLSeconds < 0 {
+		return nil, fmt.Errorf("hashTTLSeconds must not be negative")
+	}
+	if config.DuplicateAction != "reject" && config.DuplicateAction != "passthrough" {
+		return nil, fmt.Errorf("unknown duplicateAction: %s", config.DuplicateAction)
+	}
+	if config.StorageMode != "string" && config.StorageMode != "zset" {
+		return nil, fmt.Errorf("unknown storageMode: %s", config.StorageMode)
+	}
+	if config.FingerprintRetentionSeconds < 0 {
+		return nil, fmt.Errorf("fingerprintRetentionSeconds must not be negative")
+	}
+	if config.FingerprintsPath != "" && config.StorageMode != "zset" {
+		return nil, fmt.Errorf("fingerprintsPath requires storageMode zset")
+	}
+
+	// redis
+	redisOption := godis.Option{
+		Host:     config.RedisHost,
+		Port:     config.RedisPort,
+		Password: redisPassword,
+		Db:       config.RedisDb,
+	}
+	if config.RedisTLSEnabled {
+		tlsConfig, err := newRedisTLSConfig(config.RedisTLSCACert, config.RedisTLSClientCert, config.RedisTLSClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis TLS configuration: %w", err)
+		}
+		redisOption.Dial = redisTLSDialer(tlsConfig)
+	}
+	if config.RedisPoolMaxActive < 1 || config.RedisPoolMaxIdle < 0 || config.RedisPoolIdleTimeoutSeconds < 0 {
+		return nil, fmt.Errorf("invalid redis pool configuration")
+	}
+	// godis 不会启动空闲连接的回收协程, 借出时先 PING 校验, 失效的连接会被替换
+	poolConfig := godis.PoolConfig{
+		MaxTotal:             config.RedisPoolMaxActive,
+		MaxIdle:              config.RedisPoolMaxIdle,
+		MinEvictableIdleTime: time.Duration(config.RedisPoolIdleTimeoutSeconds) * time.Second,
+		TestOnBorrow:         true,
+	}
+	var redisCipher *redisCipher
+	if config.RedisEncryptionEnabled {
+		key, err := hex.DecodeString(config.RedisEncryptionKey)
+		if err != nil || len(key) != 16 {
+			return nil, fmt.Errorf("redisEncryptionKey must be a 16-byte hex string")
+		}
+		if redisCipher, err = newRedisCipher(key, config.RedisEncryptionStrictMode, logger); err != nil {
+			return nil, err
+		}
+	}
+
+	var pool redisPool
+	var cluster *clusterClient
+	if config.RedisClusterEnabled {
+		// 以下功能使用单节点连接, 不能与 cluster 一起使用
+		if len(config.RedisSentinelAddrs) > 0 || config.RedisPipelineEnabled || config.HashSharding ||
+			config.SecretSharingEnabled || config.ConsistencyCheckEnabled {
+			return nil, fmt.Errorf("redisClusterEnabled cannot be combined with redisSentinelAddrs, redisPipelineEnabled, " +
+				"hashSharding, secretSharingEnabled or consistencyCheckEnabled")
+		}
+		if config.RedisClusterMaxRedirects < 0 {
+			return nil, fmt.Errorf("redisClusterMaxRedirects must not be negative")
+		}
+		nodes, err := parseClusterNodes(config.RedisClusterNodes)
+		if err != nil {
+			return nil, err
+		}
+		cluster = newClusterClient(nodes, redisOption, poolConfig, config.RedisClusterMaxRedirects, logger)
+	} else if len(config.RedisSentinelAddrs) > 0 {
+		if config.RedisMasterName == "" {
+			return nil, fmt.Errorf("redisMasterName is required with redisSentinelAddrs")
+		}
+		sentinels, err := parseSentinelAddrs(config.RedisSentinelAddrs)
+		if err != nil {
+			return nil, err
+		}
+		pool = newSentinelPool(ctx, sentinels, config.RedisMasterName, redisOption, poolConfig, logger)
+		// 分片等独立连接只在启动时解析一次主节点
+		if host, port, err := queryMaster(sentinels, config.RedisMasterName); err == nil {
+			redisOption.Host, redisOption.Port = host, port
+		} else {
+			logger.Error("查询 redis sentinel 失败", logFields{"error": err})
+		}
+	} else {
+		pool = godis.NewPool(&poolConfig, &redisOption)
+	}
+	var newPool func(string) redisPool
+	if pool != nil && len(config.RedisSentinelAddrs) == 0 {
+		newPool = func(password string) redisPool {
+			option := redisOption
+			option.Password = password
+			return godis.NewPool(&poolConfig, &option)
+		}
+	}
+
+	var pipeline *redisPipeline
+	if config.RedisPipelineEnabled {
+		if config.PipelineFlushIntervalMs <= 0 {
+			return nil, fmt.Errorf("pipelineFlushIntervalMs must be positive")
+		}
+		pipeline = newRedisPipeline(ctx, redisOption, time.Duration(config.PipelineFlushIntervalMs)*time.Millisecond, logger)
+	}
+
+	if config.CountersPath != "" && config.CounterWorkers < 1 {
+		return nil, fmt.Errorf("counterWorkers must be at least 1")
+	}
+	if config.CounterTTLDays < 0 {
+		return nil, fmt.Errorf("counterTTLDays must not be negative")
+	}
+
+	var publisher *hashPublisher
+	if config.PubSubEnabled {
+		if config.PubSubChannel == "" {
+			return nil, fmt.Errorf("pubSubChannel must not be empty")
+		}
+		if config.PubSubQueueSize < 1 {
+			return nil, fmt.Errorf("pubSubQueueSize must be at least 1")
+		}
+		publisher = newHashPublisher(ctx, redisOption, config.PubSubChannel, config.PubSubQueueSize, logger)
+	}
+
+	var shards *shardedRedis
+	if config.HashSharding {
+		if config.ShardCount < 1 {
+			return nil, fmt.Errorf("shardCount must be at least 1")
+		}
+		shards = newShardedRedis(redisOption, poolConfig, 0, config.ShardCount, logger)
+		if config.ConsistentHashingEnabled {
+			if config.ConsistentHashVNodes < 1 {
+				return nil, fmt.Errorf("consistentHashVNodes must be at least 1")
+			}
+			shards.ring = NewHashRing(config.ShardCount, config.ConsistentHashVNodes)
+		}
+		if config.MaintainShardConnections {
+			shards.keepWarm(ctx)
+		}
+	}
+
+	var shareStores *shardedRedis
+	if config.SecretSharingEnabled {
+		if sm2PrivateKey == nil {
+			return nil, fmt.Errorf("sm2PrivateKeyPEM is required for secret sharing")
+		}
+		if config.SecretSharingThreshold < 2 || config.SecretSharingThreshold > config.SecretSharingTotal {
+			return nil, fmt.Errorf("secretSharingThreshold must be between 2 and secretSharingTotal")
+		}
+		if config.SecretSharingAdminToken == "" {
+			return nil, fmt.Errorf("secretSharingAdminToken is required for secret sharing")
+		}
+		// 私钥分片不能写进业务数据库或 hash 分片
+		firstDB, lastDB := config.SecretSharingFirstDB, config.SecretSharingFirstDB+config.SecretSharingTotal-1
+		if firstDB < 0 || (config.RedisDb >= firstDB && config.RedisDb <= lastDB) ||
+			(config.HashSharding && firstDB < config.ShardCount) {
+			return nil, fmt.Errorf("secretSharingFirstDB must not overlap redisDb or the hash shard databases")
+		}
+		shareStores = newShardedRedis(redisOption, poolConfig, firstDB, config.SecretSharingTotal, logger)
+	}
+
+	fields := map[string]bool{config.ResponseResultField: true, config.ResponseCodeField: true, config.ResponseMessageField: true}
+	if len(fields) != 3 || fields[""] {
+		return nil, fmt.Errorf("responseResultField, responseCodeField and responseMessageField must be distinct and non-empty")
+	}
+
+	var clientCARoots *x509.CertPool
+	if config.VerifyClientCert {
+		roots, err := newCertPool(config.TrustedCABundlePEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trustedCABundlePEM: %w", err)
+		}
+		clientCARoots = roots
+	}
+
+	if config.HashResponseBody && config.MaxResponseBuffer <= 0 {
+		return nil, fmt.Errorf("maxResponseBuffer must be positive")
+	}
+	if config.DecompressBeforeHash && !config.HashResponseBody {
+		return nil, fmt.Errorf("decompressBeforeHash requires hashResponseBody")
+	}
+	if config.HashURLEnabled && config.URLSignTTLSeconds <= 0 {
+		return nil, fmt.Errorf("urlSignTTLSeconds must be positive")
+	}
+	if config.ChainHistoryPath != "" && !config.HashChainingEnabled {
+		return nil, fmt.Errorf("chainHistoryPath requires hashChainingEnabled")
+	}
+	// 链头与历史由同一个脚本修改, 在集群中必须位于同一个 slot
+	if config.RedisClusterEnabled && config.ChainRedisKey != "" &&
+		clusterKeySlot(config.ChainRedisKey) != clusterKeySlot(config.ChainRedisKey+":history") {
+		return nil, fmt.Errorf("chainRedisKey needs a hash tag such as {gmsm} with redisClusterEnabled")
+	}
+
+	if (config.InjectSM3Auth || config.SM3AuthChallenge) && (config.SM3AuthScheme == "" || strings.ContainsAny(config.SM3AuthScheme, " \t")) {
+		return nil, fmt.Errorf("sm3AuthScheme must be a non-empty token without spaces")
+	}
+
+	if config.TimestampBinding {
+		if config.TimestampGranularitySeconds <= 0 {
+			return nil, fmt.Errorf("timestampGranularitySeconds must be positive")
+		}
+		if config.AcceptedWindowDrift < 0 {
+			return nil, fmt.Errorf("acceptedWindowDrift must not be negative")
+		}
+	}
+
+	var uuidNamespace []byte
+	if config.GenerateUUID {
+		if uuidNamespace, err = parseUUID(config.UUIDNamespace); err != nil {
+			return nil, fmt.Errorf("uuidNamespace must be a UUID like 6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+		}
+	}
+
+	if config.SM3AuthChallenge && (config.Realm == "" || strings.ContainsAny(config.Realm, "\"\\")) {
+		return nil, fmt.Errorf("realm must be non-empty and must not contain quotes or backslashes")
+	}
+
+	if config.ResponseCacheEnabled && config.CacheTTLSeconds < 0 {
+		return nil, fmt.Errorf("cacheTTLSeconds must not be negative")
+	}
+
+	if config.RequestIDHeader == "" {
+		return nil, fmt.Errorf("requestIDHeader must not be empty")
+	}
+
+	var allowedMethods map[string]bool
+	if len(config.AllowedMethods) > 0 {
+		allowedMethods = make(map[string]bool, len(config.AllowedMethods))
+		for _, method := range config.AllowedMethods {
+			allowedMethods[strings.ToUpper(strings.TrimSpace(method))] = true
+		}
+	}
+
+	if config.CORSEnabled {
+		if len(config.CORSAllowedOrigins) == 0 {
+			return nil, fmt.Errorf("corsAllowedOrigins must not be empty when corsEnabled is true")
+		}
+		for _, origin := range config.CORSAllowedOrigins {
+			if _, err := path.Match(origin, ""); err != nil {
+				return nil, fmt.Errorf("invalid corsAllowedOrigins entry %q", origin)
+			}
+		}
+		if config.CORSMaxAgeSeconds < 0 {
+			return nil, fmt.Errorf("corsMaxAgeSeconds must not be negative")
+		}
+	}
+
+	methods := make([]string, 0, len(allowedMethods))
+	for _, method := range config.AllowedMethods {
+		methods = append(methods, strings.ToUpper(strings.TrimSpace(method)))
+	}
+	corsAllowedMethods := strings.Join(methods, ", ")
+
+	healthAllowedNets, err := parseCIDRs("healthAllowedCIDRs", config.HealthAllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+	trustedProxyNets, err := parseCIDRs("trustedProxyCIDRs", config.TrustedProxyCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ReplayProtectionEnabled && (config.NonceTTLSeconds <= 0 || config.NonceHeader == "") {
+		return nil, fmt.Errorf("nonceTTLSeconds must be positive and nonceHeader must not be empty")
+	}
+
+	if config.SessionEnabled {
+		if config.SessionTTLSeconds <= 0 || config.SessionCookieName == "" || config.SessionIssuePath == "" {
+			return nil, fmt.Errorf("sessionTTLSeconds must be positive and sessionCookieName and sessionIssuePath must not be empty")
+		}
+		// 会话签发总要经过 nonce 检查
+		if config.NonceTTLSeconds <= 0 || config.NonceHeader == "" {
+			return nil, fmt.Errorf("sessionEnabled requires a positive nonceTTLSeconds and a nonceHeader")
+		}
+	}
+
+	if config.RateLimitEnabled && (config.RateLimitRequests <= 0 || config.RateLimitWindowSeconds <= 0) {
+		return nil, fmt.Errorf("rateLimitRequests and rateLimitWindowSeconds must be positive")
+	}
+
+	// 容量小于 1 时桶永远攒不出一个完整令牌
+	if config.TokenBucketEnabled && (config.TokenBucketCapacity < 1 || config.TokenBucketRefillRatePerSecond <= 0) {
+		return nil, fmt.Errorf("tokenBucketCapacity must be at least 1 and tokenBucketRefillRatePerSecond must be positive")
+	}
+
+	if config.ConsistencyCheckEnabled {
+		if len(config.ConsistencyCheckKeys) == 0 {
+			return nil, fmt.Errorf("consistencyCheckKeys must not be empty")
+		}
+		if config.ConsistencyCheckInterval <= 0 {
+			return nil, fmt.Errorf("consistencyCheckInterval must be positive")
+		}
+		watchConsistency(ctx, redisOption, config.RedisKeyPrefix, config.ConsistencyCheckKeys, time.Duration(config.ConsistencyCheckInterval)*time.Second, logger)
+	}
+
+	p := &MyPlugin{
+		smAlgorithm:        config.SMAlgorithm,
+		mimeRouting:        config.MIMEAlgorithmRouting,
+		newPool:            newPool,
+		cluster:            cluster,
+		redisCipher:        redisCipher,
+		pipeline:           pipeline,
+		publisher:          publisher,
+		countersPath:       config.CountersPath,
+		counterTTL:         config.CounterTTLDays * 24 * 60 * 60,
+		redisKeyPrefix:     config.RedisKeyPrefix,
+		hashTTL:            config.HashTTLSeconds,
+		duplicateAction:    config.DuplicateAction,
+		shards:             shards,
+		next:               next,
+		sm4IV:              sm4IV,
+		sm4DeterministicIV: config.SM4DeterministicIV,
+		rejectWeakIV:       config.RejectWeakIV,
+		compressBeforeSM4:  config.CompressBeforeEncrypt,
+		compressionFlag:    compressionFlag,
+		hashOutputMode:     config.HashOutputMode,
+		hashEncoding:       config.HashEncoding,
+		multiAlgorithms:    multiAlgorithmSet,
+		multiFailOnMissing: config.MultiFailOnMissing,
+		multiTimeout:       time.Duration(config.MultiTimeoutMs) * time.Millisecond,
+
+		hmacKeyRotation: config.HMACKeyRotationEnabled,
+		hmacVerify:      config.HMACRotationMode == "verify",
+
+		sm2PrivateKey:      sm2PrivateKey,
+		sm2PublicKey:       sm2PublicKey,
+		envelopeEncryption: config.EnvelopeEncryptionEnabled,
+		sm2SignatureFormat: config.SM2SignatureFormat,
+		sm2SignResponse:    config.SM2SignResponse,
+		maxBodyBytes:       maxBodyBytes,
+
+		streamingThreshold: config.StreamingThresholdBytes,
+
+		storageMode:          config.StorageMode,
+		fingerprintRetention: config.FingerprintRetentionSeconds,
+		fingerprintsPath:     config.FingerprintsPath,
+
+		sm2VerifyRequest:    config.SM2VerifyRequest,
+		sm2TrustedPublicKey: sm2TrustedPublicKey,
+
+		sm4PasswordDerived: config.SM4PasswordDerived,
+		sm4PasswordHeader:  config.SM4PasswordHeader,
+		sm4PasswordSalt:    config.SM4PasswordSalt,
+		sm4ScryptN:         config.SM4ScryptN,
+		sm4ScryptR:         config.SM4ScryptR,
+		sm4ScryptP:         config.SM4ScryptP,
+		derivedKeys:        derivedKeys,
+
+		deploymentValidation: config.DeploymentValidationEnabled,
+		blueHashSetKey:       config.BlueHashSetKey,
+		greenHashSetKey:      config.GreenHashSetKey,
+		activeDeploymentKey:  config.ActiveDeployment,
+
+		fingerprintDatabase: config.FingerprintDatabaseMode,
+		fingerprintSetKey:   config.FingerprintDatabaseSetKey,
+
+		eventSourcing:  config.EventSourcingEnabled,
+		eventStreamKey: config.EventStreamKey,
+		eventMaxAge:    config.EventMaxAge,
+
+		hkdMasterKey: hkdMasterKey,
+		hkdDepth:     config.HKDDepth,
+
+		canaryEnabled:      config.CanaryEnabled,
+		canarySetKey:       config.CanarySetKey,
+		canaryBlockOnMatch: config.CanaryBlockOnMatch,
+
+		tokenBinding:       config.TokenBindingEnabled,
+		tokenBindingHeader: config.TokenBindingHeader,
+
+		keyGenPath:        config.KeyGenPath,
+		keyGenToken:       config.KeyGenToken,
+		attestationMode:   config.AttestationMode,
+		attestationLogKey: config.AttestationLogKey,
+
+		caCert:         caCert,
+		caKey:          caKey,
+		caValidityDays: config.CACertValidityDays,
+
+		hashRoutes: hashRoutes,
+
+		canonicalHashVerification: config.CanonicalHashVerification,
+		canonicalHashFormats:      config.CanonicalHashFormats,
+
+		votingMode:       config.VotingMode,
+		votingPrivateKey: votingPrivateKey,
+		votingTallyKey:   config.VotingTallyKey,
+		votingAdminToken: config.VotingAdminToken,
+
+		circuitBreaker: breaker,
+
+		redisBreaker:       redisBreaker,
+		breakerFallthrough: config.CircuitBreakerFallthrough,
+
+		bloomFilter:          config.BloomFilterEnabled,
+		bloomFilterKey:       config.BloomFilterKey,
+		bloomFilterBits:      int64(config.BloomFilterBits),
+		bloomFilterHashCount: config.BloomFilterHashCount,
+
+		pkiRoots:         pkiRoots,
+		pkiMaxChainDepth: config.PKIMaxChainDepth,
+		pkiCacheTTL:      time.Duration(config.PKICacheTTLSeconds) * time.Second,
+
+		piiMasking: config.PIIMaskingEnabled,
+		piiFields:  config.PIIFields,
+
+		preimageSearch:        config.PreimageSearchEnabled,
+		preimageMaxIterations: config.PreimageSearchMaxIterations,
+
+		batchHashPath:     config.BatchHashPath,
+		maxBatchSize:      config.MaxBatchSize,
+		batchStoreInRedis: config.BatchStoreInRedis,
+
+		verifyPath:      config.VerifyPath,
+		verifyFromRedis: config.VerifyFromRedis,
+
+		acceptCertAsPublicKey: config.AcceptCertAsPublicKey,
+
+		merklePath: config.MerklePath,
+
+		namespaceFromHeader: config.NamespaceFromHeader,
+		requireNamespace:    config.RequireNamespace,
+
+		geoIP: geoIP,
+
+		tokenization:  config.TokenizationEnabled,
+		tokenVaultKey: config.TokenVaultKey,
+		tokenFormat:   config.TokenFormat,
+		tokenAuthKey:  tokenAuthKey,
+
+		streamSigning: config.StreamSigningEnabled,
+		streamWindow:  config.StreamSigningWindowBytes,
+
+		honeyToken:            config.HoneyTokenEnabled,
+		honeyTokenSetKey:      config.HoneyTokenSetKey,
+		honeyTokenTemplateKey: config.HoneyTokenTemplateKey,
+		honeyTokenDelay:       time.Duration(config.HoneyTokenDelayMs) * time.Millisecond,
+
+		featureHash:     config.FeatureHashEnabled,
+		featureSegments: config.FeatureHashSegments,
+		featureCookie:   config.FeatureClientCookie,
+
+		sharingThreshold:  config.SecretSharingThreshold,
+		sharingAdminToken: config.SecretSharingAdminToken,
+		shareStores:       shareStores,
+
+		rateLimit:       config.RateLimitEnabled,
+		rateLimitMax:    config.RateLimitRequests,
+		rateLimitWindow: config.RateLimitWindowSeconds,
+
+		tokenBucket:           config.TokenBucketEnabled,
+		tokenBucketCapacity:   config.TokenBucketCapacity,
+		tokenBucketRefillRate: config.TokenBucketRefillRatePerSecond,
+
+		trustedProxyNets: trustedProxyNets,
+
+		hashResponseBody:     config.HashResponseBody,
+		maxResponseBuffer:    config.MaxResponseBuffer,
+		decompressBeforeHash: config.DecompressBeforeHash,
+
+		hashResponseHeaders: headerHashNames(config.HashResponseHeaders),
+		hashRequestHeaders:  headerHashNames(config.HashRequestHeaders),
+
+		hashURL:    config.HashURLEnabled,
+		urlSignTTL: config.URLSignTTLSeconds,
+
+		hashChaining:     config.HashChainingEnabled,
+		chainRedisKey:    config.ChainRedisKey,
+		chainHistoryPath: config.ChainHistoryPath,
+
+		mutex: config.MutexEnabled,
+
+		clientCARoots: clientCARoots,
+
+		responseResultField:  config.ResponseResultField,
+		responseCodeField:    config.ResponseCodeField,
+		responseMessageField: config.ResponseMessageField,
+		responseCodeSuccess:  config.ResponseCodeSuccess,
+		extraResponseFields:  config.ExtraResponseFields,
+
+		forwardToNext: config.ForwardToNext,
+
+		multipartPerField: config.MultipartPerField,
+
+		jsonHashFields:       config.JSONHashFields,
+		requireAllJSONFields: config.RequireAllJSONFields,
+
+		replayProtection: config.ReplayProtectionEnabled,
+		nonceTTL:         config.NonceTTLSeconds,
+		nonceHeader:      config.NonceHeader,
+
+		session:           config.SessionEnabled,
+		sessionTTL:        config.SessionTTLSeconds,
+		sessionCookieName: config.SessionCookieName,
+		sessionIssuePath:  config.SessionIssuePath,
+
+		healthPath:        config.HealthPath,
+		healthAllowedNets: healthAllowedNets,
+
+		allowedMethods:      allowedMethods,
+		allowedPathPrefixes: config.AllowedPathPrefixes,
+
+		corsEnabled:        config.CORSEnabled,
+		corsAllowedOrigins: config.CORSAllowedOrigins,
+		corsAllowedMethods: corsAllowedMethods,
+		corsAllowedHeaders: strings.Join(config.CORSAllowedHeaders, ", "),
+		corsMaxAge:         config.CORSMaxAgeSeconds,
+
+		requestIDHeader: config.RequestIDHeader,
+		logRequestID:    config.LogRequestID,
+
+		injectSM3Auth: config.InjectSM3Auth,
+		sm3AuthScheme: config.SM3AuthScheme,
+		hashEmptyBody: config.HashEmptyBody,
+
+		sm3AuthChallenge: config.SM3AuthChallenge,
+		realm:            config.Realm,
+
+		timestampBinding:     config.TimestampBinding,
+		timestampGranularity: config.TimestampGranularitySeconds,
+		acceptedWindowDrift:  config.AcceptedWindowDrift,
+
+		uuidNamespace: uuidNamespace,
+
+		responseCache:    config.ResponseCacheEnabled,
+		cacheTTL:         config.CacheTTLSeconds,
+		cacheVaryHeaders: config.CacheVaryHeaders,
+
+		errorFormat: config.ErrorFormat,
+		logger:      logger,
+		tracing:     config.TracingEnabled,
+
+		encryptResponse: config.EncryptResponse,
+	}
+	p.keys.Store(&runtimeKeys{
+		sm4Key:            sm4Key,
+		sm4CBCMACKey:      sm4CBCMACKey,
+		sm3HMACKey:        sm3HMACKey,
+		hmacKeys:          hmacKeys,
+		hmacCurrentKeyID:  config.HMACCurrentKeyID,
+		hmacRetiredKeyIDs: hmacRetiredKeyIDs,
+	})
+	if pool != nil {
+		p.pool.Store(newTrackedPool(pool))
+	}
+
+	if p.attestationMode {
+		raw, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+		p.configHash = sm3Sum(raw)
+	}
+
+	if p.shareStores != nil {
+		if err := p.storeSecretShares(); err != nil {
+			p.logger.Error("保存私钥分片失败", logFields{"error": err})
+		}
+	}
+
+	if config.DynamicConfigPath != "" {
+		if config.DynamicConfigReloadIntervalSeconds <= 0 {
+			return nil, fmt.Errorf("dynamicConfigReloadIntervalSeconds must be positive")
+		}
+		// 记录实际生效的密钥(可能来自环境变量), 重新加载时与文件比较
+		running := *config
+		running.SM4Key, running.SM2PrivateKeyPEM, running.RedisPassword = sm4KeyHex, sm2PrivateKeyPEM, redisPassword
+		copied, err := copyConfig(&running)
+		if err != nil {
+			return nil, err
+		}
+		p.config = *copied
+		go p.watchDynamicConfig(ctx, config.DynamicConfigPath, time.Duration(config.DynamicConfigReloadIntervalSeconds)*time.Second)
+	}
+
+	if config.CountersPath != "" {
+		// 无缓冲: 只有空闲的 worker 能接收, 都在忙时 countAlgorithm 丢弃计数
+		p.counterJobs = make(chan string)
+		for i := 0; i < config.CounterWorkers; i++ {
+			go p.runCounterWorker(ctx)
+		}
+	}
+
+	if p.canaryEnabled {
+		conn, err := p.getConn()
+		if err == nil {
+			err = p.loadCanaries(conn, config.CanaryHashes)
+			conn.Close()
+		}
+		if err != nil {
+			p.logger.Error("加载 canary hash 失败", logFields{"error": err})
+		}
+	}
+
+	return p, nil
+}
+
+// getConn borrows a connection from the pool, or returns the cluster client in cluster mode.
+// The caller must Close it. With the redis circuit breaker, failures to borrow a connection are
+// counted and errRedisBreakerOpen is returned without contacting redis while it is open.
+func (p *MyPlugin) getConn() (redisConn, error) {
+	if p.redisBreaker != nil && !p.redisBreaker.allow() {
+		return nil, errRedisBreakerOpen
+	}
+
+	var conn redisConn
+	if p.cluster != nil {
+		conn = p.cluster
+	} else {
+		r, err := p.borrowConn()
+		if err != nil {
+			if p.redisBreaker != nil {
+				p.redisBreaker.failure()
+			}
+			return nil, err
+		}
+		conn = r
+	}
+	if p.redisBreaker != nil {
+		p.redisBreaker.success()
+	}
+	if p.redisCipher != nil {
+		return &encryptedConn{redisConn: conn, cipher: p.redisCipher}, nil
+	}
+	return conn, nil
+}
+
+// Close destroys the redis pool, once its borrowed connections are returned, and closes the shard
+// connections.
+func (p *MyPlugin) Close() error {
+	if p.cluster != nil {
+		p.cluster.Destroy()
+	} else {
+		p.pool.Load().retire()
+	}
+	if p.shards != nil {
+		p.shards.close()
+	}
+	if p.shareStores != nil {
+		p.shareStores.close()
+	}
+	return nil
+}
+
+func (p *MyPlugin) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	requestID := p.assignRequestID(rw, req)
+
+	if p.healthPath != "" && req.URL.Path == p.healthPath {
+		p.serveHealth(rw, req)
+		return
+	}
+
+	if p.corsEnabled && p.handleCORS(rw, req) {
+		return
+	}
+
+	// 不处理的请求不读取请求体, 也不访问 redis
+	if !p.activeFor(req) {
+		p.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if p.tracing {
+		req = p.withTrace(req)
+	}
+
+	if p.namespaceFromHeader != "" {
+		var ok bool
+		if req, ok = p.withNamespace(rw, req); !ok {
+			return
+		}
+	}
+
+	// 从连接池借出连接, Close 时归还; 出错的连接由 godis 标记为 broken 并丢弃
+	conn, err := p.getConn()
+	if err == errRedisBreakerOpen && p.breakerFallthrough {
+		// 熔断期间跳过 redis, 依赖 redis 的功能按 redis 出错处理
+		conn, err = unavailableConn{}, nil
+	}
+	if err != nil {
+		p.logger.Error("获取 redis 连接失败", logFields{"error": err})
+		p.writeError(rw, http.StatusServiceUnavailable, "redis unavailable")
+		return
+	}
+	defer conn.Close()
+
+	if p.rateLimit && !p.checkRateLimit(conn, rw, req) {
+		return
+	}
+
+	if p.tokenBucket && !p.checkTokenBucket(conn, rw, req) {
+		return
+	}
+
+	if p.replayProtection && !p.checkNonce(conn, rw, req) {
+		return
+	}
+
+	if p.session {
+		if req.Method == http.MethodPost && req.URL.Path == p.sessionIssuePath {
+			// 未开启全局重放保护时, 签发请求单独检查 nonce
+			if !p.replayProtection && !p.checkNonce(conn, rw, req) {
+				return
+			}
+			p.serveSessionIssue(conn, rw, req)
+			return
+		}
+		if !p.checkSession(conn, rw, req) {
+			return
+		}
+	}
+
+	if p.tokenBinding && !p.checkTokenBinding(conn, rw, req) {
+		return
+	}
+
+	if p.pkiRoots != nil && !p.checkCertChain(conn, rw, req) {
+		return
+	}
+
+	if p.clientCARoots != nil && !p.checkClientCert(rw, req) {
+		return
+	}
+
+	if p.circuitBreaker != nil {
+		if !p.admitRequest(conn) {
+			p.writeError(rw, http.StatusServiceUnavailable, "service overloaded")
+			return
+		}
+		defer p.recordLatency(conn, time.Now())
+	}
+
+	if p.featureHash {
+		p.applyFeatureFlags(conn, req)
+	}
+
+	if p.eventSourcing && req.Method == http.MethodGet && req.URL.Path == eventsPath {
+		p.serveEvents(conn, rw, req)
+		return
+	}
+
+	if p.fingerprintsPath != "" && req.Method == http.MethodGet && req.URL.Path == p.fingerprintsPath {
+		p.serveFingerprints(conn, rw, req)
+		return
+	}
+
+	if p.merklePath != "" && req.Method == http.MethodGet && req.URL.Path == p.merklePath+merkleProofSuffix {
+		p.serveMerkleProof(conn, rw, req)
+		return
+	}
+
+	if p.chainHistoryPath != "" && req.Method == http.MethodGet && req.URL.Path == p.chainHistoryPath {
+		p.serveChainHistory(conn, rw, req)
+		return
+	}
+
+	if p.countersPath != "" && req.Method == http.MethodGet && req.URL.Path == p.countersPath {
+		p.serveCounters(conn, rw, req)
+		return
+	}
+
+	if p.keyGenPath != "" && req.Method == http.MethodPost && req.URL.Path == p.keyGenPath {
+		p.serveKeyGen(conn, rw, req)
+		return
+	}
+
+	if p.hkdMasterKey != nil && req.Header.Get(hkdPathHeader) != "" {
+		p.serveHKD(conn, rw, req)
+		return
+	}
+
+	// 未携带 Authorization 时直接质询, 不读取请求体
+	if p.sm3AuthChallenge && req.Header.Get("Authorization") == "" && !(p.hashURL && isPresigned(req)) {
+		p.challengeSM3Auth(rw, "authorization required")
+		return
+	}
+
+	if p.maxBodyBytes > 0 {
+		req.Body = http.MaxBytesReader(rw, req.Body, p.maxBodyBytes)
+	}
+
+	// 逐字段流式计算, 不整体读取请求体
+	if p.multipartPerField && !p.sm3AuthChallenge && isMultipartForm(req) && p.algorithmFor(req) == "SM3" {
+		p.serveMultipartHashes(rw, req)
+		return
+	}
+
+	// 大请求体边转发边计算, 不整体读入内存; 流式签名和 JSON 字段 hash 需要完整请求体
+	if p.streamable(req) {
+		p.serveStreamingHash(conn, rw, req, requestID)
+		return
+	}
+
+	var bytes []byte
+	if p.streamSigning && req.Method == http.MethodPost {
+		body, signatures, digest, err := p.readSigned(req.Body)
+		if isBodyTooLarge(err) {
+			p.rejectBodyTooLarge(rw, req)
+			return
+		}
+		if err != nil {
+			p.writeError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+		bytes = body
+		declareStreamTrailers(rw, signatures)
+		defer setStreamTrailers(rw, signatures, digest)
+	} else {
+		var err error
+		if bytes, err = io.ReadAll(req.Body); isBodyTooLarge(err) {
+			p.rejectBodyTooLarge(rw, req)
+			return
+		}
+		if err != nil {
+			p.writeError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// 预签名 URL 本身就是授权, 不再做其他校验
+	if p.hashURL && isPresigned(req) {
+		p.servePresigned(conn, rw, req, bytes)
+		return
+	}
+
+	if p.sm2VerifyRequest && !p.verifyRequestSignature(rw, req, bytes) {
+		return
+	}
+
+	if p.hmacKeyRotation && p.hmacVerify && !p.verifyRequestHMAC(rw, req, bytes) {
+		return
+	}
+
+	if p.piiMasking {
+		p.logMaskedBody(bytes)
+	}
+
+	if p.sm3AuthChallenge && !p.verifySM3Authorization(rw, req, bytes) {
+		return
+	}
+
+	if p.injectSM3Auth {
+		p.setSM3Authorization(req, bytes)
+	}
+
+	if p.hmacKeyRotation && !p.hmacVerify {
+		p.signRequestHMAC(req, bytes)
+	}
+
+	if len(p.hashRequestHeaders) > 0 {
+		req.Header.Set(sm3ReqHeaderHashHeader, headerHash(req.Header, p.hashRequestHeaders))
+	}
+
+	if p.hashURL {
+		p.recordURLHash(conn, rw, req, bytes)
+	}
+
+	if p.honeyToken {
+		if hashHex, found := p.findHoneyToken(conn, bytes); found {
+			p.serveHoneyToken(conn, rw, req, bytes, hashHex)
+			return
+		}
+	}
+
+	if p.caCert != nil {
+		if req.Method == http.MethodPost && req.URL.Path == caIssuePath {
+			p.serveCAIssue(conn, rw, bytes)
+			return
+		}
+		if req.Method == http.MethodGet && req.URL.Path == caCRLPath {
+			p.serveCACRL(conn, rw)
+			return
+		}
+	}
+
+	if p.preimageSearch && req.Method == http.MethodPost && req.URL.Path == preimagePath {
+		p.servePreimage(rw, bytes)
+		return
+	}
+
+	if p.batchHashPath != "" && req.Method == http.MethodPost && req.URL.Path == p.batchHashPath {
+		p.serveBatchHash(conn, rw, req, bytes)
+		return
+	}
+
+	if p.verifyPath != "" && req.Method == http.MethodPost && req.URL.Path == p.verifyPath {
+		p.serveVerify(conn, rw, req, bytes)
+		return
+	}
+
+	if p.merklePath != "" && req.Method == http.MethodPost && req.URL.Path == p.merklePath {
+		p.serveMerkleTree(conn, rw, req, bytes)
+		return
+	}
+
+	if p.tokenization && req.Method == http.MethodPost {
+		switch req.URL.Path {
+		case tokenizePath:
+			p.serveTokenize(conn, rw, bytes)
+			return
+		case detokenizePath:
+			p.serveDetokenize(conn, rw, bytes)
+			return
+		}
+	}
+
+	if p.shareStores != nil && req.Method == http.MethodPost && req.URL.Path == recoverKeyPath {
+		p.serveRecoverKey(rw, req, bytes)
+		return
+	}
+
+	if p.votingMode && req.Method == http.MethodPost {
+		switch req.URL.Path {
+		case votePath:
+			p.serveVote(conn, rw, bytes)
+			return
+		case tallyPath:
+			p.serveTally(conn, rw, req)
+			return
+		}
+	}
+
+	if len(p.hashResponseHeaders) > 0 {
+		headerHashing := &headerHashWriter{rw: rw, names: p.hashResponseHeaders}
+		rw = headerHashing
+		defer headerHashing.finish()
+	}
+
+	if p.responseCache && len(bytes) > 0 {
+		key := p.cacheKey(req, bytes)
+		if p.serveCachedResponse(conn, rw, key) {
+			return
+		}
+		capture := newResponseCapture(rw)
+		rw = capture
+		defer p.storeCachedResponse(conn, key, capture)
+	}
+
+	if p.eventSourcing {
+		recorder := &statusRecorder{ResponseWriter: rw}
+		rw = recorder
+		defer func() { p.appendEvent(conn, req, bytes, recorder.statusCode()) }()
+	}
+
+	if p.hashResponseBody {
+		hashing := newHashingWriter(rw, p.maxResponseBuffer)
+		rw = hashing
+		defer p.finishHashing(hashing)
+	}
+
+	if p.encryptResponse {
+		key, err := p.sm4KeyFor(req)
+		if err != nil {
+			p.writeError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+		capture := newResponseCapture(rw)
+		rw = capture
+		defer p.sendEncrypted(capture, key)
+	}
+
+	if p.sm2SignResponse {
+		capture := newResponseCapture(rw)
+		rw = capture
+		defer p.signResponse(capture)
+	}
+
+	if p.canaryEnabled {
+		capture := newResponseCapture(rw)
+		rw = capture
+		defer p.inspectCanary(conn, capture, req)
+	}
+
+	if p.canonicalHashVerification && req.Header.Get(sm3HashHeader) != "" && !p.verifyBodyHash(rw, req, bytes) {
+		return
+	}
+
+	if len(p.hashRoutes) > 0 {
+		p.routeByHash(rw, req, bytes)
+		return
+	}
+
+	if p.deploymentValidation {
+		p.checkDeployment(conn, rw, bytes)
+	}
+
+	if p.bloomFilter {
+		p.checkBloomFilter(conn, rw, bytes)
+	}
+
+	if p.fingerprintDatabase {
+		if req.Method == http.MethodPost && req.URL.Path == fingerprintSearchPath {
+			p.searchFingerprints(conn, rw, bytes)
+		} else {
+			p.storeFingerprints(conn, rw, bytes)
+		}
+		return
+	}
+
+	if p.mutex && len(bytes) > 0 {
+		lockKey := p.bodyLockKey(req, sm3Sum(bytes))
+		token, locked, err := p.waitLock(conn, lockKey, mutexTTLMs)
+		if err != nil {
+			p.writeError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !locked {
+			p.writeError(rw, http.StatusServiceUnavailable, "lock timeout")
+			return
+		}
+		defer func() {
+			if err := p.releaseLock(conn, lockKey, token); err != nil {
+				p.logger.Error("释放锁失败", logFields{"error": err})
+			}
+		}()
+	}
+
+	if len(bytes) > 0 && p.isDuplicate(conn, req, bytes) {
+		if p.duplicateAction == "reject" {
+			p.writeError(rw, http.StatusConflict, "duplicate request")
+			return
+		}
+		rw.Header().Set(duplicateHeader, "true")
+	}
+
+	algorithm := p.algorithmFor(req)
+	if p.counterJobs != nil {
+		recorder := &statusRecorder{ResponseWriter: rw}
+		rw = recorder
+		defer func() {
+			if recorder.statusCode() < http.StatusBadRequest {
+				p.countAlgorithm(req, algorithm)
+			}
+		}()
+	}
+
+	// 实现自己的逻辑
+	switch algorithm {
+	case "SM3":
+		input := bytes
+		if len(p.jsonHashFields) > 0 && isJSONRequest(req) {
+			var err error
+			if input, err = p.jsonFieldsInput(bytes); err != nil {
+				p.writeError(rw, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		var window int64
+		if p.timestampBinding {
+			var err error
+			if window, err = p.timestampWindow(req); err != nil {
+				p.writeError(rw, http.StatusBadRequest, err.Error())
+				return
+			}
+			input = timeBoundInput(input, window)
+			rw.Header().Set(timestampWindowHeader, strconv.FormatInt(window, 10))
+		}
+
+		var hash, previousHash []byte
+		if p.hashChaining {
+			var err error
+			if hash, previousHash, err = p.extendChain(conn, req, requestID, input); err != nil {
+				p.logger.Error("更新 hash 链失败", logFields{"error": err})
+				p.writeError(rw, http.StatusServiceUnavailable, "hash chain unavailable")
+				return
+			}
+		} else {
+			hasher := sm3.New()
+			hasher.Write(input)
+			hash = hasher.Sum(nil)
+		}
+
+		// 将字节切片转换为十六进制字符串表示
+		hashHex := fmt.Sprintf("%x", hash)
+		// 打印输出
+
+		p.logger.Info("加密后的值", hashLogFields(req, hashHex))
+
+		if p.shards != nil {
+			if _, err := p.shards.Set(hashHex, "1"); err != nil {
+				p.logger.Error("写入 redis 分片失败", logFields{"error": err})
+			}
+		}
+		p.recordRequestHash(conn, req, requestID, algorithm, hashHex)
+		if p.publisher != nil {
+			p.publisher.publish(algorithm, hashHex, requestID)
+		}
+
+		if p.hashEncoding == "raw" {
+			rw.Header().Set("Content-Type", "application/octet-stream")
+			rw.Write(hash)
+			return
+		}
+		encoded := formatHash(hash, p.hashEncoding)
+
+		if p.hashOutputMode != "body" {
+			rw.Header().Set(sm3HashHeader, encoded)
+		}
+		if p.hashOutputMode == "header" || p.forwardToNext {
+			// 作为透明的审计层, 原请求体照常交给下游
+			req.Header.Set(sm3HashHeader, encoded)
+			restoreBody(req, bytes)
+			p.next.ServeHTTP(rw, req)
+			return
+		}
+
+		response := p.sm3Response(encoded)
+		if p.timestampBinding {
+			response["ts"] = window
+		}
+		if p.uuidNamespace != nil {
+			response["uuid"] = SM3UUID(p.uuidNamespace, bytes)
+		}
+		if p.hashChaining {
+			response["previousHash"] = formatHash(previousHash, p.hashEncoding)
+		}
+		m, _ := json.Marshal(response)
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write(m)
+	case "SM3-HMAC":
+		p.serveSM3HMAC(rw, bytes)
+	case "SM3-HMAC-VERIFY":
+		p.verifySM3HMAC(rw, req, bytes)
+	case "SM4":
+		p.serveSM4(conn, rw, req, bytes)
+	case "SM4CCM":
+		p.serveSM4CCM(rw, req, bytes)
+	case "SM2VRF":
+		p.serveVRF(rw, bytes)
+	case "SM2SIGN":
+		p.serveSM2Sign(rw, bytes)
+	case "SM2VERIFY":
+		if p.verifyRequestSignature(rw, req, bytes) {
+			writeJSON(rw, http.StatusOK, map[string]interface{}{"code": 0, "message": "ok"})
+		}
+	case "SM4-ECB", "SM4-CBC", "SM4-CBC-DECRYPT":
+		p.forwardSM4(rw, req, bytes, algorithm)
+	case "SM4-OFB":
+		p.serveSM4OFB(conn, rw, req, bytes)
+	case "SM2-ENCRYPT":
+		if p.envelopeEncryption {
+			p.serveEnvelopeEncrypt(rw, bytes)
+		} else {
+			p.serveSM2Encrypt(rw, bytes)
+		}
+	case "SM2-DECRYPT":
+		if p.envelopeEncryption {
+			p.forwardEnvelopeDecrypt(rw, req, bytes)
+		} else {
+			p.forwardSM2Decrypt(rw, req, bytes)
+		}
+	case "MULTI":
+		p.serveMulti(rw, req, bytes)
+	default:
+		if p.forwardToNext {
+			// 请求体已被读取, 还原后下游才能读到
+			restoreBody(req, bytes)
+			p.next.ServeHTTP(rw, req)
+			return
+		}
+		// 原样输出
+		rw.Write(bytes)
+	}
+}
+
+// sm3Response builds the SM3 result object with the configured field names and extra fields.
+func (p *MyPlugin) sm3Response(result interface{}) map[string]interface{} {
+	response := make(map[string]interface{}, len(p.extraResponseFields)+3)
+	for key, value := range p.extraResponseFields {
+		response[key] = value
+	}
+	response[p.responseResultField] = result
+	response[p.responseCodeField] = p.responseCodeSuccess
+	response[p.responseMessageField] = "ok"
+	return response
+}
+
+// algorithmFor selects the algorithm for the request by the longest matching Content-Type prefix
+// in the MIME routing map, falling back to the configured SMAlgorithm.
+func (p *MyPlugin) algorithmFor(req *http.Request) string {
+	contentType := strings.ToLower(req.Header.Get("Content-Type"))
+
+	algorithm, matched := p.smAlgorithm, ""
+	for prefix, alg := range p.mimeRouting {
+		if strings.HasPrefix(contentType, strings.ToLower(prefix)) && len(prefix) > len(matched) {
+			algorithm, matched = alg, prefix
+		}
+	}
+	return algorithm
+}
+
+// serveSM4 encrypts the body with SM4-CBC and writes the base64 ciphertext together with the IV.
+func (p *MyPlugin) serveSM4(conn redisConn, rw http.ResponseWriter, req *http.Request, body []byte) {
+	key, err := p.sm4KeyFor(req)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+	result := map[string]interface{}{"code": 0, "message": "ok"}
+
+	var iv []byte
+	if p.sm4DeterministicIV {
+		// 序列号递增, 保证相同请求的 IV 也不会重复
+		seq, err := conn.Incr(p.keyPrefix(req) + ":sm4:seq")
+		if err != nil {
+			p.writeError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		iv = deriveIV(key, req.Method, req.URL.Path, clientID(req), uint64(seq))
+		result["seq"] = seq
+	} else {
+		if iv, err = randomIV(); err != nil {
+			p.writeError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	plaintext := body
+	if p.compressBeforeSM4 {
+		compressed, err := compress(p.compressionFlag, body)
+		if err != nil {
+			p.writeError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		plaintext = compressed
+	}
+
+	ciphertext, err := sm4CBCEncrypt(key, iv, plaintext)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if p.compressBeforeSM4 {
+		// 压缩标志放在密文前, 解密时据此选择解压算法
+		ciphertext = append([]byte{p.compressionFlag}, ciphertext...)
+	}
+	if macKey := p.keys.Load().sm4CBCMACKey; macKey != nil {
+		ciphertext = appendCBCTag(macKey, iv, ciphertext)
+	}
+
+	result["result"] = base64.StdEncoding.EncodeToString(ciphertext)
+	result["iv"] = hex.EncodeToString(iv)
+	writeJSON(rw, http.StatusOK, result)
+}
+
+// forwardSM4 encrypts the body with SM4-ECB or SM4-CBC (fixed SM4IV), or decrypts it for
+// SM4-CBC-DECRYPT, and passes the result to the next handler. Ciphertext travels base64 encoded;
+// SM4-CBC ciphertext carries the HMAC-SM3 tag when sm4CBCMACKey is set.
+func (p *MyPlugin) forwardSM4(rw http.ResponseWriter, req *http.Request, body []byte, algorithm string) {
+	key, err := p.sm4KeyFor(req)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var out []byte
+	if algorithm == "SM4-CBC-DECRYPT" {
+		ciphertext, err := base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			p.writeError(rw, http.StatusBadRequest, "body must be base64 encoded ciphertext")
+			return
+		}
+		// 标签、填充、密钥错误都只返回 "decryption failed"
+		if ciphertext, err = checkCBCTag(p.keys.Load().sm4CBCMACKey, p.sm4IV, ciphertext); err != nil {
+			p.writeError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+		if p.compressBeforeSM4 {
+			if len(ciphertext) == 0 {
+				p.writeError(rw, http.StatusBadRequest, errDecryptionFailed.Error())
+				return
+			}
+			// 第一个字节是压缩标志
+			flag := ciphertext[0]
+			if ciphertext, err = sm4CBCDecrypt(key, p.sm4IV, ciphertext[1:]); err == nil {
+				ciphertext, err = decompress(flag, ciphertext)
+			}
+			out = ciphertext
+		} else {
+			out, err = sm4CBCDecrypt(key, p.sm4IV, ciphertext)
+		}
+		if err != nil {
+			p.writeError(rw, http.StatusBadRequest, errDecryptionFailed.Error())
+			return
+		}
+	} else {
+		plaintext := body
+		if p.compressBeforeSM4 {
+			if plaintext, err = compress(p.compressionFlag, body); err != nil {
+				p.writeError(rw, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+
+		var ciphertext []byte
+		if algorithm == "SM4-ECB" {
+			ciphertext, err = sm4ECBEncrypt(key, plaintext)
+		} else {
+			ciphertext, err = sm4CBCEncrypt(key, p.sm4IV, plaintext)
+		}
+		if err != nil {
+			p.writeError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if p.compressBeforeSM4 {
+			ciphertext = append([]byte{p.compressionFlag}, ciphertext...)
+		}
+		if macKey := p.keys.Load().sm4CBCMACKey; algorithm == "SM4-CBC" && macKey != nil {
+			ciphertext = appendCBCTag(macKey, p.sm4IV, ciphertext)
+		}
+		out = []byte(base64.StdEncoding.EncodeToString(ciphertext))
+	}
+
+	restoreBody(req, out)
+	req.Header.Set("Content-Length", strconv.Itoa(len(out)))
+	p.next.ServeHTTP(rw, req)
+}
+
+// sm3Sum returns the SM3 digest of data.
+func sm3Sum(data []byte) []byte {
+	hasher := sm3.New()
+	hasher.Write(data)
+	return hasher.Sum(nil)
+}
+
+// clientID identifies the caller by the X-Client-ID header, falling back to the remote IP.
+func clientID(req *http.Request) string {
+	if id := req.Header.Get("X-Client-ID"); id != "" {
+		return id
+	}
+	return clientIP(req)
+}
+
+// clientIP returns the host part of the request's remote address.
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// xmlError is the body of an error response in "xml" ErrorFormat.
+type xmlError struct {
+	XMLName xml.Name `xml:"error"`
+	Code    int      `xml:"code"`
+	Message string   `xml:"message"`
+}
+
+// writeError writes an error response in the configured ErrorFormat: {"code":N,"message":"..."}
+// for "json", "HTTP N: message" for "text" and <error><code>N</code><message>...</message></error> for "xml".
+func (p *MyPlugin) writeError(rw http.ResponseWriter, code int, msg string) {
+	switch p.errorFormat {
+	case "text":
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rw.WriteHeader(code)
+		rw.Write([]byte("HTTP " + strconv.Itoa(code) + ": " + msg + "\n"))
+	case "xml":
+		m, _ := xml.Marshal(xmlError{Code: code, Message: msg})
+		rw.Header().Set("Content-Type", "application/xml")
+		rw.WriteHeader(code)
+		rw.Write(m)
+	default:
+		writeJSON(rw, code, map[string]interface{}{"code": code, "message": msg})
+	}
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(rw http.ResponseWriter, status int, v interface{}) {
+	m, _ := json.Marshal(v)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	rw.Write(m)
 }
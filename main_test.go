@@ -0,0 +1,193 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestPlugin builds the plugin with New against f, after modify has adjusted the default config.
+// The next handler echoes the body it receives.
+func newTestPlugin(t testing.TB, f *fakeRedis, modify func(c *Config)) *MyPlugin {
+	t.Helper()
+	config := CreateConfig()
+	option := f.option(0)
+	config.RedisHost, config.RedisPort = option.Host, option.Port
+	config.LogLevel = "error"
+	modify(config)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		rw.Write(body)
+	})
+	handler, err := New(context.Background(), next, config, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := handler.(*MyPlugin)
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+// errReader fails every read.
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("connection reset by peer") }
+
+func TestServeHTTPBodyLimit(t *testing.T) {
+	f := newFakeRedis(t)
+	p := newTestPlugin(t, f, func(c *Config) { c.MaxRequestBodyBytes = 16 })
+
+	tests := []struct {
+		name       string
+		body       io.Reader
+		wantStatus int
+		wantBody   string
+	}{
+		{"one byte under", strings.NewReader(strings.Repeat("a", 15)), http.StatusOK, ""},
+		{"at the limit", strings.NewReader(strings.Repeat("b", 16)), http.StatusOK, ""},
+		{"one byte over", strings.NewReader(strings.Repeat("c", 17)), http.StatusRequestEntityTooLarge, `{"code":413,"message":"body too large"}`},
+		{"read error", errReader{}, http.StatusBadRequest, "connection reset by peer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", tt.body)
+			rw := httptest.NewRecorder()
+			p.ServeHTTP(rw, req)
+
+			if rw.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rw.Code, tt.wantStatus, rw.Body)
+			}
+			if !strings.Contains(rw.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rw.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAlgorithmFor(t *testing.T) {
+	p := &MyPlugin{
+		smAlgorithm: "SM3",
+		mimeRouting: map[string]string{
+			"application/json":         "SM3",
+			"application/octet-stream": "SM4",
+			"application/x-sm2-signed": "SM2VERIFY",
+			"application/":             "SM4-CBC",
+		},
+	}
+
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{"application/json", "SM3"},
+		{"application/json; charset=utf-8", "SM3"},
+		{"application/octet-stream", "SM4"},
+		{"Application/Octet-Stream", "SM4"},
+		{"application/x-sm2-signed", "SM2VERIFY"},
+		{"application/xml", "SM4-CBC"},
+		{"text/plain", "SM3"},
+		{"", "SM3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set("Content-Type", tt.contentType)
+			if got := p.algorithmFor(req); got != tt.want {
+				t.Errorf("algorithmFor(%q) = %s, want %s", tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMIMEAlgorithmRoutingConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		routing map[string]string
+		wantErr string
+	}{
+		{"known algorithms", map[string]string{"application/json": "SM3", "text/": "SM3"}, ""},
+		{"unknown algorithm", map[string]string{"application/json": "MD5"}, `unknown algorithm "MD5"`},
+		{"algorithm without its key", map[string]string{"application/octet-stream": "SM4"}, "sm4Key is required for SM4"},
+		{"SM2VERIFY without a trusted key", map[string]string{"application/x-sm2-signed": "SM2VERIFY"}, "sm2TrustedPublicKeyPEM is required for SM2VERIFY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := CreateConfig()
+			config.MIMEAlgorithmRouting = tt.routing
+
+			_, err := New(context.Background(), http.NotFoundHandler(), config, "test")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("New() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("New() error = %v, want it to mention %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServeHTTPForwardToNext(t *testing.T) {
+	body := strings.Repeat("0123456789abcdef", 4096)
+	hash := hex.EncodeToString(sm3Sum([]byte(body)))
+
+	tests := []struct {
+		name          string
+		algorithm     string
+		forwardToNext bool
+		wantNext      bool
+		// wantBody is what terminal mode answers with
+		wantBody string
+	}{
+		{"unprocessed, forward", "", true, true, ""},
+		{"unprocessed, terminal", "", false, false, body},
+		{"SM3, forward", "SM3", true, true, ""},
+		{"SM3, terminal", "SM3", false, false, `{"code":0,"message":"ok","result":"` + hash + `"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRedis(t)
+			p := newTestPlugin(t, f, func(c *Config) {
+				c.ForwardToNext = tt.forwardToNext
+				c.SMAlgorithm = tt.algorithm
+			})
+			var called bool
+			var nextBody, nextHash string
+			var nextLength int64
+			p.next = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				called = true
+				raw, _ := io.ReadAll(req.Body)
+				nextBody, nextLength = string(raw), req.ContentLength
+				nextHash = req.Header.Get(sm3HashHeader)
+				rw.WriteHeader(http.StatusAccepted)
+			})
+
+			rw := httptest.NewRecorder()
+			p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+			if called != tt.wantNext {
+				t.Fatalf("next called = %v, want %v", called, tt.wantNext)
+			}
+			if tt.wantNext {
+				if nextBody != body || nextLength != int64(len(body)) {
+					t.Errorf("next read %d bytes with Content-Length %d, want all %d", len(nextBody), nextLength, len(body))
+				}
+				if tt.algorithm == "SM3" && nextHash != hash {
+					t.Errorf("next saw %s = %q, want %s", sm3HashHeader, nextHash, hash)
+				}
+				if rw.Code != http.StatusAccepted {
+					t.Errorf("status = %d, want the next handler's 202", rw.Code)
+				}
+				return
+			}
+			if rw.Code != http.StatusOK || rw.Body.String() != tt.wantBody {
+				t.Errorf("terminal mode wrote %d with %d bytes, want %d bytes", rw.Code, rw.Body.Len(), len(tt.wantBody))
+			}
+		})
+	}
+}
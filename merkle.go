@@ -0,0 +1,193 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// merkleProofSuffix is appended to MerklePath for inclusion proof lookups.
+const merkleProofSuffix = "/proof"
+
+// merkleLeafHash and merkleNodeHash prefix the SM3 input with 0x00 for a leaf and 0x01 for an
+// internal node, so an internal node cannot be passed off as a leaf (RFC 6962 style).
+func merkleLeafHash(leaf []byte) []byte {
+	return sm3Sum(append([]byte{0x00}, leaf...))
+}
+
+func merkleNodeHash(left, right []byte) []byte {
+	return sm3Sum(append(append([]byte{0x01}, left...), right...))
+}
+
+// buildMerkleTree returns the levels of a binary SM3 Merkle tree over leaves, from the leaf
+// hashes up to the root. The bottom level holds merkleLeafHash of every leaf, padded with zero
+// hashes to a power of two so every internal node, merkleNodeHash(left, right), has two children.
+// The tree over no leaves is the single zero hash.
+func buildMerkleTree(leaves [][]byte) [][][]byte {
+	size := 1
+	for size < len(leaves) {
+		size *= 2
+	}
+	level := make([][]byte, size)
+	for i := range level {
+		if i < len(leaves) {
+			level[i] = merkleLeafHash(leaves[i])
+		} else {
+			level[i] = make([]byte, 32)
+		}
+	}
+
+	tree := [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, len(level)/2)
+		for i := range next {
+			next[i] = merkleNodeHash(level[2*i], level[2*i+1])
+		}
+		tree = append(tree, next)
+		level = next
+	}
+	return tree
+}
+
+// merkleTreeRoot returns the root of a tree built by buildMerkleTree.
+func merkleTreeRoot(tree [][][]byte) []byte {
+	return tree[len(tree)-1][0]
+}
+
+// merkleProof returns the sibling of the leaf at index on every level below the root, each
+// with the side it sits on. The path starts from merkleLeafHash of the leaf.
+func merkleProof(tree [][][]byte, index int) []map[string]string {
+	proof := make([]map[string]string, 0, len(tree)-1)
+	for _, level := range tree[:len(tree)-1] {
+		sibling, position := index+1, "right"
+		if index%2 == 1 {
+			sibling, position = index-1, "left"
+		}
+		proof = append(proof, map[string]string{"hash": hex.EncodeToString(level[sibling]), "position": position})
+		index /= 2
+	}
+	return proof
+}
+
+// parseMerkleLeaves decodes the hex SM3 hashes used as leaves.
+func parseMerkleLeaves(hashes []string) ([][]byte, error) {
+	if len(hashes) == 0 {
+		return nil, errors.New("hashes must not be empty")
+	}
+	leaves := make([][]byte, len(hashes))
+	for i, h := range hashes {
+		leaf, err := hex.DecodeString(h)
+		if err != nil || len(leaf) != 32 {
+			return nil, errors.New("hashes must be hex SM3 hashes")
+		}
+		leaves[i] = leaf
+	}
+	return leaves, nil
+}
+
+// hexLevels hex-encodes every node of the tree.
+func hexLevels(tree [][][]byte) [][]string {
+	out := make([][]string, len(tree))
+	for i, level := range tree {
+		out[i] = make([]string, len(level))
+		for j, node := range level {
+			out[i][j] = hex.EncodeToString(node)
+		}
+	}
+	return out
+}
+
+// serveMerkleTree builds the tree over {"hashes":[...]} and stores the leaves under
+// <prefix>:merkle:<root>, with the hash TTL, so proofs can be served later.
+func (p *MyPlugin) serveMerkleTree(conn redisConn, rw http.ResponseWriter, req *http.Request, body []byte) {
+	var request struct {
+		Hashes []string `json:"hashes"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		p.writeError(rw, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	leaves, err := parseMerkleLeaves(request.Hashes)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tree := buildMerkleTree(leaves)
+	root := hex.EncodeToString(merkleTreeRoot(tree))
+
+	// 统一为小写 hex, 查询证明时按字符串比较
+	normalized := make([]string, len(leaves))
+	for i, leaf := range leaves {
+		normalized[i] = hex.EncodeToString(leaf)
+	}
+	stored, _ := json.Marshal(normalized)
+	key := p.keyPrefix(req) + ":merkle:" + root
+	if p.hashTTL > 0 {
+		_, err = conn.SetEx(key, p.hashTTL, string(stored))
+	} else {
+		_, err = conn.Set(key, string(stored))
+	}
+	if err != nil {
+		p.logger.Error("保存 Merkle 树失败", logFields{"error": err})
+		p.writeError(rw, http.StatusServiceUnavailable, "redis unavailable")
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"root": root, "tree": hexLevels(tree), "code": 0})
+}
+
+// serveMerkleProof answers GET <MerklePath>/proof?leaf=<hex>&root=<hex> with the sibling path
+// from the leaf to the stored root.
+func (p *MyPlugin) serveMerkleProof(conn redisConn, rw http.ResponseWriter, req *http.Request) {
+	leafHex := strings.ToLower(req.URL.Query().Get("leaf"))
+	root := strings.ToLower(req.URL.Query().Get("root"))
+	if leafHex == "" || root == "" {
+		p.writeError(rw, http.StatusBadRequest, "leaf and root are required")
+		return
+	}
+
+	stored, err := conn.Get(p.keyPrefix(req) + ":merkle:" + root)
+	if err != nil {
+		p.logger.Error("读取 Merkle 树失败", logFields{"error": err})
+		p.writeError(rw, http.StatusServiceUnavailable, "redis unavailable")
+		return
+	}
+	if stored == "" {
+		p.writeError(rw, http.StatusNotFound, "unknown root")
+		return
+	}
+	var hashes []string
+	if err := json.Unmarshal([]byte(stored), &hashes); err != nil {
+		p.writeError(rw, http.StatusInternalServerError, "invalid stored tree")
+		return
+	}
+	leaves, err := parseMerkleLeaves(hashes)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, "invalid stored tree")
+		return
+	}
+
+	index := -1
+	for i, h := range hashes {
+		if h == leafHex {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		p.writeError(rw, http.StatusNotFound, "leaf not in tree")
+		return
+	}
+
+	tree := buildMerkleTree(leaves)
+	writeJSON(rw, http.StatusOK, map[string]interface{}{
+		"root":  root,
+		"leaf":  leafHex,
+		"index": index,
+		"proof": merkleProof(tree, index),
+		"code":  0,
+	})
+}
@@ -0,0 +1,69 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// verifyMerkleProof folds the proof of leaf up to a root the way a client would.
+func verifyMerkleProof(leaf []byte, proof []map[string]string) []byte {
+	node := merkleLeafHash(leaf)
+	for _, step := range proof {
+		sibling, _ := hex.DecodeString(step["hash"])
+		if step["position"] == "left" {
+			node = merkleNodeHash(sibling, node)
+		} else {
+			node = merkleNodeHash(node, sibling)
+		}
+	}
+	return node
+}
+
+func TestBuildMerkleTree(t *testing.T) {
+	a, b, c := sm3Sum([]byte("a")), sm3Sum([]byte("b")), sm3Sum([]byte("c"))
+	zero := make([]byte, 32)
+	ab := merkleNodeHash(merkleLeafHash(a), merkleLeafHash(b))
+
+	tests := []struct {
+		name   string
+		leaves [][]byte
+		want   []byte
+	}{
+		{"empty", nil, zero},
+		{"one leaf", [][]byte{a}, merkleLeafHash(a)},
+		{"two leaves", [][]byte{a, b}, ab},
+		{"padded to four", [][]byte{a, b, c}, merkleNodeHash(ab, merkleNodeHash(merkleLeafHash(c), zero))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := merkleTreeRoot(buildMerkleTree(tt.leaves)); !bytes.Equal(got, tt.want) {
+				t.Errorf("root = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerkleProof(t *testing.T) {
+	var leaves [][]byte
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		leaves = append(leaves, sm3Sum([]byte(s)))
+	}
+	tree := buildMerkleTree(leaves)
+	root := merkleTreeRoot(tree)
+
+	for i, leaf := range leaves {
+		if got := verifyMerkleProof(leaf, merkleProof(tree, i)); !bytes.Equal(got, root) {
+			t.Errorf("proof of leaf %d leads to %x, want the root %x", i, got, root)
+		}
+	}
+
+	// 内部节点不能冒充叶子: 用第二层节点及其上方的路径无法得到根
+	internal := tree[1][0]
+	if got := verifyMerkleProof(internal, merkleProof(tree, 0)[1:]); bytes.Equal(got, root) {
+		t.Error("an internal node verifies as a leaf")
+	}
+	if bytes.Equal(verifyMerkleProof(leaves[1], merkleProof(tree, 0)), root) {
+		t.Error("a leaf verifies with another leaf's proof")
+	}
+}
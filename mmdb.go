@@ -0,0 +1,243 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"os"
+)
+
+// mmdbMetadataMarker precedes the metadata map at the end of a MaxMind DB file.
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// errMMDBFormat reports a file that is not a well-formed MaxMind DB.
+var errMMDBFormat = errors.New("invalid MaxMind DB file")
+
+// mmdbReader looks up records in a MaxMind DB (MMDB) file held in memory. Only what a country
+// lookup needs is implemented: the search tree and the data section decoder.
+type mmdbReader struct {
+	buf        []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	// data is the data section, which starts 16 bytes after the search tree
+	data      []byte
+	ipv4Start uint
+}
+
+// openMMDB reads the MaxMind DB file at path.
+func openMMDB(path string) (*mmdbReader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	start := bytes.LastIndex(buf, mmdbMetadataMarker)
+	if start < 0 {
+		return nil, errMMDBFormat
+	}
+	metadata := buf[start+len(mmdbMetadataMarker):]
+	value, _, err := mmdbDecode(metadata, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	meta, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errMMDBFormat
+	}
+
+	r := &mmdbReader{buf: buf}
+	nodeCount, ok1 := meta["node_count"].(uint64)
+	recordSize, ok2 := meta["record_size"].(uint64)
+	ipVersion, ok3 := meta["ip_version"].(uint64)
+	if !ok1 || !ok2 || !ok3 || (recordSize != 24 && recordSize != 28 && recordSize != 32) {
+		return nil, errMMDBFormat
+	}
+	r.nodeCount, r.recordSize, r.ipVersion = uint(nodeCount), uint(recordSize), uint(ipVersion)
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+16 > uint(start) {
+		return nil, errMMDBFormat
+	}
+	r.data = buf[treeSize+16 : start]
+
+	// IPv6 树中 IPv4 地址位于 ::/96 下
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < r.nodeCount; i++ {
+			node = r.record(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+// record returns the left (bit 0) or right (bit 1) record of node.
+func (r *mmdbReader) record(node, bit uint) uint {
+	b := r.buf[node*r.recordSize/4:]
+	switch r.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:]))
+	}
+}
+
+// lookup returns the record for ip, or nil when the database has none.
+func (r *mmdbReader) lookup(ip net.IP) (interface{}, error) {
+	node, bits := uint(0), 128
+	if v4 := ip.To4(); v4 != nil {
+		ip, bits = v4, 32
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else if r.ipVersion == 4 {
+		return nil, nil
+	}
+
+	for i := 0; i < bits && node < r.nodeCount; i++ {
+		bit := uint(ip[i/8]>>(7-uint(i%8))) & 1
+		node = r.record(node, bit)
+	}
+	if node <= r.nodeCount {
+		// 等于 nodeCount 表示没有记录
+		return nil, nil
+	}
+	value, _, err := mmdbDecode(r.data, int(node-r.nodeCount-16), 0)
+	return value, err
+}
+
+// mmdbMaxDepth bounds pointer and container nesting in corrupt files.
+const mmdbMaxDepth = 32
+
+// mmdbDecode decodes the data field at offset in data and returns it with the offset of the
+// next field. Maps become map[string]interface{}, arrays []interface{}, strings string, unsigned
+// integers uint64, int32 int64, floats float64 and booleans bool; bytes and uint128 are []byte.
+func mmdbDecode(data []byte, offset, depth int) (interface{}, int, error) {
+	if depth > mmdbMaxDepth || offset < 0 || offset >= len(data) {
+		return nil, 0, errMMDBFormat
+	}
+	ctrl := data[offset]
+	offset++
+	typ := int(ctrl >> 5)
+
+	if typ == 1 {
+		// 指针: 大小字段的高两位决定指针长度
+		size := int(ctrl>>3) & 0x3
+		if offset+size+1 > len(data) {
+			return nil, 0, errMMDBFormat
+		}
+		var pointer int
+		switch size {
+		case 0:
+			pointer = int(ctrl&0x7)<<8 | int(data[offset])
+		case 1:
+			pointer = (int(ctrl&0x7)<<16 | int(data[offset])<<8 | int(data[offset+1])) + 2048
+		case 2:
+			pointer = (int(ctrl&0x7)<<24 | int(data[offset])<<16 | int(data[offset+1])<<8 | int(data[offset+2])) + 526336
+		case 3:
+			pointer = int(binary.BigEndian.Uint32(data[offset:]))
+		}
+		value, _, err := mmdbDecode(data, pointer, depth+1)
+		return value, offset + size + 1, err
+	}
+
+	if typ == 0 {
+		// 扩展类型
+		if offset >= len(data) {
+			return nil, 0, errMMDBFormat
+		}
+		typ = 7 + int(data[offset])
+		offset++
+	}
+
+	size := int(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > len(data) {
+			return nil, 0, errMMDBFormat
+		}
+		extra := 0
+		for _, b := range data[offset : offset+n] {
+			extra = extra<<8 | int(b)
+		}
+		size = [...]int{29, 285, 65821}[n-1] + extra
+		offset += n
+	}
+
+	switch typ {
+	case 7: // map
+		m := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			key, next, err := mmdbDecode(data, offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, errMMDBFormat
+			}
+			value, next, err := mmdbDecode(data, next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[k], offset = value, next
+		}
+		return m, offset, nil
+	case 11: // array
+		a := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			value, next, err := mmdbDecode(data, offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a, offset = append(a, value), next
+		}
+		return a, offset, nil
+	case 14: // boolean, 值在大小字段中
+		return size != 0, offset, nil
+	}
+
+	if offset+size > len(data) {
+		return nil, 0, errMMDBFormat
+	}
+	payload := data[offset : offset+size]
+	offset += size
+	switch typ {
+	case 2: // utf8 string
+		return string(payload), offset, nil
+	case 3: // double
+		if size != 8 {
+			return nil, 0, errMMDBFormat
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(payload)), offset, nil
+	case 15: // float
+		if size != 4 {
+			return nil, 0, errMMDBFormat
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(payload))), offset, nil
+	case 5, 6, 9: // uint16, uint32, uint64
+		var v uint64
+		for _, b := range payload {
+			v = v<<8 | uint64(b)
+		}
+		return v, offset, nil
+	case 8: // int32
+		var v uint32
+		for _, b := range payload {
+			v = v<<8 | uint32(b)
+		}
+		return int64(int32(v)), offset, nil
+	case 4, 10: // bytes, uint128
+		return payload, offset, nil
+	default:
+		return nil, 0, errMMDBFormat
+	}
+}
@@ -0,0 +1,137 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// multiAlgorithms are the MultiAlgorithms values, in the order they are reported.
+var multiAlgorithms = []string{"SM3", "SM4", "SM2"}
+
+// multiResult is one algorithm's output from serveMulti.
+type multiResult struct {
+	algorithm string
+	result    interface{}
+	err       error
+}
+
+// serveMulti runs every configured MultiAlgorithms entry over the same body, one goroutine each,
+// and writes their results under "sm2", "sm3" and "sm4" (encoding/json sorts the keys). An
+// algorithm whose key is not configured, or whose key cannot be obtained for this request, gets
+// null; with MultiFailOnMissing that is a 400 instead. If they have not all finished within
+// MultiTimeoutMs the response is a 504, and algorithms that have not started yet are skipped.
+func (p *MyPlugin) serveMulti(rw http.ResponseWriter, req *http.Request, body []byte) {
+	ctx, cancel := context.WithTimeout(req.Context(), p.multiTimeout)
+	defer cancel()
+
+	// 带缓冲, 超时返回后仍在计算的 goroutine 不会阻塞
+	results := make(chan multiResult, len(multiAlgorithms))
+	var wg sync.WaitGroup
+	for _, algorithm := range multiAlgorithms {
+		if !p.multiAlgorithms[algorithm] {
+			continue
+		}
+		wg.Add(1)
+		go func(algorithm string) {
+			defer wg.Done()
+			if ctx.Err() != nil {
+				return
+			}
+			r := multiResult{algorithm: algorithm}
+			switch algorithm {
+			case "SM3":
+				r.result = hex.EncodeToString(sm3Sum(body))
+			case "SM4":
+				r.result, r.err = p.multiSM4(req, body)
+			case "SM2":
+				r.result, r.err = p.multiSM2(body)
+			}
+			results <- r
+		}(algorithm)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	// 客户端断开时 done 也可能先关闭, 这时部分算法没有执行
+	if ctx.Err() != nil {
+		p.writeError(rw, http.StatusGatewayTimeout, "multi-algorithm processing timed out")
+		return
+	}
+	close(results)
+
+	byAlgorithm := make(map[string]multiResult, len(multiAlgorithms))
+	for r := range results {
+		if r.err != nil {
+			p.writeError(rw, http.StatusInternalServerError, r.err.Error())
+			return
+		}
+		byAlgorithm[r.algorithm] = r
+	}
+
+	response := map[string]interface{}{"code": 0, "message": "ok"}
+	var missing []string
+	for _, algorithm := range multiAlgorithms {
+		r, ok := byAlgorithm[algorithm]
+		if !ok {
+			continue
+		}
+		if r.result == nil {
+			missing = append(missing, algorithm)
+		}
+		response[strings.ToLower(algorithm)] = r.result
+	}
+
+	if len(missing) > 0 && p.multiFailOnMissing {
+		p.writeError(rw, http.StatusBadRequest, "not configured: "+strings.Join(missing, ", "))
+		return
+	}
+	writeJSON(rw, http.StatusOK, response)
+}
+
+// multiSM4 encrypts body with SM4-CBC under a random IV. It returns nil without an error when no
+// SM4 key is available.
+func (p *MyPlugin) multiSM4(req *http.Request, body []byte) (interface{}, error) {
+	if p.keys.Load().sm4Key == nil && !p.sm4PasswordDerived {
+		return nil, nil
+	}
+	key, err := p.sm4KeyFor(req)
+	if err != nil {
+		return nil, nil
+	}
+
+	iv, err := randomIV()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := sm4CBCEncrypt(key, iv, body)
+	if err != nil {
+		return nil, err
+	}
+	if macKey := p.keys.Load().sm4CBCMACKey; macKey != nil {
+		ciphertext = appendCBCTag(macKey, iv, ciphertext)
+	}
+	return map[string]string{"result": base64.StdEncoding.EncodeToString(ciphertext), "iv": hex.EncodeToString(iv)}, nil
+}
+
+// multiSM2 signs body with the SM2 private key. It returns nil without an error when no key is configured.
+func (p *MyPlugin) multiSM2(body []byte) (interface{}, error) {
+	if p.sm2PrivateKey == nil {
+		return nil, nil
+	}
+	signature, err := p.signSM2(body)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"signature": signature, "format": p.sm2SignatureFormat}, nil
+}
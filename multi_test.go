@@ -0,0 +1,138 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+const testMultiSM4Key = "00112233445566778899aabbccddeeff"
+
+// newMultiPlugin returns a plugin in MULTI mode with keys for all three algorithms.
+func newMultiPlugin(tb testing.TB, modify func(c *Config)) (*MyPlugin, *sm2.PrivateKey) {
+	tb.Helper()
+	key, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pemKey, err := x509.WritePrivateKeyToPem(key, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	p := newTestPlugin(tb, newFakeRedis(tb), func(c *Config) {
+		c.SMAlgorithm = "MULTI"
+		c.SM4Key = testMultiSM4Key
+		c.SM2PrivateKeyPEM = string(pemKey)
+		c.DuplicateAction = "passthrough"
+		modify(c)
+	})
+	return p, key
+}
+
+// Each result of the parallel run equals what the algorithm produces on its own.
+func TestServeHTTPMulti(t *testing.T) {
+	p, key := newMultiPlugin(t, func(*Config) {})
+	body := testBinaryBody(t, 64<<10)
+
+	rw := httptest.NewRecorder()
+	p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rw.Code, rw.Body)
+	}
+	var response struct {
+		SM3 string `json:"sm3"`
+		SM4 struct {
+			Result string `json:"result"`
+			IV     string `json:"iv"`
+		} `json:"sm4"`
+		SM2 struct {
+			Signature string `json:"signature"`
+		} `json:"sm2"`
+	}
+	if err := json.Unmarshal(rw.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%v: %s", err, rw.Body)
+	}
+
+	if want := hex.EncodeToString(sm3Sum(body)); response.SM3 != want {
+		t.Errorf("sm3 = %s, want %s", response.SM3, want)
+	}
+	sm4Key, _ := hex.DecodeString(testMultiSM4Key)
+	iv, _ := hex.DecodeString(response.SM4.IV)
+	ciphertext, _ := base64.StdEncoding.DecodeString(response.SM4.Result)
+	if want, err := sm4CBCEncrypt(sm4Key, iv, body); err != nil || !bytes.Equal(ciphertext, want) {
+		t.Errorf("sm4 differs from sm4CBCEncrypt under iv %s (%v)", response.SM4.IV, err)
+	}
+	if !VerifySM2Signature(&key.PublicKey, body, response.SM2.Signature) {
+		t.Error("sm2 signature does not verify over the body")
+	}
+}
+
+func TestServeMultiTimeout(t *testing.T) {
+	p, _ := newMultiPlugin(t, func(*Config) {})
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		ctx     func() context.Context
+	}{
+		{"deadline passed", 0, context.Background},
+		{"client gone", time.Minute, func() context.Context {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.multiTimeout = tt.timeout
+			req := httptest.NewRequest(http.MethodPost, "/", nil).WithContext(tt.ctx())
+			rw := httptest.NewRecorder()
+			p.serveMulti(rw, req, []byte("too slow"))
+
+			if rw.Code != http.StatusGatewayTimeout {
+				t.Errorf("status = %d, want 504: %s", rw.Code, rw.Body)
+			}
+		})
+	}
+}
+
+// BenchmarkMulti compares running the three algorithms one after another ("serial") with
+// serveMulti, which runs them in parallel ("concurrent").
+func BenchmarkMulti(b *testing.B) {
+	p, _ := newMultiPlugin(b, func(*Config) {})
+	body := testBinaryBody(b, 256<<10)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	b.Run("serial", func(b *testing.B) {
+		b.SetBytes(int64(len(body)))
+		for i := 0; i < b.N; i++ {
+			sm3Sum(body)
+			if _, err := p.multiSM4(req, body); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := p.multiSM2(body); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("concurrent", func(b *testing.B) {
+		b.SetBytes(int64(len(body)))
+		for i := 0; i < b.N; i++ {
+			rw := httptest.NewRecorder()
+			p.serveMulti(rw, req, body)
+			if rw.Code != http.StatusOK {
+				b.Fatalf("status = %d: %s", rw.Code, rw.Body)
+			}
+		}
+	})
+}
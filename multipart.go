@@ -0,0 +1,68 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// isMultipartForm reports whether the request carries a multipart/form-data body.
+func isMultipartForm(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// serveMultipartHashes streams each part of a multipart/form-data body through SM3 and writes
+// an object mapping the field names to their hashes. A repeated field maps to a list of hashes.
+func (p *MyPlugin) serveMultipartHashes(rw http.ResponseWriter, req *http.Request) {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	hashes := make(map[string]interface{})
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if isBodyTooLarge(err) {
+			p.rejectBodyTooLarge(rw, req)
+			return
+		}
+		if err != nil {
+			p.writeError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// 逐块写入 hasher, 大文件不会整体读入内存
+		hasher := sm3.New()
+		_, err = io.Copy(hasher, part)
+		part.Close()
+		if isBodyTooLarge(err) {
+			p.rejectBodyTooLarge(rw, req)
+			return
+		}
+		if err != nil {
+			p.writeError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+		hashHex := hex.EncodeToString(hasher.Sum(nil))
+
+		name := part.FormName()
+		switch previous := hashes[name].(type) {
+		case nil:
+			hashes[name] = hashHex
+		case string:
+			hashes[name] = []string{previous, hashHex}
+		case []string:
+			hashes[name] = append(previous, hashHex)
+		}
+	}
+
+	writeJSON(rw, http.StatusOK, p.sm3Response(hashes))
+}
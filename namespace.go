@@ -0,0 +1,52 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// maxNamespaceLength caps the namespace taken from NamespaceFromHeader.
+const maxNamespaceLength = 32
+
+// namespaceContextKey carries the request's redis namespace in its context.
+type namespaceContextKey struct{}
+
+// sanitizeNamespace keeps only ASCII letters, digits, '-' and '_' of s and truncates the result
+// to maxNamespaceLength.
+func sanitizeNamespace(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if b.Len() == maxNamespaceLength {
+			break
+		}
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// withNamespace returns req carrying the namespace from NamespaceFromHeader. A request without
+// a usable namespace gets a 400 when RequireNamespace is set and otherwise falls back to the
+// plain key prefix.
+func (p *MyPlugin) withNamespace(rw http.ResponseWriter, req *http.Request) (*http.Request, bool) {
+	namespace := sanitizeNamespace(req.Header.Get(p.namespaceFromHeader))
+	if namespace == "" {
+		if p.requireNamespace {
+			p.writeError(rw, http.StatusBadRequest, "missing "+p.namespaceFromHeader+" header")
+			return req, false
+		}
+		return req, true
+	}
+	return req.WithContext(context.WithValue(req.Context(), namespaceContextKey{}, namespace)), true
+}
+
+// keyPrefix is the prefix of the request's redis keys: RedisKeyPrefix, preceded by the
+// request's namespace when it has one.
+func (p *MyPlugin) keyPrefix(req *http.Request) string {
+	if namespace, ok := req.Context().Value(namespaceContextKey{}).(string); ok {
+		return namespace + ":" + p.redisKeyPrefix
+	}
+	return p.redisKeyPrefix
+}
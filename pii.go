@@ -0,0 +1,65 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// maskPIIValue returns the first 8 hex characters of the SM3 of v. Strings are hashed as is,
+// other values by their JSON encoding, so the same value always gets the same mask.
+func maskPIIValue(v interface{}) string {
+	raw, ok := v.(string)
+	if !ok {
+		encoded, _ := json.Marshal(v)
+		raw = string(encoded)
+	}
+	return hex.EncodeToString(sm3Sum([]byte(raw)))[:8]
+}
+
+// maskPIIPath masks the value at the dot separated path inside v. Arrays met along the way
+// have the rest of the path applied to every element.
+func maskPIIPath(v interface{}, path []string) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		child, ok := node[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			node[path[0]] = maskPIIValue(child)
+			return
+		}
+		maskPIIPath(child, path[1:])
+	case []interface{}:
+		for _, element := range node {
+			maskPIIPath(element, path)
+		}
+	}
+}
+
+// maskPII returns a copy of a JSON body with the configured PII fields masked.
+func (p *MyPlugin) maskPII(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	for _, field := range p.piiFields {
+		maskPIIPath(v, strings.Split(field, "."))
+	}
+	return json.Marshal(v)
+}
+
+// logMaskedBody logs a JSON request body with its PII fields masked. The body itself is not changed.
+func (p *MyPlugin) logMaskedBody(body []byte) {
+	masked, err := p.maskPII(body)
+	if err != nil {
+		// 不是 JSON 请求体, 不记录
+		return
+	}
+	p.logger.Info("请求体(已脱敏)", logFields{"body": string(masked)})
+}
@@ -0,0 +1,84 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaskPII(t *testing.T) {
+	mask := func(s string) string { return hex.EncodeToString(sm3Sum([]byte(s)))[:8] }
+
+	tests := []struct {
+		name   string
+		fields []string
+		body   string
+		want   string
+	}{
+		{"top level", []string{"name"}, `{"name":"张三","age":30}`, `{"age":30,"name":"` + mask("张三") + `"}`},
+		{"nested", []string{"user.idCard"}, `{"user":{"idCard":"110101199003077777","city":"北京"}}`, `{"user":{"city":"北京","idCard":"` + mask("110101199003077777") + `"}}`},
+		{"array elements", []string{"cards.pan"}, `{"cards":[{"pan":"4111"},{"pan":"5500"}]}`, `{"cards":[{"pan":"` + mask("4111") + `"},{"pan":"` + mask("5500") + `"}]}`},
+		{"number by its JSON text", []string{"phone"}, `{"phone":13800138000}`, `{"phone":"` + mask("13800138000") + `"}`},
+		{"missing field", []string{"user.email"}, `{"user":{}}`, `{"user":{}}`},
+		{"path through a scalar", []string{"name.first"}, `{"name":"x"}`, `{"name":"x"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &MyPlugin{piiFields: tt.fields}
+			got, err := p.maskPII([]byte(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("maskPII() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (&MyPlugin{piiFields: []string{"a"}}).maskPII([]byte("not json")); err == nil {
+		t.Error("maskPII() accepted a non-JSON body")
+	}
+}
+
+// The log gets the masked body, the next handler the original one.
+func TestPIIMaskingForwardsOriginal(t *testing.T) {
+	f := newFakeRedis(t)
+	p := newTestPlugin(t, f, func(c *Config) {
+		c.PIIMaskingEnabled = true
+		c.PIIFields = []string{"user.phone"}
+		// 不做处理的算法, 请求交给下游
+		c.SMAlgorithm = ""
+	})
+	var logs bytes.Buffer
+	p.logger = newLogger(&logs, "info")
+
+	body := `{"user":{"phone":"13800138000"},"amount":5}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rw := httptest.NewRecorder()
+	p.ServeHTTP(rw, req)
+
+	if rw.Body.String() != body {
+		t.Errorf("next handler got %s, want the original body %s", rw.Body, body)
+	}
+	if strings.Contains(logs.String(), "13800138000") {
+		t.Error("the phone number reached the log")
+	}
+	var entry struct {
+		Fields struct {
+			Body string `json:"body"`
+		} `json:"fields"`
+	}
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(line, `"body"`) {
+			json.Unmarshal([]byte(line), &entry)
+		}
+	}
+	if want := hex.EncodeToString(sm3Sum([]byte("13800138000")))[:8]; !strings.Contains(entry.Fields.Body, want) {
+		t.Errorf("logged body %q does not carry the mask %s", entry.Fields.Body, want)
+	}
+}
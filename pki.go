@@ -0,0 +1,213 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// certChainHeader carries the client certificate chain, leaf first, as a URL-escaped PEM bundle
+// (the same encoding as nginx's $ssl_client_escaped_cert).
+const certChainHeader = "X-SM2-CertChain"
+
+// pkiCachePrefix prefixes the redis keys of validated chains, keyed by SM3(leaf DER).
+const pkiCachePrefix = "gmsm:pki:"
+
+// parseCertificates parses every CERTIFICATE block of a PEM bundle.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// loadTrustedRoots reads the trust anchors from the .pem, .crt and .cer files in dir.
+func loadTrustedRoots(dir string) ([]*x509.Certificate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []*x509.Certificate
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".pem", ".crt", ".cer":
+		default:
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		certs, err := parseCertificates(data)
+		if err != nil {
+			return nil, errors.New(entry.Name() + ": " + err.Error())
+		}
+		roots = append(roots, certs...)
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("no trusted roots found in " + dir)
+	}
+	return roots, nil
+}
+
+// checkCertificate checks the validity period and signature algorithm of a single certificate.
+func checkCertificate(cert *x509.Certificate, now time.Time) string {
+	if now.Before(cert.NotBefore) {
+		return "certificate not yet valid: " + cert.Subject.CommonName
+	}
+	if now.After(cert.NotAfter) {
+		return "certificate expired: " + cert.Subject.CommonName
+	}
+	if cert.SignatureAlgorithm != x509.SM2WithSM3 {
+		return "unsupported signature algorithm: " + cert.Subject.CommonName
+	}
+	return ""
+}
+
+// checkIssuer checks that issuer may have issued cert, which has caBelow CA certificates
+// between it and the leaf (the leaf itself not counted), and verifies the signature.
+func checkIssuer(cert, issuer *x509.Certificate, caBelow int) string {
+	if !bytes.Equal(cert.RawIssuer, issuer.RawSubject) {
+		return "issuer mismatch: " + cert.Subject.CommonName
+	}
+	if !issuer.BasicConstraintsValid || !issuer.IsCA {
+		return "issuer is not a CA: " + issuer.Subject.CommonName
+	}
+	if issuer.KeyUsage != 0 && issuer.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return "issuer key usage does not allow certificate signing: " + issuer.Subject.CommonName
+	}
+	if (issuer.MaxPathLen > 0 || issuer.MaxPathLenZero) && caBelow > issuer.MaxPathLen {
+		return "path length constraint violated: " + issuer.Subject.CommonName
+	}
+	if err := cert.CheckSignatureFrom(issuer); err != nil {
+		return "invalid signature: " + cert.Subject.CommonName
+	}
+	return ""
+}
+
+// validateChain validates a leaf-first chain against the trusted roots following RFC 5280
+// path validation (without policy and name constraint processing). It returns the failure
+// reason, or "" when the chain is valid.
+func (p *MyPlugin) validateChain(chain []*x509.Certificate, now time.Time) string {
+	if len(chain) == 0 {
+		return "empty certificate chain"
+	}
+	if len(chain) > p.pkiMaxChainDepth {
+		return "certificate chain exceeds maximum depth"
+	}
+
+	leaf := chain[0]
+	if leaf.KeyUsage != 0 && leaf.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return "leaf key usage does not allow digital signature"
+	}
+	if len(leaf.ExtKeyUsage) > 0 {
+		allowed := false
+		for _, usage := range leaf.ExtKeyUsage {
+			allowed = allowed || usage == x509.ExtKeyUsageClientAuth || usage == x509.ExtKeyUsageAny
+		}
+		if !allowed {
+			return "leaf extended key usage does not allow client authentication"
+		}
+	}
+
+	for i, cert := range chain {
+		if reason := checkCertificate(cert, now); reason != "" {
+			return reason
+		}
+		if i+1 < len(chain) {
+			if reason := checkIssuer(cert, chain[i+1], i); reason != "" {
+				return reason
+			}
+		}
+	}
+
+	// 链的最后一个证书必须是受信任的根证书, 或由受信任的根证书签发
+	last := chain[len(chain)-1]
+	for _, root := range p.pkiRoots {
+		if bytes.Equal(root.Raw, last.Raw) {
+			return ""
+		}
+	}
+	if len(chain)+1 > p.pkiMaxChainDepth {
+		return "certificate chain exceeds maximum depth"
+	}
+	reason := "certificate chain does not lead to a trusted root"
+	for _, root := range p.pkiRoots {
+		if !bytes.Equal(last.RawIssuer, root.RawSubject) {
+			continue
+		}
+		if reason = checkCertificate(root, now); reason != "" {
+			continue
+		}
+		if reason = checkIssuer(last, root, len(chain)-1); reason == "" {
+			return ""
+		}
+	}
+	return reason
+}
+
+// checkCertChain validates the chain in the X-SM2-CertChain header, consulting and filling the
+// redis cache of validated leaves. It writes a 401 and returns false when validation fails.
+func (p *MyPlugin) checkCertChain(conn redisConn, rw http.ResponseWriter, req *http.Request) bool {
+	reject := func(reason string) bool {
+		writeJSON(rw, http.StatusUnauthorized, map[string]interface{}{"reason": reason})
+		return false
+	}
+
+	raw, err := url.QueryUnescape(req.Header.Get(certChainHeader))
+	if err != nil || raw == "" {
+		return reject("missing certificate chain")
+	}
+	chain, err := parseCertificates([]byte(raw))
+	if err != nil {
+		return reject("malformed certificate chain")
+	}
+	if len(chain) == 0 {
+		return reject("empty certificate chain")
+	}
+
+	cacheKey := pkiCachePrefix + hex.EncodeToString(sm3Sum(chain[0].Raw))
+	if cached, err := conn.Get(cacheKey); err == nil && cached != "" {
+		return true
+	}
+
+	now := time.Now()
+	if reason := p.validateChain(chain, now); reason != "" {
+		return reject(reason)
+	}
+
+	// 缓存时间不超过叶子证书的有效期
+	ttl := p.pkiCacheTTL
+	if remaining := chain[0].NotAfter.Sub(now); remaining < ttl {
+		ttl = remaining
+	}
+	if seconds := int(ttl.Seconds()); seconds > 0 {
+		if _, err := conn.SetEx(cacheKey, seconds, "1"); err != nil {
+			p.logger.Error("缓存证书链验证结果失败", logFields{"error": err})
+		}
+	}
+	return true
+}
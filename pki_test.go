@@ -0,0 +1,190 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// testSM2Cert issues template with a fresh SM2 key, signed by parentKey under parent, or
+// self-signed when parent is nil. Unset serial numbers and validity periods are filled in.
+func testSM2Cert(t *testing.T, template, parent *x509.Certificate, parentKey *sm2.PrivateKey) (*x509.Certificate, *sm2.PrivateKey) {
+	t.Helper()
+	key, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template.SerialNumber == nil {
+		if template.SerialNumber, err = rand.Int(rand.Reader, big.NewInt(1<<62)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if template.NotBefore.IsZero() {
+		template.NotBefore, template.NotAfter = time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	}
+	template.SignatureAlgorithm = x509.SM2WithSM3
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func testCA(name string) *x509.Certificate {
+	return &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+}
+
+func testLeaf(name string) *x509.Certificate {
+	return &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+}
+
+func TestValidateChain(t *testing.T) {
+	root, rootKey := testSM2Cert(t, testCA("root"), nil, nil)
+	intermediate, intermediateKey := testSM2Cert(t, testCA("intermediate"), root, rootKey)
+	leaf, _ := testSM2Cert(t, testLeaf("leaf"), intermediate, intermediateKey)
+
+	expiredTemplate := testLeaf("expired")
+	expiredTemplate.NotBefore, expiredTemplate.NotAfter = time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour)
+	expired, _ := testSM2Cert(t, expiredTemplate, intermediate, intermediateKey)
+
+	encipherTemplate := testLeaf("encipher-only")
+	encipherTemplate.KeyUsage = x509.KeyUsageKeyEncipherment
+	encipherOnly, _ := testSM2Cert(t, encipherTemplate, intermediate, intermediateKey)
+
+	serverTemplate := testLeaf("server")
+	serverTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	server, _ := testSM2Cert(t, serverTemplate, intermediate, intermediateKey)
+
+	notCA, notCAKey := testSM2Cert(t, testLeaf("not-a-ca"), root, rootKey)
+	issuedByLeaf, _ := testSM2Cert(t, testLeaf("issued-by-leaf"), notCA, notCAKey)
+
+	pathZeroTemplate := testCA("path-len-zero")
+	pathZeroTemplate.MaxPathLenZero = true
+	pathZero, pathZeroKey := testSM2Cert(t, pathZeroTemplate, root, rootKey)
+	belowPathZero, belowPathZeroKey := testSM2Cert(t, testCA("below-path-len-zero"), pathZero, pathZeroKey)
+	tooDeep, _ := testSM2Cert(t, testLeaf("too-deep"), belowPathZero, belowPathZeroKey)
+
+	otherRoot, otherRootKey := testSM2Cert(t, testCA("other-root"), nil, nil)
+	untrusted, _ := testSM2Cert(t, testLeaf("untrusted"), otherRoot, otherRootKey)
+
+	tests := []struct {
+		name     string
+		chain    []*x509.Certificate
+		maxDepth int
+		want     string
+	}{
+		{"valid chain", []*x509.Certificate{leaf, intermediate}, 3, ""},
+		{"valid chain with root", []*x509.Certificate{leaf, intermediate, root}, 3, ""},
+		{"expired leaf", []*x509.Certificate{expired, intermediate}, 3, "certificate expired"},
+		{"wrong key usage", []*x509.Certificate{encipherOnly, intermediate}, 3, "leaf key usage"},
+		{"wrong extended key usage", []*x509.Certificate{server, intermediate}, 3, "leaf extended key usage"},
+		{"issuer not a CA", []*x509.Certificate{issuedByLeaf, notCA}, 3, "issuer is not a CA"},
+		{"chain longer than max depth", []*x509.Certificate{leaf, intermediate, root}, 2, "exceeds maximum depth"},
+		{"implied root beyond max depth", []*x509.Certificate{leaf, intermediate}, 2, "exceeds maximum depth"},
+		{"path length constraint", []*x509.Certificate{tooDeep, belowPathZero, pathZero}, 5, "path length constraint"},
+		{"untrusted root", []*x509.Certificate{untrusted, otherRoot}, 3, "does not lead to a trusted root"},
+		{"empty", nil, 3, "empty certificate chain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &MyPlugin{pkiRoots: []*x509.Certificate{root}, pkiMaxChainDepth: tt.maxDepth}
+			got := p.validateChain(tt.chain, time.Now())
+			if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+				t.Errorf("validateChain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCertChain(t *testing.T) {
+	root, rootKey := testSM2Cert(t, testCA("root"), nil, nil)
+	leaf, _ := testSM2Cert(t, testLeaf("leaf"), root, rootKey)
+	expiredTemplate := testLeaf("expired")
+	expiredTemplate.NotBefore, expiredTemplate.NotAfter = time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour)
+	expired, _ := testSM2Cert(t, expiredTemplate, root, rootKey)
+
+	f := newFakeRedis(t)
+	conn := f.conn(t, 0)
+	p := &MyPlugin{
+		pkiRoots:         []*x509.Certificate{root},
+		pkiMaxChainDepth: 3,
+		pkiCacheTTL:      time.Minute,
+		logger:           newLogger(io.Discard, "error"),
+	}
+	header := func(certs ...*x509.Certificate) string {
+		var bundle []byte
+		for _, cert := range certs {
+			bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+		}
+		return url.QueryEscape(string(bundle))
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		wantOK     bool
+		wantReason string
+	}{
+		{"valid", header(leaf), true, ""},
+		{"expired", header(expired), false, "certificate expired: expired"},
+		{"missing", "", false, "missing certificate chain"},
+		{"not PEM", "garbage", false, "empty certificate chain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(certChainHeader, tt.header)
+			}
+			rw := httptest.NewRecorder()
+			if got := p.checkCertChain(conn, rw, req); got != tt.wantOK {
+				t.Fatalf("checkCertChain() = %v, want %v", got, tt.wantOK)
+			}
+			if tt.wantOK {
+				return
+			}
+			var response struct {
+				Reason string `json:"reason"`
+			}
+			if err := json.Unmarshal(rw.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if rw.Code != http.StatusUnauthorized || response.Reason != tt.wantReason {
+				t.Errorf("got %d %q, want 401 %q", rw.Code, response.Reason, tt.wantReason)
+			}
+		})
+	}
+
+	if _, ok := f.get(0, pkiCachePrefix+hex.EncodeToString(sm3Sum(leaf.Raw))); !ok {
+		t.Error("valid chain was not cached")
+	}
+}
@@ -0,0 +1,56 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	preimagePath = "/preimage"
+	// maxPreimagePrefixLength bounds the search at 2^24 expected attempts.
+	maxPreimagePrefixLength = 3
+)
+
+// servePreimage searches for a random 32-byte message whose SM3 starts with the first
+// prefixLength bytes of target. It demonstrates the cost of preimage resistance.
+func (p *MyPlugin) servePreimage(rw http.ResponseWriter, body []byte) {
+	var request struct {
+		Target       string `json:"target"`
+		PrefixLength int    `json:"prefixLength"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		p.writeError(rw, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if request.PrefixLength < 1 || request.PrefixLength > maxPreimagePrefixLength {
+		p.writeError(rw, http.StatusUnprocessableEntity, "prefixLength must be between 1 and 3")
+		return
+	}
+	target, err := hex.DecodeString(request.Target)
+	if err != nil || len(target) < request.PrefixLength {
+		p.writeError(rw, http.StatusBadRequest, "invalid target")
+		return
+	}
+	prefix := target[:request.PrefixLength]
+
+	candidate := make([]byte, 32)
+	for i := 1; i <= p.preimageMaxIterations; i++ {
+		if _, err := rand.Read(candidate); err != nil {
+			p.writeError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if bytes.HasPrefix(sm3Sum(candidate), prefix) {
+			writeJSON(rw, http.StatusOK, map[string]interface{}{
+				"found":      true,
+				"preimage":   hex.EncodeToString(candidate),
+				"iterations": i,
+			})
+			return
+		}
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"found": false})
+}
@@ -0,0 +1,63 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/piaohao/godis"
+)
+
+// hashNotification is the message published for every stored hash.
+type hashNotification struct {
+	Hash      string `json:"hash"`
+	Algorithm string `json:"algorithm"`
+	TS        int64  `json:"ts"`
+	RequestID string `json:"requestId"`
+}
+
+// hashPublisher publishes hash notifications from a background goroutine on its own connection,
+// so a slow redis or subscriber never delays a request. Messages that do not fit in the queue are
+// dropped.
+type hashPublisher struct {
+	option  godis.Option
+	channel string
+	queue   chan []byte
+	logger  *logger
+}
+
+// newHashPublisher starts publishing to channel and stops when ctx is done.
+func newHashPublisher(ctx context.Context, option godis.Option, channel string, queueSize int, logger *logger) *hashPublisher {
+	p := &hashPublisher{option: option, channel: channel, queue: make(chan []byte, queueSize), logger: logger}
+	go p.run(ctx)
+	return p
+}
+
+func (p *hashPublisher) run(ctx context.Context) {
+	r := newRedis(p.option, p.logger)
+	defer func() { r.Close() }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-p.queue:
+			if _, err := r.Publish(p.channel, string(message)); err != nil {
+				// 连接可能已断开, 换一个新连接, 这条消息丢弃
+				p.logger.Error("发布 hash 通知失败", logFields{"error": err})
+				r.Close()
+				r = newRedis(p.option, p.logger)
+			}
+		}
+	}
+}
+
+// publish queues a notification for hashHex without blocking.
+func (p *hashPublisher) publish(algorithm, hashHex, requestID string) {
+	message, _ := json.Marshal(hashNotification{Hash: hashHex, Algorithm: algorithm, TS: time.Now().Unix(), RequestID: requestID})
+	select {
+	case p.queue <- message:
+	default:
+		p.logger.Warn("hash 通知队列已满, 丢弃", logFields{"hash": hashHex})
+	}
+}
@@ -0,0 +1,76 @@
+package gmsmPlugin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateLimitScript increments the window counter, starts the window on the first request
+// and returns {count, ttl}.
+const rateLimitScript = `
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[1])
+end
+return {count, redis.call('TTL', KEYS[1])}
+`
+
+// rateLimitClient identifies the client by the remote address. X-Forwarded-For is only read when
+// the peer is a trusted proxy, and then from the right: the first address that is not a trusted
+// proxy is the client, anything to its left was written by the client itself.
+func (p *MyPlugin) rateLimitClient(req *http.Request) string {
+	client := clientIP(req)
+	if !containsIP(p.trustedProxyNets, client) {
+		return client
+	}
+	hops := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		client = hop
+		if !containsIP(p.trustedProxyNets, hop) {
+			break
+		}
+	}
+	return client
+}
+
+// checkRateLimit counts the request against the client's fixed window and sets the
+// X-RateLimit-* headers. It writes 429 and returns false once the limit is exceeded.
+// Redis errors are logged and the request is let through.
+func (p *MyPlugin) checkRateLimit(conn redisConn, rw http.ResponseWriter, req *http.Request) bool {
+	key := p.keyPrefix(req) + ":ratelimit:" + p.rateLimitClient(req)
+	reply, err := conn.Eval(rateLimitScript, 1, key, strconv.Itoa(p.rateLimitWindow))
+	if err != nil {
+		p.logger.Error("限流计数失败", logFields{"error": err})
+		return true
+	}
+	values, _ := reply.([]interface{})
+	if len(values) != 2 {
+		p.logger.Error("限流脚本返回值无效", nil)
+		return true
+	}
+	count, _ := values[0].(int64)
+	ttl, _ := values[1].(int64)
+	if ttl < 0 {
+		ttl = int64(p.rateLimitWindow)
+	}
+
+	remaining := int64(p.rateLimitMax) - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	rw.Header().Set("X-RateLimit-Limit", strconv.Itoa(p.rateLimitMax))
+	rw.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+	rw.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix()+ttl, 10))
+
+	if count > int64(p.rateLimitMax) {
+		p.writeError(rw, http.StatusTooManyRequests, "rate limit exceeded")
+		return false
+	}
+	return true
+}
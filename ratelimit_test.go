@@ -0,0 +1,128 @@
+package gmsmPlugin
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeRateLimit is the fakeRedis stand-in for rateLimitScript.
+func fakeRateLimit(call func(args ...string) interface{}, keys, argv []string) interface{} {
+	count := call("INCR", keys[0])
+	if count == int64(1) {
+		call("EXPIRE", keys[0], argv[0])
+	}
+	return []interface{}{count, call("TTL", keys[0])}
+}
+
+func TestRateLimitClient(t *testing.T) {
+	trusted, err := parseCIDRs("trustedProxyCIDRs", []string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &MyPlugin{trustedProxyNets: trusted}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:4000", nil, "203.0.113.7"},
+		{"spoofed header from untrusted peer", "203.0.113.7:4000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:4000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"client prepends a forged hop", "10.0.0.2:4000", []string{"192.0.2.9, 198.51.100.1"}, "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.2:4000", []string{"198.51.100.1, 10.1.1.1"}, "198.51.100.1"},
+		{"several headers", "10.0.0.2:4000", []string{"192.0.2.9", "198.51.100.1"}, "198.51.100.1"},
+		{"trusted proxy without header", "10.0.0.2:4000", nil, "10.0.0.2"},
+		{"only trusted hops", "10.0.0.2:4000", []string{"10.3.3.3"}, "10.3.3.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if got := p.rateLimitClient(req); got != tt.want {
+				t.Errorf("rateLimitClient() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// The limit-th request in a window still passes, the one after it is rejected.
+func TestCheckRateLimitBoundary(t *testing.T) {
+	f := newFakeRedis(t)
+	f.script(rateLimitScript, fakeRateLimit)
+	conn := f.conn(t, 0)
+	p := &MyPlugin{
+		redisKeyPrefix:  "gmsm",
+		rateLimitMax:    3,
+		rateLimitWindow: 60,
+		logger:          newLogger(io.Discard, "error"),
+	}
+
+	tests := []struct {
+		wantOK        bool
+		wantRemaining string
+	}{
+		{true, "2"},
+		{true, "1"},
+		{true, "0"},
+		{false, "0"},
+	}
+	for i, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		// 不受信任的对端换 X-Forwarded-For 也算同一个客户端
+		req.Header.Set("X-Forwarded-For", net.IPv4(198, 51, 100, byte(i)).String())
+		rw := httptest.NewRecorder()
+
+		if got := p.checkRateLimit(conn, rw, req); got != tt.wantOK {
+			t.Errorf("request %d: checkRateLimit() = %v, want %v", i+1, got, tt.wantOK)
+		}
+		if got := rw.Header().Get("X-RateLimit-Remaining"); got != tt.wantRemaining {
+			t.Errorf("request %d: X-RateLimit-Remaining = %s, want %s", i+1, got, tt.wantRemaining)
+		}
+		if !tt.wantOK && rw.Code != http.StatusTooManyRequests {
+			t.Errorf("request %d: status = %d, want 429", i+1, rw.Code)
+		}
+	}
+	if v, _ := f.get(0, "gmsm:ratelimit:203.0.113.7"); v != "4" {
+		t.Errorf("gmsm:ratelimit:203.0.113.7 = %q, want 4", v)
+	}
+}
+
+// The bucket is keyed like the fixed window: by the peer, not by a forwarded address it sent.
+func TestCheckTokenBucketClient(t *testing.T) {
+	f := newFakeRedis(t)
+	var keys []string
+	f.script(tokenBucketScript, func(call func(args ...string) interface{}, k, argv []string) interface{} {
+		keys = append(keys, k[0])
+		return []interface{}{int64(0), int64(1500)}
+	})
+	conn := f.conn(t, 0)
+	p := &MyPlugin{
+		redisKeyPrefix:        "gmsm",
+		tokenBucketCapacity:   1,
+		tokenBucketRefillRate: 1,
+		logger:                newLogger(io.Discard, "error"),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.RemoteAddr = "203.0.113.7:4000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	rw := httptest.NewRecorder()
+	if p.checkTokenBucket(conn, rw, req) {
+		t.Fatal("checkTokenBucket() allowed a request to an empty bucket")
+	}
+	if got := rw.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %s, want 2", got)
+	}
+	if len(keys) != 1 || keys[0] != "gmsm:tokenbucket:203.0.113.7" {
+		t.Errorf("bucket keys = %v, want [gmsm:tokenbucket:203.0.113.7]", keys)
+	}
+}
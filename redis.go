@@ -0,0 +1,192 @@
+package gmsmPlugin
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/piaohao/godis"
+)
+
+// newRedis creates a client and connects it right away. godis only sends AUTH and SELECT from
+// Connect; a client that connects lazily on its first command ignores Password and Db.
+func newRedis(option godis.Option, logger *logger) *godis.Redis {
+	r := godis.NewRedis(&option)
+	if err := r.Connect(); err != nil {
+		logger.Error("连接 redis 数据库失败", logFields{"db": option.Db, "error": err})
+	}
+	return r
+}
+
+// redisPool hands out pooled connections; Close on a connection returns it.
+// *godis.Pool and *sentinelPool implement it.
+type redisPool interface {
+	GetResource() (*godis.Redis, error)
+	Destroy()
+}
+
+// trackedPool counts the connections borrowed from a pool, so that a pool replaced by a reload is
+// destroyed only after the last of them has been returned.
+type trackedPool struct {
+	redisPool
+
+	mu       sync.Mutex
+	borrowed int
+	retired  bool
+}
+
+func newTrackedPool(pool redisPool) *trackedPool {
+	return &trackedPool{redisPool: pool}
+}
+
+// borrow takes a connection from the pool. It returns ok false, without touching the pool,
+// once the pool has been retired.
+func (t *trackedPool) borrow() (conn *pooledConn, ok bool, err error) {
+	t.mu.Lock()
+	if t.retired {
+		t.mu.Unlock()
+		return nil, false, nil
+	}
+	t.borrowed++
+	t.mu.Unlock()
+
+	r, err := t.GetResource()
+	if err != nil {
+		t.release()
+		return nil, true, err
+	}
+	return &pooledConn{Redis: r, pool: t}, true, nil
+}
+
+func (t *trackedPool) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.borrowed--
+	if t.retired && t.borrowed == 0 {
+		t.redisPool.Destroy()
+	}
+}
+
+// retire stops lending connections and destroys the pool as soon as none is borrowed.
+func (t *trackedPool) retire() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.retired {
+		return
+	}
+	t.retired = true
+	if t.borrowed == 0 {
+		t.redisPool.Destroy()
+	}
+}
+
+// pooledConn is a connection borrowed from a trackedPool; Close returns it.
+type pooledConn struct {
+	*godis.Redis
+	pool *trackedPool
+	once sync.Once
+}
+
+func (c *pooledConn) Close() error {
+	err := c.Redis.Close()
+	c.once.Do(c.pool.release)
+	return err
+}
+
+// borrowConn borrows a connection from the current pool. A pool retired by a reload between
+// loading it and borrowing from it is skipped in favour of its replacement.
+func (p *MyPlugin) borrowConn() (*pooledConn, error) {
+	for {
+		conn, ok, err := p.pool.Load().borrow()
+		if ok {
+			return conn, err
+		}
+	}
+}
+
+// redisConn holds the commands used while serving a request; Close releases it.
+// *godis.Redis implements it, and so does *clusterClient, which routes each command by its key.
+type redisConn interface {
+	Ping() (string, error)
+	Get(key string) (string, error)
+	Set(key, value string) (string, error)
+	SetEx(key string, seconds int, value string) (string, error)
+	SetNx(key, value string) (int64, error)
+	SetWithParams(key, value, nxxx string) (string, error)
+	SetWithParamsAndTime(key, value, nxxx, expx string, time int64) (string, error)
+	SetBitWithBool(key string, offset int64, value bool) (bool, error)
+	Incr(key string) (int64, error)
+	Expire(key string, seconds int) (int64, error)
+	Del(keys ...string) (int64, error)
+	HGet(key, field string) (string, error)
+	HSet(key, field, value string) (int64, error)
+	HMSet(key string, hash map[string]string) (string, error)
+	HGetAll(key string) (map[string]string, error)
+	SAdd(key string, members ...string) (int64, error)
+	SIsMember(key, member string) (bool, error)
+	SInter(keys ...string) ([]string, error)
+	MGet(keys ...string) ([]string, error)
+	RPush(key string, members ...string) (int64, error)
+	LRange(key string, start, stop int64) ([]string, error)
+	ZAdd(key string, score float64, member string, params ...*godis.ZAddParams) (int64, error)
+	ZRangeByScore(key string, min, max float64) ([]string, error)
+	ZRemRangeByScore(key string, min, max float64) (int64, error)
+	Eval(script string, keyCount int, params ...string) (interface{}, error)
+	Close() error
+}
+
+// redisDo sends a command that godis has no wrapper for (e.g. XADD) and returns the raw reply:
+// bulk strings are []byte and multi-bulk replies are []interface{}. On a cluster the command is
+// routed by its first argument, which must be the key.
+func redisDo(conn redisConn, cmd string, args ...string) (interface{}, error) {
+	if _, ok := conn.(unavailableConn); ok {
+		return nil, errRedisBreakerOpen
+	}
+	if c, ok := conn.(*encryptedConn); ok {
+		// 原始命令不经过加密
+		conn = c.redisConn
+	}
+	if c, ok := conn.(*clusterClient); ok {
+		return c.do(firstKey(args), func(r *godis.Redis) (interface{}, error) { return redisDo(r, cmd, args...) })
+	}
+	if c, ok := conn.(*pooledConn); ok {
+		conn = c.Redis
+	}
+
+	r := conn.(*godis.Redis)
+	raw := make([][]byte, len(args))
+	for i, arg := range args {
+		raw[i] = []byte(arg)
+	}
+	if err := r.SendByStr(cmd, raw...); err != nil {
+		return nil, err
+	}
+	return r.Receive()
+}
+
+// scoredMember is a sorted set member with its score.
+// godis.Tuple does not export its fields, so WITHSCORES replies are parsed here.
+type scoredMember struct {
+	Member string
+	Score  float64
+}
+
+// zrangeWithScores runs ZRANGE or ZREVRANGE (cmd) with WITHSCORES.
+func zrangeWithScores(r redisConn, cmd, key string, start, stop int64) ([]scoredMember, error) {
+	reply, err := redisDo(r, cmd, key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10), "WITHSCORES")
+	if err != nil {
+		return nil, err
+	}
+
+	items, _ := reply.([]interface{})
+	members := make([]scoredMember, 0, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		member, _ := items[i].([]byte)
+		rawScore, _ := items[i+1].([]byte)
+		score, err := strconv.ParseFloat(string(rawScore), 64)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, scoredMember{Member: string(member), Score: score})
+	}
+	return members, nil
+}
@@ -0,0 +1,418 @@
+package gmsmPlugin
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/piaohao/godis"
+)
+
+// fakeRedis is an in-process RESP server with one keyspace per database, enough of redis for
+// the plugin's tests. Expiry times are accepted and ignored. EVAL runs the Go stand-ins
+// registered with script.
+type fakeRedis struct {
+	listener net.Listener
+	// latency is slept before every batch of replies is written, as a stand-in for the network
+	// round-trip: a pipeline pays it once, serial commands once each.
+	latency time.Duration
+	scripts map[string]fakeScript
+	// intercept, when set, sees every command first, with whether the connection sent ASKING just
+	// before it. A non-nil reply is written instead of running the command. Set it before the
+	// first connection is made.
+	intercept func(args []string, asking bool) interface{}
+
+	mu      sync.Mutex
+	strings map[int]map[string]string
+	lists   map[int]map[string][]string
+	sets    map[int]map[string]map[string]bool
+	hashes  map[int]map[string]map[string]string
+	// masters answers SENTINEL get-master-addr-by-name: master name to "host:port"
+	masters map[string]string
+	// subscribers are the connections subscribed to each channel
+	subscribers map[string][]net.Conn
+	commands    int
+}
+
+// newFakeRedis starts a server on a random local port; it is closed when the test ends.
+func newFakeRedis(t testing.TB) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serveFakeRedis(t, listener)
+}
+
+// serveFakeRedis starts a server accepting connections from listener, e.g. a TLS listener.
+func serveFakeRedis(t testing.TB, listener net.Listener) *fakeRedis {
+	f := &fakeRedis{
+		listener: listener,
+		strings:  make(map[int]map[string]string),
+		lists:    make(map[int]map[string][]string),
+		sets:     make(map[int]map[string]map[string]bool),
+		hashes:   make(map[int]map[string]map[string]string),
+		masters:  make(map[string]string),
+
+		subscribers: make(map[string][]net.Conn),
+		scripts:     make(map[string]fakeScript),
+	}
+	go f.serve()
+	t.Cleanup(func() { listener.Close() })
+	return f
+}
+
+// fakeScript stands in for a Lua script: call runs a redis command like redis.call does.
+type fakeScript func(call func(args ...string) interface{}, keys, argv []string) interface{}
+
+// script registers fn as the implementation of the Lua source src. Register scripts before the
+// first connection is made.
+func (f *fakeRedis) script(src string, fn fakeScript) {
+	f.scripts[src] = fn
+}
+
+// option returns the godis option for database db of the server.
+func (f *fakeRedis) option(db int) godis.Option {
+	addr := f.listener.Addr().(*net.TCPAddr)
+	return godis.Option{Host: "127.0.0.1", Port: addr.Port, Db: db, ConnectionTimeout: time.Second, SoTimeout: 5 * time.Second}
+}
+
+// conn returns a connection to database db, closed when the test ends.
+func (f *fakeRedis) conn(t testing.TB, db int) *godis.Redis {
+	t.Helper()
+	option := f.option(db)
+	r := godis.NewRedis(&option)
+	if err := r.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+// get returns key in database db and whether it exists.
+func (f *fakeRedis) get(db int, key string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.strings[db][key]
+	return v, ok
+}
+
+// hget returns field of the hash key in database db and whether it exists.
+func (f *fakeRedis) hget(db int, key, field string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.hashes[db][key][field]
+	return v, ok
+}
+
+// setMaster makes the server, acting as a sentinel, report addr as the master called name.
+func (f *fakeRedis) setMaster(name, addr string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.masters[name] = addr
+}
+
+// publish sends message to the connections subscribed to channel.
+func (f *fakeRedis) publish(channel, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range f.subscribers[channel] {
+		w := bufio.NewWriter(c)
+		writeReply(w, []interface{}{[]byte("message"), []byte(channel), []byte(message)})
+		w.Flush()
+	}
+}
+
+// addr returns the "host:port" the server listens on.
+func (f *fakeRedis) addr() string {
+	return f.listener.Addr().String()
+}
+
+// set stores key in database db.
+func (f *fakeRedis) set(db int, key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.initDB(db)
+	f.strings[db][key] = value
+}
+
+// initDB creates the keyspaces of database db on first use; f.mu must be held.
+func (f *fakeRedis) initDB(db int) {
+	if f.strings[db] == nil {
+		f.strings[db] = make(map[string]string)
+		f.lists[db] = make(map[string][]string)
+		f.sets[db] = make(map[string]map[string]bool)
+		f.hashes[db] = make(map[string]map[string]string)
+	}
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		c, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(c)
+	}
+}
+
+func (f *fakeRedis) handle(c net.Conn) {
+	defer c.Close()
+	r, w := bufio.NewReader(c), bufio.NewWriter(c)
+	db := 0
+	asking := false
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		name := strings.ToUpper(args[0])
+		var intercepted interface{}
+		if f.intercept != nil {
+			intercepted = f.intercept(args, asking)
+		}
+		asking = name == "ASKING"
+		if name == "SUBSCRIBE" {
+			// 订阅之后连接只接收 publish 推送的消息
+			for i, channel := range args[1:] {
+				writeReply(w, []interface{}{[]byte("subscribe"), []byte(channel), int64(i + 1)})
+			}
+			w.Flush()
+			f.mu.Lock()
+			for _, channel := range args[1:] {
+				f.subscribers[channel] = append(f.subscribers[channel], c)
+			}
+			f.mu.Unlock()
+			continue
+		}
+		if intercepted != nil {
+			writeReply(w, intercepted)
+		} else if name == "SELECT" && len(args) == 2 {
+			db, _ = strconv.Atoi(args[1])
+			writeReply(w, "OK")
+		} else if name == "ASKING" {
+			writeReply(w, "OK")
+		} else {
+			writeReply(w, f.exec(db, name, args[1:]))
+		}
+		// 客户端发完 pipeline 才读回复, 缓冲区空时再写出
+		if r.Buffered() == 0 {
+			if f.latency > 0 {
+				time.Sleep(f.latency)
+			}
+			if w.Flush() != nil {
+				return
+			}
+		}
+		if name == "QUIT" {
+			w.Flush()
+			return
+		}
+	}
+}
+
+// exec runs one command against database db and returns its reply: string for a status,
+// []byte or nil for a bulk string, int64, []interface{} or error.
+func (f *fakeRedis) exec(db int, name string, args []string) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.run(db, name, args)
+}
+
+// run is exec with f.mu held.
+func (f *fakeRedis) run(db int, name string, args []string) interface{} {
+	f.commands++
+	f.initDB(db)
+	keyspace := f.strings[db]
+
+	switch name {
+	case "PING":
+		return "PONG"
+	case "AUTH", "QUIT":
+		return "OK"
+	case "GET":
+		if v, ok := keyspace[args[0]]; ok {
+			return []byte(v)
+		}
+		return nil
+	case "SET":
+		for _, opt := range args[2:] {
+			if _, exists := keyspace[args[0]]; strings.EqualFold(opt, "NX") && exists {
+				return nil
+			}
+		}
+		keyspace[args[0]] = args[1]
+		return "OK"
+	case "SETEX":
+		keyspace[args[0]] = args[2]
+		return "OK"
+	case "MGET":
+		values := make([]interface{}, len(args))
+		for i, key := range args {
+			if v, ok := keyspace[key]; ok {
+				values[i] = []byte(v)
+			}
+		}
+		return values
+	case "DEL":
+		var n int64
+		for _, key := range args {
+			if _, ok := keyspace[key]; ok {
+				delete(keyspace, key)
+				n++
+			}
+		}
+		return n
+	case "INCR":
+		n, err := strconv.ParseInt(keyspace[args[0]], 10, 64)
+		if _, exists := keyspace[args[0]]; exists && err != nil {
+			return errors.New("ERR value is not an integer or out of range")
+		}
+		n++
+		keyspace[args[0]] = strconv.FormatInt(n, 10)
+		return n
+	case "EXPIRE":
+		if _, ok := keyspace[args[0]]; ok {
+			return int64(1)
+		}
+		return int64(0)
+	case "TTL":
+		if _, ok := keyspace[args[0]]; ok {
+			return int64(-1)
+		}
+		return int64(-2)
+	case "RPUSH":
+		f.lists[db][args[0]] = append(f.lists[db][args[0]], args[1:]...)
+		return int64(len(f.lists[db][args[0]]))
+	case "SADD":
+		if f.sets[db][args[0]] == nil {
+			f.sets[db][args[0]] = make(map[string]bool)
+		}
+		var n int64
+		for _, member := range args[1:] {
+			if !f.sets[db][args[0]][member] {
+				f.sets[db][args[0]][member] = true
+				n++
+			}
+		}
+		return n
+	case "HSET", "HMSET":
+		if f.hashes[db][args[0]] == nil {
+			f.hashes[db][args[0]] = make(map[string]string)
+		}
+		var n int64
+		for i := 1; i+1 < len(args); i += 2 {
+			if _, ok := f.hashes[db][args[0]][args[i]]; !ok {
+				n++
+			}
+			f.hashes[db][args[0]][args[i]] = args[i+1]
+		}
+		if name == "HMSET" {
+			return "OK"
+		}
+		return n
+	case "HGET":
+		if v, ok := f.hashes[db][args[0]][args[1]]; ok {
+			return []byte(v)
+		}
+		return nil
+	case "EVAL":
+		fn, ok := f.scripts[args[0]]
+		numKeys, err := strconv.Atoi(args[1])
+		if !ok || err != nil {
+			return errors.New("NOSCRIPT no fake for this script")
+		}
+		call := func(args ...string) interface{} { return f.run(db, strings.ToUpper(args[0]), args[1:]) }
+		return fn(call, args[2:2+numKeys], args[2+numKeys:])
+	case "SENTINEL":
+		if !strings.EqualFold(args[0], "get-master-addr-by-name") {
+			break
+		}
+		host, port, err := net.SplitHostPort(f.masters[args[1]])
+		if err != nil {
+			return []interface{}{nil, nil}
+		}
+		return []interface{}{[]byte(host), []byte(port)}
+	case "SISMEMBER":
+		if f.sets[db][args[0]][args[1]] {
+			return int64(1)
+		}
+		return int64(0)
+	}
+	return errors.New("ERR unknown command '" + name + "'")
+}
+
+// readCommand reads one RESP array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, errors.New("expected array")
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || n < 1 {
+		return nil, errors.New("invalid array length")
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func writeReply(w *bufio.Writer, reply interface{}) {
+	switch v := reply.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case string:
+		w.WriteString("+" + v + "\r\n")
+	case []byte:
+		w.WriteString("$" + strconv.Itoa(len(v)) + "\r\n" + string(v) + "\r\n")
+	case int64:
+		w.WriteString(":" + strconv.FormatInt(v, 10) + "\r\n")
+	case error:
+		w.WriteString("-" + v.Error() + "\r\n")
+	case []interface{}:
+		w.WriteString("*" + strconv.Itoa(len(v)) + "\r\n")
+		for _, item := range v {
+			writeReply(w, item)
+		}
+	}
+}
+
+func TestFakeRedis(t *testing.T) {
+	f := newFakeRedis(t)
+	r := f.conn(t, 3)
+
+	if _, err := r.Set("k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := r.Get("k"); err != nil || got != "v" {
+		t.Fatalf("Get() = %q, %v", got, err)
+	}
+	if v, ok := f.get(3, "k"); !ok || v != "v" {
+		t.Errorf("key not stored in db 3")
+	}
+	if _, ok := f.get(0, "k"); ok {
+		t.Errorf("key leaked into db 0")
+	}
+}
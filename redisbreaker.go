@@ -0,0 +1,198 @@
+package gmsmPlugin
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/piaohao/godis"
+)
+
+// Redis circuit breaker states.
+const (
+	breakerClosed int32 = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+var breakerStateNames = [...]string{"closed", "open", "half-open"}
+
+// errRedisBreakerOpen is returned instead of contacting redis while the breaker is open.
+var errRedisBreakerOpen = errors.New("redis circuit breaker open")
+
+// redisBreaker stops contacting redis after threshold consecutive failures to get a connection.
+// After timeout one request is let through (half-open): its success closes the breaker, its
+// failure opens it again for another timeout.
+type redisBreaker struct {
+	state     int32
+	failures  int32
+	threshold int32
+	timeout   time.Duration
+
+	mu       sync.Mutex
+	openedAt time.Time
+
+	logger *logger
+}
+
+func newRedisBreaker(threshold int, timeout time.Duration, logger *logger) *redisBreaker {
+	return &redisBreaker{threshold: int32(threshold), timeout: timeout, logger: logger}
+}
+
+// allow reports whether redis may be contacted.
+func (b *redisBreaker) allow() bool {
+	switch atomic.LoadInt32(&b.state) {
+	case breakerClosed:
+		return true
+	case breakerHalfOpen:
+		// 试探请求尚未返回
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if time.Since(b.openedAt) < b.timeout {
+		return false
+	}
+	// 只有一个请求能完成 open -> half-open, 由它试探 redis
+	return b.transition(breakerOpen, breakerHalfOpen)
+}
+
+func (b *redisBreaker) success() {
+	atomic.StoreInt32(&b.failures, 0)
+	b.transition(breakerHalfOpen, breakerClosed)
+}
+
+func (b *redisBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.transition(breakerHalfOpen, breakerOpen) {
+		b.openedAt = time.Now()
+		return
+	}
+	if atomic.AddInt32(&b.failures, 1) >= b.threshold && b.transition(breakerClosed, breakerOpen) {
+		b.openedAt = time.Now()
+	}
+}
+
+// transition moves the breaker from one state to another and logs it. It reports false when the
+// breaker was not in the from state.
+func (b *redisBreaker) transition(from, to int32) bool {
+	if !atomic.CompareAndSwapInt32(&b.state, from, to) {
+		return false
+	}
+	b.logger.Warn("redis 熔断器状态变化", logFields{
+		"from": breakerStateNames[from], "to": breakerStateNames[to], "failures": atomic.LoadInt32(&b.failures),
+	})
+	return true
+}
+
+// unavailableConn stands in for a redis connection while the breaker is open and
+// CircuitBreakerFallthrough is set. Every command fails at once with errRedisBreakerOpen, so
+// hashing goes on and each redis-backed feature falls back as it does on a redis error.
+type unavailableConn struct{}
+
+func (unavailableConn) Ping() (string, error) {
+	return "", errRedisBreakerOpen
+}
+
+func (unavailableConn) Get(string) (string, error) {
+	return "", errRedisBreakerOpen
+}
+
+func (unavailableConn) Set(string, string) (string, error) {
+	return "", errRedisBreakerOpen
+}
+
+func (unavailableConn) SetEx(string, int, string) (string, error) {
+	return "", errRedisBreakerOpen
+}
+
+func (unavailableConn) SetNx(string, string) (int64, error) {
+	return 0, errRedisBreakerOpen
+}
+
+func (unavailableConn) SetWithParams(string, string, string) (string, error) {
+	return "", errRedisBreakerOpen
+}
+
+func (unavailableConn) SetWithParamsAndTime(string, string, string, string, int64) (string, error) {
+	return "", errRedisBreakerOpen
+}
+
+func (unavailableConn) SetBitWithBool(string, int64, bool) (bool, error) {
+	return false, errRedisBreakerOpen
+}
+
+func (unavailableConn) Incr(string) (int64, error) {
+	return 0, errRedisBreakerOpen
+}
+
+func (unavailableConn) Expire(string, int) (int64, error) {
+	return 0, errRedisBreakerOpen
+}
+
+func (unavailableConn) Del(...string) (int64, error) {
+	return 0, errRedisBreakerOpen
+}
+
+func (unavailableConn) HGet(string, string) (string, error) {
+	return "", errRedisBreakerOpen
+}
+
+func (unavailableConn) HSet(string, string, string) (int64, error) {
+	return 0, errRedisBreakerOpen
+}
+
+func (unavailableConn) HMSet(string, map[string]string) (string, error) {
+	return "", errRedisBreakerOpen
+}
+
+func (unavailableConn) HGetAll(string) (map[string]string, error) {
+	return nil, errRedisBreakerOpen
+}
+
+func (unavailableConn) SAdd(string, ...string) (int64, error) {
+	return 0, errRedisBreakerOpen
+}
+
+func (unavailableConn) SIsMember(string, string) (bool, error) {
+	return false, errRedisBreakerOpen
+}
+
+func (unavailableConn) SInter(...string) ([]string, error) {
+	return nil, errRedisBreakerOpen
+}
+
+func (unavailableConn) MGet(...string) ([]string, error) {
+	return nil, errRedisBreakerOpen
+}
+
+func (unavailableConn) RPush(string, ...string) (int64, error) {
+	return 0, errRedisBreakerOpen
+}
+
+func (unavailableConn) LRange(string, int64, int64) ([]string, error) {
+	return nil, errRedisBreakerOpen
+}
+
+func (unavailableConn) ZAdd(string, float64, string, ...*godis.ZAddParams) (int64, error) {
+	return 0, errRedisBreakerOpen
+}
+
+func (unavailableConn) ZRangeByScore(string, float64, float64) ([]string, error) {
+	return nil, errRedisBreakerOpen
+}
+
+func (unavailableConn) ZRemRangeByScore(string, float64, float64) (int64, error) {
+	return 0, errRedisBreakerOpen
+}
+
+func (unavailableConn) Eval(string, int, ...string) (interface{}, error) {
+	return nil, errRedisBreakerOpen
+}
+
+func (unavailableConn) Close() error {
+	return nil
+}
@@ -0,0 +1,401 @@
+package gmsmPlugin
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/piaohao/godis"
+)
+
+// clusterSlots is the number of hash slots in a Redis Cluster.
+const clusterSlots = 16384
+
+// clusterKeySlot returns the hash slot of key: CRC16 (XMODEM) of the key, or of its {hash tag}
+// when it has a non-empty one, modulo 16384.
+func clusterKeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for b := 0; b < 8; b++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return int(crc) % clusterSlots
+}
+
+// parseClusterNodes checks the "host:port" entries of RedisClusterNodes.
+func parseClusterNodes(entries []string) ([]string, error) {
+	nodes := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		host, rawPort, err := net.SplitHostPort(strings.TrimSpace(entry))
+		if err != nil {
+			return nil, fmt.Errorf("invalid redisClusterNodes entry %q: %w", entry, err)
+		}
+		if _, err := strconv.Atoi(rawPort); err != nil || host == "" {
+			return nil, fmt.Errorf("invalid redisClusterNodes entry %q", entry)
+		}
+		nodes = append(nodes, net.JoinHostPort(host, rawPort))
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("redisClusterNodes must not be empty")
+	}
+	return nodes, nil
+}
+
+// clusterClient is a redisConn for Redis Cluster. Every command goes to a pooled connection on the
+// node owning its key's slot. MOVED replies update the slot map and ASK replies are followed once
+// with ASKING, both up to maxRedirects times. It is shared by all requests; Close does nothing.
+type clusterClient struct {
+	seeds        []string
+	option       godis.Option
+	config       godis.PoolConfig
+	maxRedirects int
+
+	mu    sync.RWMutex
+	pools map[string]*godis.Pool
+	slots [clusterSlots]string
+
+	logger *logger
+}
+
+// newClusterClient creates the client and loads the slot map from the first seed that answers.
+// Slots stay unassigned, and go to the first seed, until a node can be reached.
+func newClusterClient(seeds []string, option godis.Option, config godis.PoolConfig, maxRedirects int, logger *logger) *clusterClient {
+	c := &clusterClient{
+		seeds:        seeds,
+		option:       option,
+		config:       config,
+		maxRedirects: maxRedirects,
+		pools:        make(map[string]*godis.Pool),
+		logger:       logger,
+	}
+	if err := c.refreshSlots(); err != nil {
+		c.logger.Error("加载 redis cluster 槽位失败", logFields{"error": err})
+	}
+	return c
+}
+
+// refreshSlots replaces the slot map with the CLUSTER SLOTS reply of the first seed that answers.
+func (c *clusterClient) refreshSlots() error {
+	var lastErr error
+	for _, seed := range c.seeds {
+		r, err := c.pool(seed).GetResource()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		reply, err := redisDo(r, "CLUSTER", "SLOTS")
+		r.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		// 每一项为 [start, end, [master-host, master-port, ...], replicas...]
+		ranges, _ := reply.([]interface{})
+		c.mu.Lock()
+		for _, item := range ranges {
+			fields, _ := item.([]interface{})
+			if len(fields) < 3 {
+				continue
+			}
+			start, _ := fields[0].(int64)
+			end, _ := fields[1].(int64)
+			master, _ := fields[2].([]interface{})
+			if len(master) < 2 || start < 0 || end >= clusterSlots {
+				continue
+			}
+			host, _ := master[0].([]byte)
+			port, _ := master[1].(int64)
+			addr := net.JoinHostPort(string(host), strconv.FormatInt(port, 10))
+			for slot := start; slot <= end; slot++ {
+				c.slots[slot] = addr
+			}
+		}
+		c.mu.Unlock()
+		return nil
+	}
+	return lastErr
+}
+
+// pool returns the connection pool of the node at addr, creating it on first use.
+func (c *clusterClient) pool(addr string) *godis.Pool {
+	c.mu.RLock()
+	pool := c.pools[addr]
+	c.mu.RUnlock()
+	if pool != nil {
+		return pool
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pool = c.pools[addr]; pool == nil {
+		host, rawPort, _ := net.SplitHostPort(addr)
+		port, _ := strconv.Atoi(rawPort)
+		option := c.option
+		option.Host, option.Port = host, port
+		// cluster 只有数据库 0
+		option.Db = 0
+		config := c.config
+		pool = godis.NewPool(&config, &option)
+		c.pools[addr] = pool
+	}
+	return pool
+}
+
+// nodeFor returns the address of the node owning key's slot.
+func (c *clusterClient) nodeFor(key string) string {
+	c.mu.RLock()
+	addr := c.slots[clusterKeySlot(key)]
+	c.mu.RUnlock()
+	if addr == "" {
+		return c.seeds[0]
+	}
+	return addr
+}
+
+// do runs fn on a connection to the node owning key, following MOVED and ASK redirections.
+func (c *clusterClient) do(key string, fn func(r *godis.Redis) (interface{}, error)) (interface{}, error) {
+	addr := c.nodeFor(key)
+	asking := false
+	for redirects := 0; ; redirects++ {
+		r, err := c.pool(addr).GetResource()
+		if err != nil {
+			return nil, err
+		}
+		if asking {
+			if _, err := r.Asking(); err != nil {
+				r.Close()
+				return nil, err
+			}
+		}
+		reply, err := fn(r)
+		r.Close()
+
+		var moved *godis.MovedDataError
+		var ask *godis.AskDataError
+		switch {
+		case errors.As(err, &moved):
+			// 槽位已迁移, 更新映射后发往新节点
+			addr = net.JoinHostPort(moved.Host, strconv.Itoa(moved.Port))
+			asking = false
+			if moved.Slot >= 0 && moved.Slot < clusterSlots {
+				c.mu.Lock()
+				c.slots[moved.Slot] = addr
+				c.mu.Unlock()
+			}
+		case errors.As(err, &ask):
+			// 槽位迁移中, 只有这一次命令发往目标节点
+			addr = net.JoinHostPort(ask.Host, strconv.Itoa(ask.Port))
+			asking = true
+		default:
+			return reply, err
+		}
+		if redirects >= c.maxRedirects {
+			return nil, fmt.Errorf("too many redis cluster redirections: %w", err)
+		}
+	}
+}
+
+// Destroy closes the pools of all nodes.
+func (c *clusterClient) Destroy() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, pool := range c.pools {
+		pool.Destroy()
+	}
+}
+
+// Close implements redisConn; the client outlives the request.
+func (c *clusterClient) Close() error {
+	return nil
+}
+
+// evalKey is the key an EVAL is routed by: its first key, if it has one.
+func evalKey(keyCount int, params []string) string {
+	if keyCount > 0 && len(params) > 0 {
+		return params[0]
+	}
+	return ""
+}
+
+// firstKey is the key a multi-key command is routed by. All keys must share a slot.
+func firstKey(keys []string) string {
+	if len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
+
+func (c *clusterClient) Ping() (string, error) {
+	reply, err := c.do("", func(r *godis.Redis) (interface{}, error) { return r.Ping() })
+	s, _ := reply.(string)
+	return s, err
+}
+
+func (c *clusterClient) Get(key string) (string, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.Get(key) })
+	s, _ := reply.(string)
+	return s, err
+}
+
+func (c *clusterClient) Set(key, value string) (string, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.Set(key, value) })
+	s, _ := reply.(string)
+	return s, err
+}
+
+func (c *clusterClient) SetEx(key string, seconds int, value string) (string, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.SetEx(key, seconds, value) })
+	s, _ := reply.(string)
+	return s, err
+}
+
+func (c *clusterClient) SetNx(key, value string) (int64, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.SetNx(key, value) })
+	n, _ := reply.(int64)
+	return n, err
+}
+
+func (c *clusterClient) SetWithParams(key, value, nxxx string) (string, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.SetWithParams(key, value, nxxx) })
+	s, _ := reply.(string)
+	return s, err
+}
+
+func (c *clusterClient) SetWithParamsAndTime(key, value, nxxx, expx string, time int64) (string, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) {
+		return r.SetWithParamsAndTime(key, value, nxxx, expx, time)
+	})
+	s, _ := reply.(string)
+	return s, err
+}
+
+func (c *clusterClient) SetBitWithBool(key string, offset int64, value bool) (bool, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.SetBitWithBool(key, offset, value) })
+	b, _ := reply.(bool)
+	return b, err
+}
+
+func (c *clusterClient) Incr(key string) (int64, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.Incr(key) })
+	n, _ := reply.(int64)
+	return n, err
+}
+
+func (c *clusterClient) Expire(key string, seconds int) (int64, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.Expire(key, seconds) })
+	n, _ := reply.(int64)
+	return n, err
+}
+
+func (c *clusterClient) Del(keys ...string) (int64, error) {
+	reply, err := c.do(firstKey(keys), func(r *godis.Redis) (interface{}, error) { return r.Del(keys...) })
+	n, _ := reply.(int64)
+	return n, err
+}
+
+func (c *clusterClient) HGet(key, field string) (string, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.HGet(key, field) })
+	s, _ := reply.(string)
+	return s, err
+}
+
+func (c *clusterClient) HSet(key, field, value string) (int64, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.HSet(key, field, value) })
+	n, _ := reply.(int64)
+	return n, err
+}
+
+func (c *clusterClient) HMSet(key string, hash map[string]string) (string, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.HMSet(key, hash) })
+	s, _ := reply.(string)
+	return s, err
+}
+
+func (c *clusterClient) HGetAll(key string) (map[string]string, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.HGetAll(key) })
+	hash, _ := reply.(map[string]string)
+	return hash, err
+}
+
+func (c *clusterClient) SAdd(key string, members ...string) (int64, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.SAdd(key, members...) })
+	n, _ := reply.(int64)
+	return n, err
+}
+
+func (c *clusterClient) SIsMember(key, member string) (bool, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.SIsMember(key, member) })
+	b, _ := reply.(bool)
+	return b, err
+}
+
+func (c *clusterClient) SInter(keys ...string) ([]string, error) {
+	reply, err := c.do(firstKey(keys), func(r *godis.Redis) (interface{}, error) { return r.SInter(keys...) })
+	members, _ := reply.([]string)
+	return members, err
+}
+
+// MGet reads the keys one by one, since they may live in different slots.
+func (c *clusterClient) MGet(keys ...string) ([]string, error) {
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		value, err := c.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (c *clusterClient) RPush(key string, members ...string) (int64, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.RPush(key, members...) })
+	n, _ := reply.(int64)
+	return n, err
+}
+
+func (c *clusterClient) LRange(key string, start, stop int64) ([]string, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.LRange(key, start, stop) })
+	items, _ := reply.([]string)
+	return items, err
+}
+
+func (c *clusterClient) ZAdd(key string, score float64, member string, params ...*godis.ZAddParams) (int64, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.ZAdd(key, score, member, params...) })
+	n, _ := reply.(int64)
+	return n, err
+}
+
+func (c *clusterClient) ZRangeByScore(key string, min, max float64) ([]string, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.ZRangeByScore(key, min, max) })
+	members, _ := reply.([]string)
+	return members, err
+}
+
+func (c *clusterClient) ZRemRangeByScore(key string, min, max float64) (int64, error) {
+	reply, err := c.do(key, func(r *godis.Redis) (interface{}, error) { return r.ZRemRangeByScore(key, min, max) })
+	n, _ := reply.(int64)
+	return n, err
+}
+
+func (c *clusterClient) Eval(script string, keyCount int, params ...string) (interface{}, error) {
+	return c.do(evalKey(keyCount, params), func(r *godis.Redis) (interface{}, error) {
+		return r.Eval(script, keyCount, params...)
+	})
+}
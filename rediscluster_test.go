@@ -0,0 +1,381 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/piaohao/godis"
+)
+
+func TestClusterKeySlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"", 0},
+		{"123456789", 12739},
+		{"foo", 12182},
+		{"bar", 5061},
+		{"{user1000}.following", clusterKeySlot("user1000")},
+		{"{user1000}.followers", clusterKeySlot("user1000")},
+		{"foo{{bar}}", clusterKeySlot("{bar")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := clusterKeySlot(tt.key); got != tt.want {
+				t.Errorf("clusterKeySlot(%q) = %d, want %d", tt.key, got, tt.want)
+			}
+		})
+	}
+	// 空的 {} 不是 hash tag, 按整个 key 计算
+	if clusterKeySlot("{}.foo") == clusterKeySlot("") {
+		t.Error("an empty hash tag was used as the key")
+	}
+}
+
+func TestParseClusterNodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []string
+		wantErr bool
+	}{
+		{"nodes", []string{"10.0.0.1:7000", " 10.0.0.2:7001 "}, []string{"10.0.0.1:7000", "10.0.0.2:7001"}, false},
+		{"IPv6", []string{"[::1]:7000"}, []string{"[::1]:7000"}, false},
+		{"missing port", []string{"10.0.0.1"}, nil, true},
+		{"bad port", []string{"10.0.0.1:redis"}, nil, true},
+		{"missing host", []string{":7000"}, nil, true},
+		{"empty", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseClusterNodes(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseClusterNodes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("parseClusterNodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeCluster is a Redis Cluster of fakeRedis shards. Each shard answers CLUSTER SLOTS, and a
+// keyed command for a slot it does not own with MOVED. A migrating slot is answered with ASK by its
+// owner for keys it does not have, and served by the target after ASKING. An EVAL over keys in
+// different slots gets CROSSSLOT.
+type fakeCluster struct {
+	nodes []*fakeRedis
+
+	mu        sync.Mutex
+	owners    [clusterSlots]int
+	migrating map[int]int
+}
+
+// newFakeCluster starts shards nodes splitting the slots evenly.
+func newFakeCluster(t *testing.T, shards int) *fakeCluster {
+	t.Helper()
+	c := &fakeCluster{migrating: make(map[int]int)}
+	for slot := range c.owners {
+		c.owners[slot] = slot * shards / clusterSlots
+	}
+	for i := 0; i < shards; i++ {
+		node := newFakeRedis(t)
+		i := i
+		node.intercept = func(args []string, asking bool) interface{} { return c.intercept(i, args, asking) }
+		c.nodes = append(c.nodes, node)
+	}
+	return c
+}
+
+// commandKey returns the key a command is routed by, if it has one.
+func commandKey(args []string) (string, bool) {
+	switch strings.ToUpper(args[0]) {
+	case "PING", "AUTH", "SELECT", "QUIT", "ASKING", "CLUSTER":
+		return "", false
+	case "EVAL":
+		if len(args) > 3 && args[2] != "0" {
+			return args[3], true
+		}
+		return "", false
+	}
+	if len(args) < 2 {
+		return "", false
+	}
+	return args[1], true
+}
+
+func (c *fakeCluster) intercept(node int, args []string, asking bool) interface{} {
+	if strings.EqualFold(args[0], "CLUSTER") {
+		return c.slotsReply()
+	}
+	key, ok := commandKey(args)
+	if !ok {
+		return nil
+	}
+	slot := clusterKeySlot(key)
+	if strings.EqualFold(args[0], "EVAL") {
+		n, _ := strconv.Atoi(args[2])
+		for _, other := range args[3 : 3+n] {
+			if clusterKeySlot(other) != slot {
+				return errors.New("CROSSSLOT Keys in request don't hash to the same slot")
+			}
+		}
+	}
+	c.mu.Lock()
+	owner := c.owners[slot]
+	target, migrating := c.migrating[slot]
+	c.mu.Unlock()
+
+	switch {
+	case owner == node && migrating:
+		if _, ok := c.nodes[node].get(0, key); !ok {
+			return errors.New("ASK " + strconv.Itoa(slot) + " " + c.nodes[target].addr())
+		}
+		return nil
+	case owner == node, migrating && target == node && asking:
+		return nil
+	}
+	return errors.New("MOVED " + strconv.Itoa(slot) + " " + c.nodes[owner].addr())
+}
+
+// slotsReply is the CLUSTER SLOTS reply for the current owners.
+func (c *fakeCluster) slotsReply() []interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var reply []interface{}
+	for start := 0; start < clusterSlots; {
+		end := start
+		for end+1 < clusterSlots && c.owners[end+1] == c.owners[start] {
+			end++
+		}
+		host, port, _ := net.SplitHostPort(c.nodes[c.owners[start]].addr())
+		n, _ := strconv.Atoi(port)
+		reply = append(reply, []interface{}{int64(start), int64(end), []interface{}{[]byte(host), int64(n)}})
+		start = end + 1
+	}
+	return reply
+}
+
+// owner returns the index of the shard owning key.
+func (c *fakeCluster) owner(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.owners[clusterKeySlot(key)]
+}
+
+// move reassigns the slot of key to node, taking the key's value along.
+func (c *fakeCluster) move(key string, node int) {
+	from := c.owner(key)
+	if value, ok := c.nodes[from].get(0, key); ok {
+		c.nodes[node].set(0, key, value)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.owners[clusterKeySlot(key)] = node
+}
+
+func (c *fakeCluster) addrs() []string {
+	addrs := make([]string, len(c.nodes))
+	for i, node := range c.nodes {
+		addrs[i] = node.addr()
+	}
+	return addrs
+}
+
+func newTestClusterClient(t *testing.T, c *fakeCluster, maxRedirects int) *clusterClient {
+	t.Helper()
+	option := c.nodes[0].option(0)
+	client := newClusterClient(c.addrs(), option, godis.PoolConfig{MaxTotal: 4}, maxRedirects, newLogger(io.Discard, "error"))
+	t.Cleanup(client.Destroy)
+	return client
+}
+
+func TestClusterClientRouting(t *testing.T) {
+	c := newFakeCluster(t, 3)
+	client := newTestClusterClient(t, c, 3)
+
+	used := make(map[int]bool)
+	for i := 0; i < 30; i++ {
+		key := "key-" + strconv.Itoa(i)
+		if _, err := client.Set(key, "v"); err != nil {
+			t.Fatal(err)
+		}
+		owner := c.owner(key)
+		used[owner] = true
+		for node := range c.nodes {
+			if _, ok := c.nodes[node].get(0, key); ok != (node == owner) {
+				t.Errorf("%s on shard %d = %v, want it only on shard %d", key, node, ok, owner)
+			}
+		}
+	}
+	if len(used) != 3 {
+		t.Errorf("keys landed on %d shards, want all 3", len(used))
+	}
+}
+
+func TestClusterClientRedirects(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRedirects int
+		// reshard changes the cluster after the client has loaded the slot map
+		reshard func(c *fakeCluster, key string) int
+		// wantSlotMoved is whether the client should remember the new owner
+		wantSlotMoved bool
+		wantErr       bool
+	}{
+		{"MOVED", 3, func(c *fakeCluster, key string) int {
+			to := (c.owner(key) + 1) % len(c.nodes)
+			c.move(key, to)
+			return to
+		}, true, false},
+		{"ASK", 3, func(c *fakeCluster, key string) int {
+			from, to := c.owner(key), (c.owner(key)+2)%len(c.nodes)
+			value, _ := c.nodes[from].get(0, key)
+			c.nodes[to].set(0, key, value)
+			c.nodes[from].exec(0, "DEL", []string{key})
+			c.mu.Lock()
+			c.migrating[clusterKeySlot(key)] = to
+			c.mu.Unlock()
+			return from
+		}, false, false},
+		{"MOVED beyond the limit", 0, func(c *fakeCluster, key string) int {
+			to := (c.owner(key) + 1) % len(c.nodes)
+			c.move(key, to)
+			return to
+		}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeCluster(t, 3)
+			client := newTestClusterClient(t, c, tt.maxRedirects)
+			const key = "order:42"
+			if _, err := client.Set(key, "paid"); err != nil {
+				t.Fatal(err)
+			}
+			before := client.nodeFor(key)
+
+			owner := tt.reshard(c, key)
+			got, err := client.Get(key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != "paid" {
+				t.Errorf("Get() = %q, want paid", got)
+			}
+
+			want := before
+			if tt.wantSlotMoved {
+				want = c.nodes[owner].addr()
+			}
+			if after := client.nodeFor(key); after != want {
+				t.Errorf("slot of %s maps to %s, want %s", key, after, want)
+			}
+		})
+	}
+}
+
+// With RedisClusterEnabled the request hash is stored on the shard owning its key.
+func TestServeHTTPRedisCluster(t *testing.T) {
+	c := newFakeCluster(t, 3)
+	p := newTestPlugin(t, c.nodes[0], func(config *Config) {
+		config.RedisClusterEnabled = true
+		config.RedisClusterNodes = c.addrs()
+	})
+
+	for _, body := range []string{"a", "b", "c", "d", "e", "f"} {
+		rw := httptest.NewRecorder()
+		p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		if rw.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rw.Code, rw.Body)
+		}
+		key := p.hashKey(httptest.NewRequest(http.MethodPost, "/", nil), hex.EncodeToString(sm3Sum([]byte(body))))
+		if _, ok := c.nodes[c.owner(key)].get(0, key); !ok {
+			t.Errorf("hash of %q was not stored on shard %d", body, c.owner(key))
+		}
+	}
+}
+
+// fakeAppendChain is the fakeRedis stand-in for appendChainScript.
+func fakeAppendChain(call func(args ...string) interface{}, keys, argv []string) interface{} {
+	head, _ := call("GET", keys[0]).([]byte)
+	if string(head) != argv[0] {
+		return int64(0)
+	}
+	call("SET", keys[0], argv[1])
+	call("RPUSH", keys[1], argv[2])
+	return int64(1)
+}
+
+// The chain head and history share a slot, so the script moving both runs on a cluster.
+func TestServeHTTPRedisClusterHashChain(t *testing.T) {
+	c := newFakeCluster(t, 3)
+	for _, node := range c.nodes {
+		node.script(appendChainScript, fakeAppendChain)
+	}
+	p := newTestPlugin(t, c.nodes[0], func(config *Config) {
+		config.RedisClusterEnabled = true
+		config.RedisClusterNodes = c.addrs()
+		config.HashChainingEnabled = true
+		config.ForwardToNext = false
+	})
+
+	var previous string
+	for i, body := range []string{"a", "b", "c"} {
+		rw := httptest.NewRecorder()
+		p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		if rw.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d: %s", i+1, rw.Code, rw.Body)
+		}
+		var response struct {
+			Result       string `json:"result"`
+			PreviousHash string `json:"previousHash"`
+		}
+		if err := json.Unmarshal(rw.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && response.PreviousHash != previous {
+			t.Errorf("request %d: previousHash = %s, want %s", i+1, response.PreviousHash, previous)
+		}
+		previous = response.Result
+	}
+}
+
+func TestChainRedisKeyCluster(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{"", false},
+		{"{audit}:chain:head", false},
+		{"audit:chain:head", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			config := CreateConfig()
+			config.RedisClusterEnabled = true
+			config.RedisClusterNodes = []string{"127.0.0.1:1"}
+			config.HashChainingEnabled = true
+			config.ChainRedisKey = tt.key
+			config.LogLevel = "error"
+
+			handler, err := New(context.Background(), http.NotFoundHandler(), config, "test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				handler.(*MyPlugin).Close()
+			}
+		})
+	}
+}
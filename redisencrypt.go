@@ -0,0 +1,156 @@
+package gmsmPlugin
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+// redisCipher encrypts values stored in redis with SM4-GCM. An encrypted value is
+// base64(nonce || ciphertext || tag) with a fresh 12-byte nonce per write.
+type redisCipher struct {
+	key []byte
+	// strict turns a value that does not decrypt into an error instead of a logged warning.
+	strict bool
+	logger *logger
+}
+
+func newRedisCipher(key []byte, strict bool, logger *logger) (*redisCipher, error) {
+	c := &redisCipher{key: key, strict: strict, logger: logger}
+	// 先建一次, 密钥有误时启动即失败
+	if _, err := c.aead(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// aead returns a new SM4-GCM instance. sm4.Sm4Cipher encrypts through scratch buffers held in
+// the cipher, so an instance must not be shared by concurrent requests.
+func (c *redisCipher) aead() (cipher.AEAD, error) {
+	block, err := sm4.NewCipher(c.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (c *redisCipher) redisEncrypt(v string) (string, error) {
+	aead, err := c.aead()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(v), nil)), nil
+}
+
+// redisDecrypt decrypts a value written by redisEncrypt. A value that does not decrypt is taken
+// to be plaintext written before encryption was turned on: it is returned as is with a warning,
+// or rejected in strict mode. An empty string (a missing key) is returned unchanged.
+func (c *redisCipher) redisDecrypt(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+	aead, err := c.aead()
+	if err != nil {
+		return "", err
+	}
+	raw, err := base64.StdEncoding.DecodeString(v)
+	if err == nil && len(raw) >= aead.NonceSize() {
+		nonce, sealed := raw[:aead.NonceSize()], raw[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, sealed, nil); err == nil {
+			return string(plaintext), nil
+		}
+	}
+	if c.strict {
+		return "", errors.New("redis value is not encrypted or fails authentication")
+	}
+	c.logger.Warn("redis 中存在未加密或无法解密的值, 按明文使用", nil)
+	return v, nil
+}
+
+// encryptedConn encrypts the values written by Set, SetEx, SetNx, HSet and HMSet and decrypts
+// those read by Get, HGet and HGetAll. Keys, hash fields, set and sorted set members and Lua
+// script arguments stay in clear: they are looked up or compared inside redis.
+type encryptedConn struct {
+	redisConn
+	cipher *redisCipher
+}
+
+func (c *encryptedConn) Set(key, value string) (string, error) {
+	v, err := c.cipher.redisEncrypt(value)
+	if err != nil {
+		return "", err
+	}
+	return c.redisConn.Set(key, v)
+}
+
+func (c *encryptedConn) SetEx(key string, seconds int, value string) (string, error) {
+	v, err := c.cipher.redisEncrypt(value)
+	if err != nil {
+		return "", err
+	}
+	return c.redisConn.SetEx(key, seconds, v)
+}
+
+func (c *encryptedConn) SetNx(key, value string) (int64, error) {
+	v, err := c.cipher.redisEncrypt(value)
+	if err != nil {
+		return 0, err
+	}
+	return c.redisConn.SetNx(key, v)
+}
+
+func (c *encryptedConn) HSet(key, field, value string) (int64, error) {
+	v, err := c.cipher.redisEncrypt(value)
+	if err != nil {
+		return 0, err
+	}
+	return c.redisConn.HSet(key, field, v)
+}
+
+func (c *encryptedConn) HMSet(key string, hash map[string]string) (string, error) {
+	encrypted := make(map[string]string, len(hash))
+	for field, value := range hash {
+		v, err := c.cipher.redisEncrypt(value)
+		if err != nil {
+			return "", err
+		}
+		encrypted[field] = v
+	}
+	return c.redisConn.HMSet(key, encrypted)
+}
+
+func (c *encryptedConn) Get(key string) (string, error) {
+	v, err := c.redisConn.Get(key)
+	if err != nil {
+		return "", err
+	}
+	return c.cipher.redisDecrypt(v)
+}
+
+func (c *encryptedConn) HGet(key, field string) (string, error) {
+	v, err := c.redisConn.HGet(key, field)
+	if err != nil {
+		return "", err
+	}
+	return c.cipher.redisDecrypt(v)
+}
+
+func (c *encryptedConn) HGetAll(key string) (map[string]string, error) {
+	hash, err := c.redisConn.HGetAll(key)
+	if err != nil {
+		return nil, err
+	}
+	for field, value := range hash {
+		if hash[field], err = c.cipher.redisDecrypt(value); err != nil {
+			return nil, err
+		}
+	}
+	return hash, nil
+}
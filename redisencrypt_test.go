@@ -0,0 +1,99 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRedisDecrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 16)
+
+	tests := []struct {
+		name    string
+		strict  bool
+		value   func(c *redisCipher) string
+		want    string
+		wantErr bool
+		wantLog bool
+	}{
+		{
+			name:   "encrypted value",
+			strict: true,
+			value: func(c *redisCipher) string {
+				v, _ := c.redisEncrypt("hello")
+				return v
+			},
+			want: "hello",
+		},
+		{
+			name:  "missing key",
+			value: func(*redisCipher) string { return "" },
+			want:  "",
+		},
+		{
+			name:    "plaintext in non-strict mode",
+			value:   func(*redisCipher) string { return "legacy-plaintext" },
+			want:    "legacy-plaintext",
+			wantLog: true,
+		},
+		{
+			name:    "plaintext in strict mode",
+			strict:  true,
+			value:   func(*redisCipher) string { return "legacy-plaintext" },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			c, err := newRedisCipher(key, tt.strict, newLogger(&out, "info"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.redisDecrypt(tt.value(c))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("redisDecrypt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("redisDecrypt() = %q, want %q", got, tt.want)
+			}
+			if logged := strings.Contains(out.String(), `"level":"warn"`); logged != tt.wantLog {
+				t.Errorf("warning logged = %v, want %v: %s", logged, tt.wantLog, out.String())
+			}
+		})
+	}
+}
+
+// One cipher is shared by every request; run with -race.
+func TestRedisCipherConcurrent(t *testing.T) {
+	c, err := newRedisCipher(bytes.Repeat([]byte{0x42}, 16), true, newLogger(io.Discard, "error"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				want := fmt.Sprintf("value-%d-%d", i, j)
+				v, err := c.redisEncrypt(want)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got, err := c.redisDecrypt(v); err != nil || got != want {
+					t.Errorf("redisDecrypt(redisEncrypt(%q)) = %q, %v", want, got, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
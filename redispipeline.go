@@ -0,0 +1,146 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/piaohao/godis"
+)
+
+// setNXScript sets KEYS[1] if it does not exist, expiring it after ARGV[1] seconds when that is
+// positive. It returns "OK" when the key was set. godis.Pipeline only queues multi-key commands,
+// so single-key writes go through EVAL.
+const setNXScript = `
+local ok
+if tonumber(ARGV[1]) > 0 then
+	ok = redis.call('SET', KEYS[1], '1', 'NX', 'EX', ARGV[1])
+else
+	ok = redis.call('SET', KEYS[1], '1', 'NX')
+end
+if ok then
+	return 'OK'
+end
+return ''
+`
+
+// errPipelineClosed is returned for commands queued after the pipeline has stopped.
+var errPipelineClosed = errors.New("redis pipeline closed")
+
+// pipelineResult is the reply to one queued command.
+type pipelineResult struct {
+	reply string
+	err   error
+}
+
+// pipelinedCommand is a queued EVAL together with the channel its result is sent on.
+type pipelinedCommand struct {
+	script string
+	keys   int
+	params []string
+	result chan pipelineResult
+}
+
+// redisPipeline batches commands from concurrent requests and sends them on its own connection
+// as one godis.Pipeline every flush interval.
+type redisPipeline struct {
+	option godis.Option
+	r      *godis.Redis
+	logger *logger
+
+	mu      sync.Mutex
+	pending []*pipelinedCommand
+	closed  bool
+}
+
+// newRedisPipeline connects the pipeline and flushes it every interval until ctx is done.
+func newRedisPipeline(ctx context.Context, option godis.Option, interval time.Duration, logger *logger) *redisPipeline {
+	p := &redisPipeline{option: option, r: newRedis(option, logger), logger: logger}
+	go func() {
+		defer func() { p.r.Close() }()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				// 停止接收新命令, 已排队的命令最后发送一次
+				p.mu.Lock()
+				p.closed = true
+				p.mu.Unlock()
+				p.flush()
+				return
+			case <-ticker.C:
+				p.flush()
+			}
+		}
+	}()
+	return p
+}
+
+// eval queues an EVAL and waits for the flush that sends it.
+func (p *redisPipeline) eval(script string, keys int, params ...string) (string, error) {
+	cmd := &pipelinedCommand{script: script, keys: keys, params: params, result: make(chan pipelineResult, 1)}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return "", errPipelineClosed
+	}
+	p.pending = append(p.pending, cmd)
+	p.mu.Unlock()
+
+	res := <-cmd.result
+	return res.reply, res.err
+}
+
+// setNX sets key if it does not exist, with a TTL when ttl is positive, and reports whether it was set.
+func (p *redisPipeline) setNX(key string, ttl int) (bool, error) {
+	reply, err := p.eval(setNXScript, 1, key, strconv.Itoa(ttl))
+	return reply == "OK", err
+}
+
+// flush sends the queued commands in one round trip and hands each its reply.
+func (p *redisPipeline) flush() {
+	p.mu.Lock()
+	batch := p.pending
+	p.pending = nil
+	p.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	pipeline := p.r.Pipelined()
+	responses := make([]*godis.Response, len(batch))
+	for i, cmd := range batch {
+		resp, err := pipeline.Eval(cmd.script, cmd.keys, cmd.params...)
+		if err != nil {
+			p.fail(batch, err)
+			return
+		}
+		responses[i] = resp
+	}
+	if err := pipeline.Sync(); err != nil {
+		p.fail(batch, err)
+		return
+	}
+
+	for i, cmd := range batch {
+		reply, err := responses[i].Get()
+		s, _ := reply.(string)
+		cmd.result <- pipelineResult{reply: s, err: err}
+	}
+}
+
+// fail reports err to every command in batch. The connection is replaced so that the next flush
+// does not read replies left over from this batch.
+func (p *redisPipeline) fail(batch []*pipelinedCommand, err error) {
+	p.logger.Error("redis pipeline 发送失败", logFields{"error": err})
+	p.r.Close()
+	p.r = newRedis(p.option, p.logger)
+	for _, cmd := range batch {
+		cmd.result <- pipelineResult{err: err}
+	}
+}
@@ -0,0 +1,142 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/piaohao/godis"
+)
+
+// fakeSetNX is the fakeRedis stand-in for setNXScript.
+func fakeSetNX(call func(args ...string) interface{}, keys, argv []string) interface{} {
+	args := []string{"SET", keys[0], "1", "NX"}
+	if argv[0] != "0" {
+		args = append(args, "EX", argv[0])
+	}
+	if call(args...) == "OK" {
+		return []byte("OK")
+	}
+	return []byte("")
+}
+
+func newTestPipeline(t testing.TB, f *fakeRedis) (*redisPipeline, context.CancelFunc) {
+	f.script(setNXScript, fakeSetNX)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return newRedisPipeline(ctx, f.option(0), time.Millisecond, newLogger(io.Discard, "error")), cancel
+}
+
+func TestRedisPipelineSetNX(t *testing.T) {
+	f := newFakeRedis(t)
+	p, cancel := newTestPipeline(t, f)
+
+	// 并发写入同一个 key, 只有一个成功
+	var wg sync.WaitGroup
+	var set atomic.Int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := p.setNX("same", 60)
+			if err != nil {
+				t.Error(err)
+			}
+			if ok {
+				set.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if set.Load() != 1 {
+		t.Errorf("%d concurrent setNX calls on one key succeeded, want 1", set.Load())
+	}
+
+	tests := []struct {
+		key  string
+		ttl  int
+		want bool
+	}{
+		{"fresh", 60, true},
+		{"fresh", 60, false},
+		{"no-ttl", 0, true},
+		{"same", 0, false},
+	}
+	for _, tt := range tests {
+		if got, err := p.setNX(tt.key, tt.ttl); err != nil || got != tt.want {
+			t.Errorf("setNX(%q, %d) = %v, %v, want %v", tt.key, tt.ttl, got, err, tt.want)
+		}
+	}
+
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for {
+		_, err := p.setNX("late", 0)
+		if err == errPipelineClosed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("setNX after shutdown = %v, want errPipelineClosed", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// pipelineBenchConcurrency is the number of concurrent requests in the pipeline benchmarks.
+const pipelineBenchConcurrency = 1000
+
+// pipelineBenchLatency is the simulated redis round-trip time.
+const pipelineBenchLatency = time.Millisecond
+
+// benchmarkDedupWrites runs setNX for unique keys from pipelineBenchConcurrency goroutines.
+func benchmarkDedupWrites(b *testing.B, setNX func(key string) error) {
+	b.SetParallelism((pipelineBenchConcurrency + runtime.GOMAXPROCS(0) - 1) / runtime.GOMAXPROCS(0))
+	var n atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := setNX(fmt.Sprint("key-", n.Add(1))); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkSerial is the dedup write without the pipeline: one SET NX round trip per request on
+// a connection borrowed from a pool of the default RedisPoolMaxActive size.
+func BenchmarkSerial(b *testing.B) {
+	f := newFakeRedis(b)
+	f.latency = pipelineBenchLatency
+	option := f.option(0)
+	pool := godis.NewPool(&godis.PoolConfig{MaxTotal: CreateConfig().RedisPoolMaxActive}, &option)
+	defer pool.Destroy()
+
+	benchmarkDedupWrites(b, func(key string) error {
+		r, err := pool.GetResource()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		_, err = r.SetWithParamsAndTime(key, "1", "NX", "EX", 60)
+		return err
+	})
+}
+
+// BenchmarkPipeline is the same write through redisPipeline, which sends the commands queued by
+// all concurrent requests in one round trip per flush. Compare its ns/op with BenchmarkSerial.
+func BenchmarkPipeline(b *testing.B) {
+	f := newFakeRedis(b)
+	f.latency = pipelineBenchLatency
+	p, _ := newTestPipeline(b, f)
+
+	benchmarkDedupWrites(b, func(key string) error {
+		_, err := p.setNX(key, 60)
+		return err
+	})
+}
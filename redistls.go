@@ -0,0 +1,73 @@
+package gmsmPlugin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// loadPEM returns value itself when it holds PEM data, otherwise reads the file it names.
+func loadPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
+
+// newRedisTLSConfig builds the TLS configuration for Redis connections. Without a CA
+// certificate the system roots are used; the client certificate is optional.
+func newRedisTLSConfig(caCert, clientCert, clientKey string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caCert != "" {
+		data, err := loadPEM(caCert)
+		if err != nil {
+			return nil, err
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(data) {
+			return nil, errors.New("no certificates found")
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	if clientCert != "" || clientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// redisTLSDialer returns a godis.Option Dial function that wraps the TCP connection
+// with tls.Client and completes the handshake within timeout.
+func redisTLSDialer(tlsConfig *tls.Config) func(addr string, timeout time.Duration) (net.Conn, error) {
+	return func(addr string, timeout time.Duration) (net.Conn, error) {
+		rawConn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		// sentinel 切换后地址会变, 按实际连接的主机校验证书
+		config := tlsConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		conn := tls.Client(rawConn, config)
+		if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		if err := conn.Handshake(); err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+}
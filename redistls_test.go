@@ -0,0 +1,203 @@
+package gmsmPlugin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/piaohao/godis"
+)
+
+// testTLSCert is a certificate with its PEM encodings.
+type testTLSCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM string
+	keyPEM  string
+}
+
+// newTestTLSCert issues template with a fresh P-256 key, signed by parent or self-signed when
+// parent is nil.
+func newTestTLSCert(t *testing.T, template *x509.Certificate, parent *testTLSCert) *testTLSCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore, template.NotAfter = time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	issuer, issuerKey := template, key
+	if parent != nil {
+		issuer, issuerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testTLSCert{
+		cert:    cert,
+		key:     key,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+func newTestTLSCA(t *testing.T, name string) *testTLSCert {
+	return newTestTLSCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+}
+
+// newTLSFakeRedis starts a fakeRedis behind TLS with a certificate for 127.0.0.1 issued by ca.
+// When clientCA is set the server requires a client certificate issued by it.
+func newTLSFakeRedis(t *testing.T, ca, clientCA *testTLSCert) *fakeRedis {
+	t.Helper()
+	server := newTestTLSCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "redis"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	serverCert, err := tls.X509KeyPair([]byte(server.certPEM), []byte(server.keyPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{serverCert}}
+	if clientCA != nil {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = x509.NewCertPool()
+		config.ClientCAs.AddCert(clientCA.cert)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serveFakeRedis(t, tls.NewListener(listener, config))
+}
+
+func TestRedisTLSDialer(t *testing.T) {
+	ca, otherCA := newTestTLSCA(t, "redis-ca"), newTestTLSCA(t, "other-ca")
+	client := newTestTLSCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "gateway"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, []byte(ca.certPEM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		caCert     string
+		clientCert *testTLSCert
+		mutual     bool
+		wantErr    bool
+	}{
+		{"CA as a path", caPath, nil, false, false},
+		{"CA inline", ca.certPEM, nil, false, false},
+		{"client certificate", ca.certPEM, client, true, false},
+		{"client certificate missing", ca.certPEM, nil, true, true},
+		{"other CA", otherCA.certPEM, nil, false, true},
+		{"system roots", "", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clientCA *testTLSCert
+			if tt.mutual {
+				clientCA = ca
+			}
+			f := newTLSFakeRedis(t, ca, clientCA)
+
+			var certPEM, keyPEM string
+			if tt.clientCert != nil {
+				certPEM, keyPEM = tt.clientCert.certPEM, tt.clientCert.keyPEM
+			}
+			tlsConfig, err := newRedisTLSConfig(tt.caCert, certPEM, keyPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+			option := f.option(0)
+			option.Dial = redisTLSDialer(tlsConfig)
+			r := godis.NewRedis(&option)
+			defer r.Close()
+
+			// TLS 1.3 的客户端证书错误要到第一次读才会暴露
+			err = r.Connect()
+			if err == nil {
+				_, err = r.Set("k", "v")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if v, ok := f.get(0, "k"); !tt.wantErr && (!ok || v != "v") {
+				t.Error("SET did not reach the server")
+			}
+		})
+	}
+}
+
+func TestNewRedisTLSConfigInvalid(t *testing.T) {
+	ca := newTestTLSCA(t, "redis-ca")
+	tests := []struct {
+		name                  string
+		caCert, cert, certKey string
+	}{
+		{"CA file missing", filepath.Join(t.TempDir(), "missing.pem"), "", ""},
+		{"CA without certificates", "-----BEGIN NOTHING-----\n-----END NOTHING-----\n", "", ""},
+		{"certificate without key", "", ca.certPEM, ""},
+		{"key not matching", "", ca.certPEM, newTestTLSCA(t, "other").keyPEM},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newRedisTLSConfig(tt.caCert, tt.cert, tt.certKey); err == nil {
+				t.Error("newRedisTLSConfig() succeeded")
+			}
+		})
+	}
+}
+
+// With RedisTLSEnabled the plugin stores request hashes over TLS.
+func TestServeHTTPRedisTLS(t *testing.T) {
+	ca := newTestTLSCA(t, "redis-ca")
+	f := newTLSFakeRedis(t, ca, nil)
+	p := newTestPlugin(t, f, func(c *Config) {
+		c.RedisTLSEnabled = true
+		c.RedisTLSCACert = ca.certPEM
+	})
+
+	body := "over tls"
+	rw := httptest.NewRecorder()
+	p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rw.Code, rw.Body)
+	}
+	key := p.hashKey(httptest.NewRequest(http.MethodPost, "/", nil), hex.EncodeToString(sm3Sum([]byte(body))))
+	if _, ok := f.get(0, key); !ok {
+		t.Errorf("hash %s was not stored over TLS", key)
+	}
+}
@@ -0,0 +1,181 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+// reloadableFields are the Config fields a dynamic config file may change while running. Any
+// other field that differs from the running configuration is ignored with a warning.
+var reloadableFields = map[string]bool{
+	"RedisPassword":     true,
+	"SM4Key":            true,
+	"SM3HMACKey":        true,
+	"SM4CBCMACKey":      true,
+	"HMACKeys":          true,
+	"HMACCurrentKeyID":  true,
+	"HMACRetiredKeyIDs": true,
+}
+
+// runtimeKeys are the keys a reload may replace. ServeHTTP never holds a lock: the reload stores
+// a new runtimeKeys and readers load the pointer, so they see either every old key or every new one.
+type runtimeKeys struct {
+	sm4Key            []byte
+	sm4CBCMACKey      []byte
+	sm3HMACKey        []byte
+	hmacKeys          map[string][]byte
+	hmacCurrentKeyID  string
+	hmacRetiredKeyIDs map[string]bool
+}
+
+// copyConfig deep-copies c through JSON, so the copy shares no maps or slices with c. Empty
+// slices and maps come back nil, which keeps the comparison in reloadConfig stable.
+func copyConfig(c *Config) (*Config, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	out := &Config{}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// loadDynamicConfig reads the JSON file at path over a copy of current, so fields missing from
+// the file keep their running values.
+func loadDynamicConfig(path string, current *Config) (*Config, error) {
+	next, err := copyConfig(current)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// watchDynamicConfig reloads the config file every interval until ctx is done.
+func (p *MyPlugin) watchDynamicConfig(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.reloadConfig(path); err != nil {
+				p.logger.Error("重新加载配置失败", logFields{"error": err})
+			}
+		}
+	}
+}
+
+// reloadConfig applies the reloadable fields of the config file that changed. Keys are validated
+// first, so a bad file leaves the running configuration untouched. The new keys and pool are
+// swapped in without waiting for in-flight requests; the old pool is destroyed once the last
+// connection borrowed from it is returned.
+func (p *MyPlugin) reloadConfig(path string) error {
+	next, err := loadDynamicConfig(path, &p.config)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	nextValue, currentValue := reflect.ValueOf(next).Elem(), reflect.ValueOf(&p.config).Elem()
+	for i := 0; i < nextValue.NumField(); i++ {
+		name := nextValue.Type().Field(i).Name
+		if reflect.DeepEqual(nextValue.Field(i).Interface(), currentValue.Field(i).Interface()) {
+			continue
+		}
+		if !reloadableFields[name] {
+			p.logger.Warn("配置项不能在运行时修改, 已忽略", logFields{"field": name})
+			nextValue.Field(i).Set(currentValue.Field(i))
+			continue
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	// 启动时配置的密钥只能替换, 不能删除
+	if (next.SM4Key == "" && p.config.SM4Key != "") || (next.SM3HMACKey == "" && p.config.SM3HMACKey != "") ||
+		(next.SM4CBCMACKey == "" && p.config.SM4CBCMACKey != "") {
+		return fmt.Errorf("sm4Key, sm3HMACKey and sm4CBCMACKey cannot be removed at runtime")
+	}
+
+	var sm4Key []byte
+	if next.SM4Key != "" {
+		if next.SM4Passphrase != "" {
+			p.logger.Warn("配置了 sm4Passphrase, sm4Key 的修改不生效", nil)
+		} else if sm4Key, err = hex.DecodeString(next.SM4Key); err != nil || len(sm4Key) != 16 {
+			return fmt.Errorf("sm4Key must be a 16-byte hex string")
+		}
+	}
+	var sm3HMACKey []byte
+	if next.SM3HMACKey != "" {
+		if sm3HMACKey, err = hex.DecodeString(next.SM3HMACKey); err != nil || len(sm3HMACKey) < 16 {
+			return fmt.Errorf("sm3HMACKey must be a hex string of at least 16 bytes")
+		}
+	}
+	var sm4CBCMACKey []byte
+	if next.SM4CBCMACKey != "" {
+		if sm4CBCMACKey, err = hex.DecodeString(next.SM4CBCMACKey); err != nil || len(sm4CBCMACKey) != 16 {
+			return fmt.Errorf("sm4CBCMACKey must be a 16-byte hex string")
+		}
+	}
+	hmacKeys, err := parseHMACKeys(next.HMACKeys)
+	if err != nil {
+		return err
+	}
+	hmacRetiredKeyIDs := make(map[string]bool, len(next.HMACRetiredKeyIDs))
+	for _, id := range next.HMACRetiredKeyIDs {
+		hmacRetiredKeyIDs[id] = true
+	}
+	if p.hmacKeyRotation && !p.hmacVerify {
+		if _, ok := hmacKeys[next.HMACCurrentKeyID]; !ok || hmacRetiredKeyIDs[next.HMACCurrentKeyID] {
+			return fmt.Errorf("hmacCurrentKeyID must name a key in hmacKeys that is not retired")
+		}
+	}
+
+	var pool redisPool
+	if next.RedisPassword != p.config.RedisPassword {
+		if p.newPool != nil {
+			pool = p.newPool(next.RedisPassword)
+		} else {
+			// cluster 和 sentinel 的连接在启动时创建
+			p.logger.Warn("只有单节点 redis 支持运行时修改 redisPassword, 已忽略", nil)
+			next.RedisPassword = p.config.RedisPassword
+		}
+	}
+
+	keys := &runtimeKeys{
+		sm4Key:            sm4Key,
+		sm4CBCMACKey:      sm4CBCMACKey,
+		sm3HMACKey:        sm3HMACKey,
+		hmacKeys:          hmacKeys,
+		hmacCurrentKeyID:  next.HMACCurrentKeyID,
+		hmacRetiredKeyIDs: hmacRetiredKeyIDs,
+	}
+	if next.SM4Passphrase != "" {
+		keys.sm4Key = p.keys.Load().sm4Key
+	}
+	p.keys.Store(keys)
+	if pool != nil {
+		p.pool.Swap(newTrackedPool(pool)).retire()
+	}
+	p.config = *next
+	p.logger.Info("已重新加载配置", logFields{"path": path})
+	return nil
+}
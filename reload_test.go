@@ -0,0 +1,156 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/piaohao/godis"
+)
+
+// testPool is a pool that records whether it was destroyed.
+type testPool struct {
+	*godis.Pool
+	destroyed atomic.Bool
+}
+
+func (t *testPool) Destroy() {
+	t.destroyed.Store(true)
+	t.Pool.Destroy()
+}
+
+func newReloadPlugin(t *testing.T, f *fakeRedis) (*MyPlugin, *testPool, *[]*testPool) {
+	t.Helper()
+	option := f.option(0)
+	newTestPool := func() *testPool {
+		pool := &testPool{Pool: godis.NewPool(&godis.PoolConfig{MaxTotal: 4}, &option)}
+		t.Cleanup(pool.Pool.Destroy)
+		return pool
+	}
+	var created []*testPool
+	old := newTestPool()
+	p := &MyPlugin{
+		logger: newLogger(io.Discard, "error"),
+		config: Config{RedisPassword: "old", SM4Key: "0123456789abcdeffedcba9876543210"},
+		newPool: func(password string) redisPool {
+			pool := newTestPool()
+			created = append(created, pool)
+			return pool
+		},
+	}
+	p.keys.Store(&runtimeKeys{sm4Key: []byte("old-sm4-key-0000")})
+	p.pool.Store(newTrackedPool(old))
+	return p, old, &created
+}
+
+func writeDynamicConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dynamic.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// A reload must not wait for a request that still holds a connection from the old pool, and the
+// old pool must stay usable until that connection is returned.
+func TestReloadDoesNotWaitForRequests(t *testing.T) {
+	f := newFakeRedis(t)
+	p, old, created := newReloadPlugin(t, f)
+
+	conn, err := p.getConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeDynamicConfig(t, `{"redisPassword": "new", "sm4Key": "00112233445566778899aabbccddeeff"}`)
+	done := make(chan error, 1)
+	go func() { done <- p.reloadConfig(path) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reloadConfig blocked on a borrowed connection")
+	}
+
+	if len(*created) != 1 {
+		t.Fatalf("reload created %d pools, want 1", len(*created))
+	}
+	if want, _ := hex.DecodeString("00112233445566778899aabbccddeeff"); !bytes.Equal(p.keys.Load().sm4Key, want) {
+		t.Errorf("sm4Key = %x after reload, want %x", p.keys.Load().sm4Key, want)
+	}
+	if old.destroyed.Load() {
+		t.Fatal("old pool destroyed while a connection was still borrowed")
+	}
+	if _, err := redisDo(conn, "PING"); err != nil {
+		t.Errorf("borrowed connection failed after reload: %v", err)
+	}
+
+	conn.Close()
+	if !old.destroyed.Load() {
+		t.Error("old pool not destroyed after its last connection was returned")
+	}
+	conn.Close()
+
+	next, err := p.getConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer next.Close()
+	if got := next.(*pooledConn).pool.redisPool; got != (*created)[0] {
+		t.Errorf("connection after reload came from %p, want the new pool %p", got, (*created)[0])
+	}
+}
+
+func TestTrackedPoolRetire(t *testing.T) {
+	f := newFakeRedis(t)
+
+	tests := []struct {
+		name          string
+		borrow        int
+		wantDestroyed bool
+	}{
+		{"idle", 0, true},
+		{"one borrowed", 1, false},
+		{"two borrowed", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			option := f.option(0)
+			pool := &testPool{Pool: godis.NewPool(&godis.PoolConfig{MaxTotal: 4}, &option)}
+			tracked := newTrackedPool(pool)
+
+			var conns []*pooledConn
+			for i := 0; i < tt.borrow; i++ {
+				conn, ok, err := tracked.borrow()
+				if !ok || err != nil {
+					t.Fatalf("borrow() = %v, %v", ok, err)
+				}
+				conns = append(conns, conn)
+			}
+			tracked.retire()
+			if got := pool.destroyed.Load(); got != tt.wantDestroyed {
+				t.Fatalf("destroyed after retire = %v, want %v", got, tt.wantDestroyed)
+			}
+			if _, ok, _ := tracked.borrow(); ok {
+				t.Error("a retired pool lent a connection")
+			}
+			for i, conn := range conns {
+				if pool.destroyed.Load() {
+					t.Fatalf("destroyed with %d connections still borrowed", len(conns)-i)
+				}
+				conn.Close()
+			}
+			if !pool.destroyed.Load() {
+				t.Error("not destroyed after every connection was returned")
+			}
+		})
+	}
+}
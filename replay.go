@@ -0,0 +1,44 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// minNonceHexChars is the shortest nonce accepted, in hex characters.
+const minNonceHexChars = 16
+
+// validNonce reports whether nonce is a hex string (dashes allowed, as in a UUID) of at least
+// minNonceHexChars hex characters.
+func validNonce(nonce string) bool {
+	digits := strings.ReplaceAll(nonce, "-", "")
+	if len(digits) < minNonceHexChars || len(digits)%2 != 0 {
+		return false
+	}
+	_, err := hex.DecodeString(digits)
+	return err == nil
+}
+
+// checkNonce records the request nonce with SET NX EX. It answers 400 for a missing or short
+// nonce and 409 for a nonce already seen within NonceTTLSeconds, and returns false in both cases.
+func (p *MyPlugin) checkNonce(conn redisConn, rw http.ResponseWriter, req *http.Request) bool {
+	nonce := strings.ToLower(strings.TrimSpace(req.Header.Get(p.nonceHeader)))
+	if !validNonce(nonce) {
+		p.writeError(rw, http.StatusBadRequest, "missing or invalid "+p.nonceHeader)
+		return false
+	}
+
+	reply, err := conn.SetWithParamsAndTime(p.keyPrefix(req)+":nonce:"+nonce, "1", "NX", "EX", int64(p.nonceTTL))
+	if err != nil {
+		// 无法确认 nonce 是否用过时拒绝请求
+		p.logger.Error("记录 nonce 失败", logFields{"error": err})
+		p.writeError(rw, http.StatusServiceUnavailable, "nonce store unavailable")
+		return false
+	}
+	if reply != "OK" {
+		p.writeError(rw, http.StatusConflict, "duplicate nonce")
+		return false
+	}
+	return true
+}
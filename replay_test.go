@@ -0,0 +1,118 @@
+package gmsmPlugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidNonce(t *testing.T) {
+	tests := []struct {
+		nonce string
+		want  bool
+	}{
+		{"0123456789abcdef", true},
+		{"0123456789abcde", false},
+		{"0123-4567-89ab-cde", false},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", true},
+		{"0123456789abcdef0", false},
+		{"0123456789abcdeg", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nonce, func(t *testing.T) {
+			if got := validNonce(tt.nonce); got != tt.want {
+				t.Errorf("validNonce(%q) = %v, want %v", tt.nonce, got, tt.want)
+			}
+		})
+	}
+}
+
+func newReplayTestPlugin(t *testing.T, f *fakeRedis) *MyPlugin {
+	t.Helper()
+	return newTestPlugin(t, f, func(c *Config) {
+		c.ReplayProtectionEnabled = true
+		c.NonceHeader = "X-Nonce"
+		// 请求体都相同, 只看 nonce
+		c.DuplicateAction = "passthrough"
+		c.SMAlgorithm = ""
+	})
+}
+
+func nonceRequest(nonce string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("pay"))
+	if nonce != "" {
+		req.Header.Set("X-Nonce", nonce)
+	}
+	return req
+}
+
+func TestCheckNonce(t *testing.T) {
+	f := newFakeRedis(t)
+	p := newReplayTestPlugin(t, f)
+
+	tests := []struct {
+		name       string
+		nonce      string
+		wantStatus int
+	}{
+		{"fresh", "0123456789abcdef", http.StatusOK},
+		{"replayed", "0123456789abcdef", http.StatusConflict},
+		{"replayed in upper case", "0123456789ABCDEF", http.StatusConflict},
+		{"uuid", "6ba7b810-9dad-11d1-80b4-00c04fd430c8", http.StatusOK},
+		{"15 hex characters", "0123456789abcde", http.StatusBadRequest},
+		{"not hex", "zzzzzzzzzzzzzzzz", http.StatusBadRequest},
+		{"missing", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			p.ServeHTTP(rw, nonceRequest(tt.nonce))
+			if rw.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rw.Code, tt.wantStatus, rw.Body)
+			}
+		})
+	}
+
+	if _, ok := f.get(0, p.keyPrefix(nonceRequest(""))+":nonce:0123456789abcdef"); !ok {
+		t.Error("nonce was not stored")
+	}
+}
+
+// Many submissions of one nonce at once: exactly one gets through.
+func TestCheckNonceConcurrent(t *testing.T) {
+	f := newFakeRedis(t)
+	f.latency = time.Millisecond
+	p := newReplayTestPlugin(t, f)
+
+	const submissions = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	codes := make(map[int]int)
+	bodies := make(map[string]bool)
+	for i := 0; i < submissions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rw := httptest.NewRecorder()
+			p.ServeHTTP(rw, nonceRequest("fedcba9876543210"))
+			mu.Lock()
+			defer mu.Unlock()
+			codes[rw.Code]++
+			if rw.Code == http.StatusConflict {
+				bodies[rw.Body.String()] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	if codes[http.StatusOK] != 1 || codes[http.StatusConflict] != submissions-1 {
+		t.Errorf("status counts = %v, want one 200 and %d 409", codes, submissions-1)
+	}
+	if want := `{"code":409,"message":"duplicate nonce"}`; len(bodies) != 1 || !bodies[want] {
+		t.Errorf("409 bodies = %v, want %s", bodies, want)
+	}
+}
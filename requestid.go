@@ -0,0 +1,90 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// maxRequestIDLength bounds incoming request IDs, which end up in redis keys and logs.
+const maxRequestIDLength = 128
+
+// newUUIDv4 returns a random version 4 UUID read from crypto/rand.
+func newUUIDv4() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return formatUUID(b), nil
+}
+
+// formatUUID writes the 16 bytes of a UUID in the 8-4-4-4-12 hex form.
+func formatUUID(b []byte) string {
+	buf := make([]byte, 36)
+	hex.Encode(buf, b[:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf)
+}
+
+// validRequestID reports whether id is short printable ASCII without spaces, safe to use in a redis key.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// assignRequestID takes the request ID from the request header, generating a UUID when it is
+// missing or invalid, and sets it on both the request (for the next handler) and the response.
+func (p *MyPlugin) assignRequestID(rw http.ResponseWriter, req *http.Request) string {
+	id := req.Header.Get(p.requestIDHeader)
+	if !validRequestID(id) {
+		var err error
+		if id, err = newUUIDv4(); err != nil {
+			p.logger.Error("生成请求 ID 失败", logFields{"error": err})
+			return ""
+		}
+		req.Header.Set(p.requestIDHeader, id)
+	}
+	rw.Header().Set(p.requestIDHeader, id)
+	return id
+}
+
+// recordRequestHash stores hashHex under <prefix>:req:<requestID> with the hash TTL and logs it
+// when LogRequestID is set.
+func (p *MyPlugin) recordRequestHash(conn redisConn, req *http.Request, requestID, algorithm, hashHex string) {
+	if requestID == "" {
+		return
+	}
+
+	key := p.keyPrefix(req) + ":req:" + requestID
+	var err error
+	if p.hashTTL > 0 {
+		_, err = conn.SetEx(key, p.hashTTL, hashHex)
+	} else {
+		_, err = conn.Set(key, hashHex)
+	}
+	if err != nil {
+		p.logger.Error("按请求 ID 记录 hash 失败", logFields{"error": err})
+	}
+
+	if p.logRequestID {
+		fields := hashLogFields(req, hashHex)
+		fields["requestId"], fields["algorithm"] = requestID, algorithm
+		p.logger.Info("请求 hash", fields)
+	}
+}
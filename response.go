@@ -0,0 +1,171 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+)
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// statusCode returns the recorded status, defaulting to 200 when nothing was written.
+func (r *statusRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// responseCapture buffers the status code and body so they can be inspected before
+// anything is sent to the client. Headers go straight to the wrapped ResponseWriter.
+type responseCapture struct {
+	rw     http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseCapture(rw http.ResponseWriter) *responseCapture {
+	return &responseCapture{rw: rw}
+}
+
+func (c *responseCapture) Header() http.Header {
+	return c.rw.Header()
+}
+
+func (c *responseCapture) WriteHeader(code int) {
+	if c.status == 0 {
+		c.status = code
+	}
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	return c.body.Write(b)
+}
+
+// statusCode returns the captured status, defaulting to 200 when nothing was written.
+func (c *responseCapture) statusCode() int {
+	if c.status == 0 {
+		return http.StatusOK
+	}
+	return c.status
+}
+
+// flush sends the captured response to the wrapped ResponseWriter.
+func (c *responseCapture) flush() {
+	c.rw.WriteHeader(c.statusCode())
+	c.rw.Write(c.body.Bytes())
+}
+
+// sm3ResponseHashHeader carries the SM3 hex of the response body.
+const sm3ResponseHashHeader = "X-SM3-Response-Hash"
+
+// hashingWriter buffers the response up to limit bytes so its SM3 hash can be sent as a header.
+// A larger response is streamed on unhashed, with the header set to skipped-oversized.
+type hashingWriter struct {
+	rw        http.ResponseWriter
+	limit     int
+	status    int
+	body      bytes.Buffer
+	streaming bool
+}
+
+func newHashingWriter(rw http.ResponseWriter, limit int) *hashingWriter {
+	return &hashingWriter{rw: rw, limit: limit}
+}
+
+func (h *hashingWriter) Header() http.Header {
+	return h.rw.Header()
+}
+
+func (h *hashingWriter) WriteHeader(code int) {
+	if h.status == 0 {
+		h.status = code
+	}
+}
+
+func (h *hashingWriter) Write(b []byte) (int, error) {
+	if h.status == 0 {
+		h.status = http.StatusOK
+	}
+	if h.streaming {
+		return h.rw.Write(b)
+	}
+	if h.body.Len()+len(b) <= h.limit {
+		return h.body.Write(b)
+	}
+
+	// 超过缓冲上限: 发送已缓冲的部分, 之后直接转发
+	h.streaming = true
+	h.rw.Header().Set(sm3ResponseHashHeader, "skipped-oversized")
+	h.rw.WriteHeader(h.status)
+	if _, err := h.rw.Write(h.body.Bytes()); err != nil {
+		return 0, err
+	}
+	h.body.Reset()
+	return h.rw.Write(b)
+}
+
+// finish sets the hash header and sends the buffered response, unless it is already streaming.
+func (h *hashingWriter) finish() {
+	if h.streaming {
+		return
+	}
+	if h.status == 0 {
+		h.status = http.StatusOK
+	}
+	h.rw.Header().Set(sm3ResponseHashHeader, hex.EncodeToString(sm3Sum(h.body.Bytes())))
+	h.rw.WriteHeader(h.status)
+	h.rw.Write(h.body.Bytes())
+}
+
+// sendEncrypted replaces a 2xx captured response body with its base64 SM4-CBC ciphertext under
+// key and the fixed SM4IV, followed by the HMAC-SM3 tag when sm4CBCMACKey is set.
+// Other responses are sent unchanged.
+func (p *MyPlugin) sendEncrypted(capture *responseCapture, key []byte) {
+	status := capture.statusCode()
+	if status < 200 || status >= 300 {
+		capture.flush()
+		return
+	}
+
+	ciphertext, err := sm4CBCEncrypt(key, p.sm4IV, capture.body.Bytes())
+	if err != nil {
+		p.logger.Error("响应加密失败", logFields{"error": err})
+		capture.rw.Header().Del("Content-Length")
+		p.writeError(capture.rw, http.StatusInternalServerError, "response encryption failed")
+		return
+	}
+	if macKey := p.keys.Load().sm4CBCMACKey; macKey != nil {
+		ciphertext = appendCBCTag(macKey, p.sm4IV, ciphertext)
+	}
+	encoded := base64.StdEncoding.EncodeToString(ciphertext)
+
+	header := capture.rw.Header()
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Length", strconv.Itoa(len(encoded)))
+	capture.rw.WriteHeader(status)
+	capture.rw.Write([]byte(encoded))
+}
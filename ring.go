@@ -0,0 +1,48 @@
+package gmsmPlugin
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// HashRing is a consistent hash ring placing vnodes virtual nodes per shard.
+// Virtual node v of shard s sits at the first 8 bytes of SM3(s || v), both 4 bytes big-endian.
+type HashRing struct {
+	points []uint64
+	owners []int
+}
+
+// NewHashRing builds a ring for shards shards with vnodes virtual nodes each.
+func NewHashRing(shards, vnodes int) *HashRing {
+	type vnode struct {
+		point uint64
+		shard int
+	}
+	nodes := make([]vnode, 0, shards*vnodes)
+	buf := make([]byte, 8)
+	for s := 0; s < shards; s++ {
+		for v := 0; v < vnodes; v++ {
+			binary.BigEndian.PutUint32(buf, uint32(s))
+			binary.BigEndian.PutUint32(buf[4:], uint32(v))
+			nodes = append(nodes, vnode{point: binary.BigEndian.Uint64(sm3Sum(buf)), shard: s})
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].point < nodes[j].point })
+
+	ring := &HashRing{points: make([]uint64, len(nodes)), owners: make([]int, len(nodes))}
+	for i, node := range nodes {
+		ring.points[i], ring.owners[i] = node.point, node.shard
+	}
+	return ring
+}
+
+// FindShard returns the shard owning key: the first virtual node at or after the
+// position of SM3(key) on the ring, wrapping around at the end.
+func (r *HashRing) FindShard(key string) int {
+	point := binary.BigEndian.Uint64(sm3Sum([]byte(key)))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= point })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[i]
+}
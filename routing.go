@@ -0,0 +1,70 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// hashRoute forwards requests whose body SM3 hex starts with prefix to an upstream.
+type hashRoute struct {
+	prefix string
+	proxy  *httputil.ReverseProxy
+}
+
+// newHashRoutes builds the hash routes, most specific (longest) prefix first.
+func newHashRoutes(routing map[string]string) ([]hashRoute, error) {
+	routes := make([]hashRoute, 0, len(routing))
+	for prefix, upstream := range routing {
+		prefix = strings.ToLower(prefix)
+		if prefix == "" || len(prefix) > 64 {
+			return nil, fmt.Errorf("invalid hash prefix %q", prefix)
+		}
+		// 奇数长度的前缀补一位再校验
+		if _, err := hex.DecodeString(prefix + strings.Repeat("0", len(prefix)%2)); err != nil {
+			return nil, fmt.Errorf("invalid hash prefix %q", prefix)
+		}
+
+		target, err := url.Parse(upstream)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return nil, fmt.Errorf("invalid upstream %q for hash prefix %q", upstream, prefix)
+		}
+		routes = append(routes, hashRoute{prefix: prefix, proxy: httputil.NewSingleHostReverseProxy(target)})
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if len(routes[i].prefix) != len(routes[j].prefix) {
+			return len(routes[i].prefix) > len(routes[j].prefix)
+		}
+		return routes[i].prefix < routes[j].prefix
+	})
+	return routes, nil
+}
+
+// routeByHash forwards the request to the upstream with the longest prefix matching the SM3
+// of the body, or to the next handler when none matches.
+func (p *MyPlugin) routeByHash(rw http.ResponseWriter, req *http.Request, body []byte) {
+	hashHex := fmt.Sprintf("%x", sm3Sum(body))
+	restoreBody(req, body)
+
+	for _, route := range p.hashRoutes {
+		if strings.HasPrefix(hashHex, route.prefix) {
+			p.logger.Debug("按 hash 前缀转发请求", logFields{"prefix": route.prefix})
+			route.proxy.ServeHTTP(rw, req)
+			return
+		}
+	}
+	p.next.ServeHTTP(rw, req)
+}
+
+// restoreBody replaces the already consumed request body with body.
+func restoreBody(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+}
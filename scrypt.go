@@ -0,0 +1,226 @@
+package gmsmPlugin
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/bits"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tjfoc/gmsm/sm3"
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+// pbkdf2SM3 is PBKDF2 (RFC 8018) with HMAC-SM3 as the PRF.
+func pbkdf2SM3(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sm3.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	out := make([]byte, 0, blocks*hashLen)
+	counter := make([]byte, 4)
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter, uint32(block))
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter)
+		u := prf.Sum(nil)
+
+		t := append([]byte{}, u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:keyLen]
+}
+
+// salsa208 applies the Salsa20/8 core to b in place.
+func salsa208(b *[16]uint32) {
+	x := *b
+	for i := 0; i < 8; i += 2 {
+		x[4] ^= bits.RotateLeft32(x[0]+x[12], 7)
+		x[8] ^= bits.RotateLeft32(x[4]+x[0], 9)
+		x[12] ^= bits.RotateLeft32(x[8]+x[4], 13)
+		x[0] ^= bits.RotateLeft32(x[12]+x[8], 18)
+		x[9] ^= bits.RotateLeft32(x[5]+x[1], 7)
+		x[13] ^= bits.RotateLeft32(x[9]+x[5], 9)
+		x[1] ^= bits.RotateLeft32(x[13]+x[9], 13)
+		x[5] ^= bits.RotateLeft32(x[1]+x[13], 18)
+		x[14] ^= bits.RotateLeft32(x[10]+x[6], 7)
+		x[2] ^= bits.RotateLeft32(x[14]+x[10], 9)
+		x[6] ^= bits.RotateLeft32(x[2]+x[14], 13)
+		x[10] ^= bits.RotateLeft32(x[6]+x[2], 18)
+		x[3] ^= bits.RotateLeft32(x[15]+x[11], 7)
+		x[7] ^= bits.RotateLeft32(x[3]+x[15], 9)
+		x[11] ^= bits.RotateLeft32(x[7]+x[3], 13)
+		x[15] ^= bits.RotateLeft32(x[11]+x[7], 18)
+
+		x[1] ^= bits.RotateLeft32(x[0]+x[3], 7)
+		x[2] ^= bits.RotateLeft32(x[1]+x[0], 9)
+		x[3] ^= bits.RotateLeft32(x[2]+x[1], 13)
+		x[0] ^= bits.RotateLeft32(x[3]+x[2], 18)
+		x[6] ^= bits.RotateLeft32(x[5]+x[4], 7)
+		x[7] ^= bits.RotateLeft32(x[6]+x[5], 9)
+		x[4] ^= bits.RotateLeft32(x[7]+x[6], 13)
+		x[5] ^= bits.RotateLeft32(x[4]+x[7], 18)
+		x[11] ^= bits.RotateLeft32(x[10]+x[9], 7)
+		x[8] ^= bits.RotateLeft32(x[11]+x[10], 9)
+		x[9] ^= bits.RotateLeft32(x[8]+x[11], 13)
+		x[10] ^= bits.RotateLeft32(x[9]+x[8], 18)
+		x[12] ^= bits.RotateLeft32(x[15]+x[14], 7)
+		x[13] ^= bits.RotateLeft32(x[12]+x[15], 9)
+		x[14] ^= bits.RotateLeft32(x[13]+x[12], 13)
+		x[15] ^= bits.RotateLeft32(x[14]+x[13], 18)
+	}
+	for i := range b {
+		b[i] += x[i]
+	}
+}
+
+// blockMix is scrypt's BlockMix over 2r 64-byte blocks; the result is written to out.
+func blockMix(in, out []uint32, r int) {
+	var x [16]uint32
+	copy(x[:], in[(2*r-1)*16:])
+	for i := 0; i < 2*r; i++ {
+		for j := range x {
+			x[j] ^= in[i*16+j]
+		}
+		salsa208(&x)
+		// 偶数块放前半部分, 奇数块放后半部分
+		copy(out[(i/2+(i%2)*r)*16:], x[:])
+	}
+}
+
+// roMix is scrypt's ROMix applied to b in place.
+func roMix(b []byte, r, n int) {
+	words := 32 * r
+	x := make([]uint32, words)
+	y := make([]uint32, words)
+	v := make([]uint32, words*n)
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+
+	for i := 0; i < n; i++ {
+		copy(v[i*words:], x)
+		blockMix(x, y, r)
+		x, y = y, x
+	}
+	for i := 0; i < n; i++ {
+		j := int(x[(2*r-1)*16]) & (n - 1)
+		for k := range x {
+			x[k] ^= v[j*words+k]
+		}
+		blockMix(x, y, r)
+		x, y = y, x
+	}
+
+	for i := range x {
+		binary.LittleEndian.PutUint32(b[i*4:], x[i])
+	}
+}
+
+// scryptSM3 is scrypt (RFC 7914) with HMAC-SM3 in place of HMAC-SHA256 in both PBKDF2 steps.
+func scryptSM3(password, salt []byte, n, r, p, keyLen int) ([]byte, error) {
+	if n <= 1 || n&(n-1) != 0 {
+		return nil, errors.New("scrypt: N must be a power of two greater than 1")
+	}
+	if r <= 0 || p <= 0 || uint64(r)*uint64(p) >= 1<<30 {
+		return nil, errors.New("scrypt: invalid r or p")
+	}
+
+	b := pbkdf2SM3(password, salt, 1, p*128*r)
+	for i := 0; i < p; i++ {
+		roMix(b[i*128*r:(i+1)*128*r], r, n)
+	}
+	return pbkdf2SM3(password, b, 1, keyLen), nil
+}
+
+// derivedKeyCache is a bounded LRU of derived keys whose entries expire after ttl.
+type derivedKeyCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type derivedKeyEntry struct {
+	id      string
+	key     []byte
+	expires time.Time
+}
+
+func newDerivedKeyCache(size int, ttl time.Duration) *derivedKeyCache {
+	return &derivedKeyCache{size: size, ttl: ttl, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// get returns the cached key for id if it has not expired.
+func (c *derivedKeyCache) get(id string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*derivedKeyEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, id)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.key, true
+}
+
+// put caches key under id, evicting the least recently used entry when full.
+func (c *derivedKeyCache) put(id string, key []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[id]; ok {
+		c.order.Remove(elem)
+	}
+	c.entries[id] = c.order.PushFront(&derivedKeyEntry{id: id, key: key, expires: time.Now().Add(c.ttl)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*derivedKeyEntry).id)
+	}
+}
+
+// sm4KeyFor returns the SM4 key for the request: the configured key, or one derived with
+// scryptSM3 from the password header and the salt SM3(globalSalt || clientID).
+func (p *MyPlugin) sm4KeyFor(req *http.Request) ([]byte, error) {
+	if !p.sm4PasswordDerived {
+		return p.keys.Load().sm4Key, nil
+	}
+
+	password := req.Header.Get(p.sm4PasswordHeader)
+	if password == "" {
+		return nil, errors.New("missing " + p.sm4PasswordHeader + " header")
+	}
+	salt := sm3Sum(append(append([]byte{}, p.sm4PasswordSalt...), clientID(req)...))
+
+	id := hex.EncodeToString(sm3Sum(append([]byte(password), salt...)))
+	if key, ok := p.derivedKeys.get(id); ok {
+		return key, nil
+	}
+	key, err := scryptSM3([]byte(password), salt, p.sm4ScryptN, p.sm4ScryptR, p.sm4ScryptP, sm4.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	p.derivedKeys.put(id, key)
+	return key, nil
+}
@@ -0,0 +1,173 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+const (
+	recoverKeyPath = "/recover-key"
+	// secretShareKey holds share i ("<x>:<y hex>") in redis database SecretSharingFirstDB+i.
+	secretShareKey = "gmsm:sss:share"
+)
+
+// splitSecret splits secret into total Shamir shares over the SM2 group order, any threshold
+// of which reconstruct it. Share i is evaluated at x = i+1.
+func splitSecret(secret *big.Int, threshold, total int) ([]*big.Int, error) {
+	n := sm2.P256Sm2().Params().N
+	coefficients := []*big.Int{secret}
+	for i := 1; i < threshold; i++ {
+		c, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+		coefficients = append(coefficients, c)
+	}
+
+	shares := make([]*big.Int, total)
+	for i := range shares {
+		// Horner 法计算 f(x) mod n
+		x := big.NewInt(int64(i + 1))
+		y := new(big.Int)
+		for j := len(coefficients) - 1; j >= 0; j-- {
+			y.Mul(y, x)
+			y.Add(y, coefficients[j])
+			y.Mod(y, n)
+		}
+		shares[i] = y
+	}
+	for _, c := range coefficients[1:] {
+		wipeInt(c)
+	}
+	return shares, nil
+}
+
+// combineShares reconstructs f(0) from shares at the given x coordinates by Lagrange interpolation.
+func combineShares(xs []int64, ys []*big.Int) *big.Int {
+	n := sm2.P256Sm2().Params().N
+	secret := new(big.Int)
+	for i := range xs {
+		num, den := big.NewInt(1), big.NewInt(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			// l_i(0) = Π x_j / (x_j - x_i)
+			num.Mul(num, big.NewInt(xs[j]))
+			num.Mod(num, n)
+			den.Mul(den, big.NewInt(xs[j]-xs[i]))
+			den.Mod(den, n)
+		}
+		term := new(big.Int).Mul(ys[i], num)
+		term.Mul(term, den.ModInverse(den, n))
+		secret.Add(secret, term)
+		secret.Mod(secret, n)
+	}
+	return secret
+}
+
+// wipeInt overwrites the words backing x.
+func wipeInt(x *big.Int) {
+	words := x.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	x.SetInt64(0)
+}
+
+// storeSecretShares splits the SM2 private key and writes share i to share database i. Shares are
+// re-randomised every time the plugin starts, which invalidates shares leaked earlier.
+func (p *MyPlugin) storeSecretShares() error {
+	shares, err := splitSecret(p.sm2PrivateKey.D, p.sharingThreshold, len(p.shareStores.shards))
+	if err != nil {
+		return err
+	}
+	for i, share := range shares {
+		value := strconv.Itoa(i+1) + ":" + share.Text(16)
+		wipeInt(share)
+		if _, err := p.shareStores.setOn(i, secretShareKey, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverKey reconstructs the SM2 private key from the first threshold shares that can be read.
+func (p *MyPlugin) recoverKey() (*sm2.PrivateKey, int, error) {
+	var xs []int64
+	var ys []*big.Int
+	for i := range p.shareStores.shards {
+		if len(xs) == p.sharingThreshold {
+			break
+		}
+		value, err := p.shareStores.getFrom(i, secretShareKey)
+		if err != nil || value == "" {
+			continue
+		}
+		parts := strings.SplitN(value, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		x, err := strconv.ParseInt(parts[0], 10, 64)
+		y, ok := new(big.Int).SetString(parts[1], 16)
+		if err != nil || !ok {
+			continue
+		}
+		xs, ys = append(xs, x), append(ys, y)
+	}
+	defer func() {
+		for _, y := range ys {
+			wipeInt(y)
+		}
+	}()
+	if len(xs) < p.sharingThreshold {
+		return nil, len(xs), errors.New("not enough key shares available")
+	}
+
+	key := new(sm2.PrivateKey)
+	key.PublicKey.Curve = sm2.P256Sm2()
+	key.D = combineShares(xs, ys)
+	key.PublicKey.X, key.PublicKey.Y = key.Curve.ScalarBaseMult(key.D.Bytes())
+	if key.PublicKey.X.Cmp(p.sm2PrivateKey.X) != 0 || key.PublicKey.Y.Cmp(p.sm2PrivateKey.Y) != 0 {
+		wipeInt(key.D)
+		return nil, len(xs), errors.New("key shares do not reconstruct the configured key")
+	}
+	return key, len(xs), nil
+}
+
+// serveRecoverKey reconstructs the SM2 key from its shares, signs the body once and wipes the key.
+// Callers must present SecretSharingAdminToken as a Bearer token.
+func (p *MyPlugin) serveRecoverKey(rw http.ResponseWriter, req *http.Request, body []byte) {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if p.sharingAdminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(p.sharingAdminToken)) != 1 {
+		p.logger.Warn("恢复私钥被拒绝", logFields{"ip": clientIP(req)})
+		p.writeError(rw, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	key, used, err := p.recoverKey()
+	if err != nil {
+		p.writeError(rw, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	der, err := key.Sign(rand.Reader, body, nil)
+	wipeInt(key.D)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	signature, err := encodeSM2Signature(der, &p.sm2PrivateKey.PublicKey, p.sm2SignatureFormat)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"signature": signature, "sharesUsed": used, "code": 0})
+}
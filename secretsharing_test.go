@@ -0,0 +1,181 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/piaohao/godis"
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// testSM2PrivateKeyPEM returns key as the PKCS#8 PEM that SM2PrivateKeyPEM expects.
+func testSM2PrivateKeyPEM(t *testing.T, key *sm2.PrivateKey) string {
+	t.Helper()
+	pemKey, err := x509.WritePrivateKeyToPem(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pemKey)
+}
+
+func TestSplitCombineSecret(t *testing.T) {
+	secret, err := rand.Int(rand.Reader, sm2.P256Sm2().Params().N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := splitSecret(new(big.Int).Set(secret), 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		indexes []int
+		want    bool
+	}{
+		{"first three", []int{0, 1, 2}, true},
+		{"last three", []int{2, 3, 4}, true},
+		{"scattered", []int{0, 2, 4}, true},
+		{"all five", []int{0, 1, 2, 3, 4}, true},
+		{"below threshold", []int{1, 3}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var xs []int64
+			var ys []*big.Int
+			for _, i := range tt.indexes {
+				xs, ys = append(xs, int64(i+1)), append(ys, shares[i])
+			}
+			if got := combineShares(xs, ys).Cmp(secret) == 0; got != tt.want {
+				t.Errorf("reconstructed secret matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newSharingPlugin(t *testing.T, f *fakeRedis) *MyPlugin {
+	t.Helper()
+	key, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := newShardedRedis(f.option(0), godis.PoolConfig{MaxTotal: 2}, 10, 5, newLogger(io.Discard, "error"))
+	t.Cleanup(stores.close)
+	p := &MyPlugin{
+		sm2PrivateKey:      key,
+		sm2SignatureFormat: "der",
+		sharingThreshold:   3,
+		sharingAdminToken:  "admin-token",
+		shareStores:        stores,
+		logger:             newLogger(io.Discard, "error"),
+	}
+	if err := p.storeSecretShares(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestStoreSecretSharesDatabases(t *testing.T) {
+	f := newFakeRedis(t)
+	newSharingPlugin(t, f)
+
+	if _, ok := f.get(0, secretShareKey); ok {
+		t.Errorf("a key share was written to database 0")
+	}
+	for db := 10; db < 15; db++ {
+		if _, ok := f.get(db, secretShareKey); !ok {
+			t.Errorf("no key share in database %d", db)
+		}
+	}
+}
+
+func TestServeRecoverKey(t *testing.T) {
+	f := newFakeRedis(t)
+	p := newSharingPlugin(t, f)
+	body := []byte("sign me")
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer nope", http.StatusUnauthorized},
+		{"token without scheme", "admin-token-extra", http.StatusUnauthorized},
+		{"admin token", "Bearer admin-token", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, recoverKeyPath, strings.NewReader(string(body)))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rw := httptest.NewRecorder()
+			p.serveRecoverKey(rw, req, body)
+
+			if rw.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rw.Code, tt.wantStatus, rw.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var response struct {
+				Signature  string `json:"signature"`
+				SharesUsed int    `json:"sharesUsed"`
+			}
+			if err := json.Unmarshal(rw.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			der, err := hex.DecodeString(response.Signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !p.sm2PrivateKey.PublicKey.Verify(body, der) {
+				t.Errorf("signature does not verify under the configured public key")
+			}
+			if response.SharesUsed != 3 {
+				t.Errorf("sharesUsed = %d, want 3", response.SharesUsed)
+			}
+		})
+	}
+}
+
+func TestSecretSharingConfig(t *testing.T) {
+	key, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string
+	}{
+		{"missing admin token", func(c *Config) { c.SecretSharingAdminToken = "" }, "secretSharingAdminToken"},
+		{"overlaps redisDb", func(c *Config) { c.RedisDb = 12 }, "secretSharingFirstDB"},
+		{"overlaps hash shards", func(c *Config) { c.HashSharding, c.ShardCount = true, 11 }, "secretSharingFirstDB"},
+		{"negative first database", func(c *Config) { c.SecretSharingFirstDB = -1 }, "secretSharingFirstDB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := CreateConfig()
+			config.SecretSharingEnabled = true
+			config.SecretSharingAdminToken = "admin-token"
+			config.SM2PrivateKeyPEM = testSM2PrivateKeyPEM(t, key)
+			tt.modify(config)
+
+			_, err := New(context.Background(), http.NotFoundHandler(), config, "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want it to mention %s", err, tt.wantErr)
+			}
+		})
+	}
+}
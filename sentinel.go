@@ -0,0 +1,248 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/piaohao/godis"
+)
+
+// sentinelRetryInterval is how long the failover listener waits before trying the sentinels again.
+const sentinelRetryInterval = 5 * time.Second
+
+// sentinelAddr is the address of one Redis Sentinel.
+type sentinelAddr struct {
+	host string
+	port int
+}
+
+// parseSentinelAddrs parses "host:port" entries; an entry may hold several comma-separated addresses.
+func parseSentinelAddrs(entries []string) ([]sentinelAddr, error) {
+	var addrs []sentinelAddr
+	for _, entry := range entries {
+		for _, item := range strings.Split(entry, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			host, rawPort, err := net.SplitHostPort(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid redisSentinelAddrs entry %q: %w", item, err)
+			}
+			port, err := strconv.Atoi(rawPort)
+			if err != nil {
+				return nil, fmt.Errorf("invalid redisSentinelAddrs entry %q", item)
+			}
+			addrs = append(addrs, sentinelAddr{host: host, port: port})
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("redisSentinelAddrs must not be empty")
+	}
+	return addrs, nil
+}
+
+// queryMaster asks the sentinels in order for the address of masterName and returns the first answer.
+func queryMaster(sentinels []sentinelAddr, masterName string) (string, int, error) {
+	var lastErr error
+	for _, sentinel := range sentinels {
+		r := godis.NewRedis(&godis.Option{Host: sentinel.host, Port: sentinel.port})
+		reply, err := r.SentinelGetMasterAddrByName(masterName)
+		r.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(reply) != 2 || reply[0] == "" {
+			lastErr = fmt.Errorf("sentinel %s:%d does not know master %q", sentinel.host, sentinel.port, masterName)
+			continue
+		}
+		port, err := strconv.Atoi(reply[1])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return reply[0], port, nil
+	}
+	return "", 0, lastErr
+}
+
+// sentinelPool is a redisPool that dials the master reported by Redis Sentinel.
+// A +switch-master event or a failed borrow marks the master stale, and the next
+// GetResource asks the sentinels again and swaps in a pool for the new master.
+type sentinelPool struct {
+	sentinels  []sentinelAddr
+	masterName string
+	option     godis.Option
+	config     godis.PoolConfig
+
+	mu     sync.Mutex
+	pool   *godis.Pool
+	master string
+	stale  bool
+
+	logger *logger
+}
+
+// newSentinelPool creates the pool and starts listening for failovers until ctx is done.
+func newSentinelPool(ctx context.Context, sentinels []sentinelAddr, masterName string, option godis.Option, config godis.PoolConfig, logger *logger) *sentinelPool {
+	s := &sentinelPool{
+		sentinels:  sentinels,
+		masterName: masterName,
+		option:     option,
+		config:     config,
+		stale:      true,
+		logger:     logger,
+	}
+	go s.watch(ctx)
+	return s
+}
+
+// current returns the pool for the current master, asking the sentinels first when the master is stale.
+func (s *sentinelPool) current() (*godis.Pool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.stale && s.pool != nil {
+		return s.pool, nil
+	}
+
+	host, port, err := queryMaster(s.sentinels, s.masterName)
+	if err != nil {
+		if s.pool == nil {
+			return nil, err
+		}
+		// 查询失败时继续使用旧的主节点, 下一个请求再查
+		s.logger.Error("查询 redis sentinel 失败", logFields{"error": err})
+		return s.pool, nil
+	}
+	s.stale = false
+
+	master := net.JoinHostPort(host, strconv.Itoa(port))
+	if s.pool != nil && master == s.master {
+		return s.pool, nil
+	}
+
+	option := s.option
+	option.Host, option.Port = host, port
+	old := s.pool
+	s.pool, s.master = godis.NewPool(&s.config, &option), master
+	if old != nil {
+		old.Destroy()
+	}
+	s.logger.Info("redis 主节点", logFields{"master": master})
+	return s.pool, nil
+}
+
+// markStale makes the next GetResource ask the sentinels for the master again.
+func (s *sentinelPool) markStale() {
+	s.mu.Lock()
+	s.stale = true
+	s.mu.Unlock()
+}
+
+// GetResource borrows a connection to the current master. When borrowing fails the
+// sentinels are asked once more, so a failover is picked up without waiting for the event.
+func (s *sentinelPool) GetResource() (*godis.Redis, error) {
+	pool, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pool.GetResource()
+	if err == nil {
+		return conn, nil
+	}
+
+	s.markStale()
+	if pool, err = s.current(); err != nil {
+		return nil, err
+	}
+	return pool.GetResource()
+}
+
+// Destroy closes the pool of the current master.
+func (s *sentinelPool) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pool != nil {
+		s.pool.Destroy()
+		s.pool = nil
+	}
+	s.stale = true
+}
+
+// watch subscribes to +switch-master on the first reachable sentinel and marks the
+// master stale when masterName fails over. It reconnects until ctx is done.
+func (s *sentinelPool) watch(ctx context.Context) {
+	for i := 0; ctx.Err() == nil; i++ {
+		sentinel := s.sentinels[i%len(s.sentinels)]
+		// 保留底层连接, 退出时直接关闭它来打断阻塞的 Subscribe;
+		// 在别的 goroutine 里调用 r.Close 会和 Subscribe 竞争 godis 的内部状态
+		var socket net.Conn
+		r := godis.NewRedis(&godis.Option{
+			Host: sentinel.host,
+			Port: sentinel.port,
+			Dial: func(addr string, timeout time.Duration) (net.Conn, error) {
+				conn, err := net.DialTimeout("tcp", addr, timeout)
+				socket = conn
+				return conn, err
+			},
+		})
+		if err := r.Connect(); err != nil {
+			s.logger.Error("连接 redis sentinel 失败", logFields{"error": err})
+			r.Close()
+			// 一轮都连不上再等待
+			if (i+1)%len(s.sentinels) != 0 {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(sentinelRetryInterval):
+			}
+			continue
+		}
+
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				socket.Close()
+			case <-done:
+			}
+		}()
+
+		pubsub := &godis.RedisPubSub{
+			OnSubscribe: func(channel string, subscribedChannels int) {},
+			OnMessage: func(channel, message string) {
+				// 消息格式: <master name> <old ip> <old port> <new ip> <new port>
+				if strings.HasPrefix(message, s.masterName+" ") {
+					s.logger.Warn("redis sentinel 主从切换", logFields{"message": message})
+					s.markStale()
+				}
+			},
+		}
+		err := r.Subscribe(pubsub, "+switch-master")
+		close(done)
+		r.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.logger.Error("订阅 redis sentinel 失败", logFields{"error": err})
+		}
+		// 断线期间可能错过切换事件
+		s.markStale()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(sentinelRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/piaohao/godis"
+)
+
+func TestParseSentinelAddrs(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []sentinelAddr
+		wantErr bool
+	}{
+		{"one per entry", []string{"10.0.0.1:26379", "10.0.0.2:26379"}, []sentinelAddr{{"10.0.0.1", 26379}, {"10.0.0.2", 26379}}, false},
+		{"comma separated", []string{"10.0.0.1:26379, 10.0.0.2:26380"}, []sentinelAddr{{"10.0.0.1", 26379}, {"10.0.0.2", 26380}}, false},
+		{"missing port", []string{"10.0.0.1"}, nil, true},
+		{"bad port", []string{"10.0.0.1:http"}, nil, true},
+		{"empty", []string{" , "}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSentinelAddrs(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSentinelAddrs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseSentinelAddrs() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("parseSentinelAddrs()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// newTestSentinelPool returns a sentinelPool asking sentinel for "mymaster".
+func newTestSentinelPool(t *testing.T, sentinel *fakeRedis) *sentinelPool {
+	t.Helper()
+	sentinels, err := parseSentinelAddrs([]string{sentinel.addr()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := newSentinelPool(ctx, sentinels, "mymaster", sentinel.option(0), godis.PoolConfig{MaxTotal: 2}, newLogger(io.Discard, "error"))
+	t.Cleanup(func() {
+		cancel()
+		s.Destroy()
+	})
+	return s
+}
+
+// setVia borrows a connection from s and sets key on whichever master it points at.
+func setVia(t *testing.T, s *sentinelPool, key string) {
+	t.Helper()
+	r, err := s.GetResource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := r.Set(key, "1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A +switch-master event marks the master stale and the next borrow asks the sentinel again.
+func TestSentinelPoolSwitchMaster(t *testing.T) {
+	sentinel, first, second := newFakeRedis(t), newFakeRedis(t), newFakeRedis(t)
+	sentinel.setMaster("mymaster", first.addr())
+	s := newTestSentinelPool(t, sentinel)
+
+	setVia(t, s, "before")
+	if _, ok := first.get(0, "before"); !ok {
+		t.Fatal("write did not reach the first master")
+	}
+
+	sentinel.setMaster("mymaster", second.addr())
+	setVia(t, s, "cached")
+	if _, ok := first.get(0, "cached"); !ok {
+		t.Error("pool asked the sentinel again without a failover")
+	}
+
+	waitFor(t, "the failover listener to subscribe", func() bool {
+		sentinel.mu.Lock()
+		defer sentinel.mu.Unlock()
+		return len(sentinel.subscribers["+switch-master"]) > 0
+	})
+	sentinel.publish("+switch-master", "othermaster 127.0.0.1 1 127.0.0.1 2")
+	sentinel.publish("+switch-master", "mymaster 127.0.0.1 1 127.0.0.1 2")
+	waitFor(t, "the master to be marked stale", func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.stale
+	})
+
+	setVia(t, s, "after")
+	if _, ok := second.get(0, "after"); !ok {
+		t.Error("write after the failover did not reach the new master")
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+// A master that refuses connections makes the pool ask the sentinel again within the same borrow.
+func TestSentinelPoolFailedBorrow(t *testing.T) {
+	sentinel, dead, alive := newFakeRedis(t), newFakeRedis(t), newFakeRedis(t)
+	dead.listener.Close()
+	sentinel.setMaster("mymaster", dead.addr())
+	s := newTestSentinelPool(t, sentinel)
+	if _, err := s.current(); err != nil {
+		t.Fatal(err)
+	}
+
+	sentinel.setMaster("mymaster", alive.addr())
+	setVia(t, s, "k")
+	if _, ok := alive.get(0, "k"); !ok {
+		t.Error("write did not reach the master reported after the failed borrow")
+	}
+}
+
+func TestSentinelPoolUnknownMaster(t *testing.T) {
+	sentinel := newFakeRedis(t)
+	s := newTestSentinelPool(t, sentinel)
+	if _, err := s.GetResource(); err == nil {
+		t.Error("GetResource() succeeded for a master the sentinel does not know")
+	}
+}
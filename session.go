@@ -0,0 +1,69 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+)
+
+// sessionTokenSize is the number of random bytes in a session token.
+const sessionTokenSize = 32
+
+// sessionKey is <prefix>:session:<hex SM3 of the token>. Only the hash appears in key names,
+// so a redis key listing does not reveal live tokens.
+func (p *MyPlugin) sessionKey(req *http.Request, token string) string {
+	return p.keyPrefix(req) + ":session:" + hex.EncodeToString(sm3Sum([]byte(token)))
+}
+
+// serveSessionIssue creates a session for a random token and returns the token in the session
+// cookie. The nonce has already been checked by the caller, so a replayed issuance request is
+// rejected before it gets here.
+func (p *MyPlugin) serveSessionIssue(conn redisConn, rw http.ResponseWriter, req *http.Request) {
+	b := make([]byte, sessionTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		p.logger.Error("生成会话令牌失败", logFields{"error": err})
+		p.writeError(rw, http.StatusInternalServerError, "session issuance failed")
+		return
+	}
+	token := hex.EncodeToString(b)
+
+	if _, err := conn.SetEx(p.sessionKey(req, token), p.sessionTTL, token); err != nil {
+		p.logger.Error("写入会话失败", logFields{"error": err})
+		p.writeError(rw, http.StatusServiceUnavailable, "session store unavailable")
+		return
+	}
+
+	http.SetCookie(rw, &http.Cookie{
+		Name:     p.sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   p.sessionTTL,
+		HttpOnly: true,
+		Secure:   req.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"code": 0, "message": "ok", "expiresIn": p.sessionTTL})
+}
+
+// checkSession validates the session cookie when the request carries one. It answers 401 and
+// returns false for an unknown or expired session. Requests without the cookie pass.
+func (p *MyPlugin) checkSession(conn redisConn, rw http.ResponseWriter, req *http.Request) bool {
+	cookie, err := req.Cookie(p.sessionCookieName)
+	if err != nil {
+		return true
+	}
+
+	stored, err := conn.Get(p.sessionKey(req, cookie.Value))
+	if err != nil {
+		// 无法确认会话是否有效时拒绝请求
+		p.logger.Error("读取会话失败", logFields{"error": err})
+		p.writeError(rw, http.StatusServiceUnavailable, "session store unavailable")
+		return false
+	}
+	if stored == "" || subtle.ConstantTimeCompare([]byte(stored), []byte(cookie.Value)) != 1 {
+		p.writeError(rw, http.StatusUnauthorized, "invalid or expired session")
+		return false
+	}
+	return true
+}
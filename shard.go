@@ -0,0 +1,169 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/piaohao/godis"
+)
+
+// shardKeepAliveInterval is how often warm shard connections are pinged.
+const shardKeepAliveInterval = 30 * time.Second
+
+// shardedRedis spreads hash keys over one redis database per shard. Every shard has its own
+// connection pool, so concurrent requests never share a connection.
+type shardedRedis struct {
+	shards []redisPool
+	// ring 非空时按一致性 hash 选择分片, 否则按首字节取模
+	ring *HashRing
+
+	logger *logger
+}
+
+// newShardedRedis creates count shards, shard i using redis database firstDB+i.
+func newShardedRedis(option godis.Option, config godis.PoolConfig, firstDB, count int, logger *logger) *shardedRedis {
+	s := &shardedRedis{logger: logger}
+	for i := 0; i < count; i++ {
+		shardOption := option
+		shardOption.Db = firstDB + i
+		s.shards = append(s.shards, godis.NewPool(&config, &shardOption))
+	}
+	return s
+}
+
+// with runs fn on a connection borrowed from shard i and returns it to the pool.
+func (s *shardedRedis) with(i int, fn func(r *godis.Redis) error) error {
+	r, err := s.shards[i].GetResource()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return fn(r)
+}
+
+// setOn stores value under key on shard i.
+func (s *shardedRedis) setOn(i int, key, value string) (reply string, err error) {
+	err = s.with(i, func(r *godis.Redis) error {
+		reply, err = r.Set(key, value)
+		return err
+	})
+	return reply, err
+}
+
+// getFrom reads key from shard i.
+func (s *shardedRedis) getFrom(i int, key string) (value string, err error) {
+	err = s.with(i, func(r *godis.Redis) error {
+		value, err = r.Get(key)
+		return err
+	})
+	return value, err
+}
+
+// shardFor returns the shard index of key on the consistent hash ring if one is configured,
+// otherwise of a hex encoded hash: int(hash[0]) % ShardCount.
+// Keys that are not hex are placed by the first byte of their SM3 digest.
+func (s *shardedRedis) shardFor(key string) int {
+	if s.ring != nil {
+		return s.ring.FindShard(key)
+	}
+	if len(key) >= 2 {
+		if b, err := hex.DecodeString(key[:2]); err == nil {
+			return int(b[0]) % len(s.shards)
+		}
+	}
+	return int(sm3Sum([]byte(key))[0]) % len(s.shards)
+}
+
+// Set stores value under key on the shard owning key.
+func (s *shardedRedis) Set(key, value string) (string, error) {
+	return s.setOn(s.shardFor(key), key, value)
+}
+
+// Get reads key from the shard owning key.
+func (s *shardedRedis) Get(key string) (string, error) {
+	return s.getFrom(s.shardFor(key), key)
+}
+
+// SetEx stores value under key with a TTL on the shard owning key.
+func (s *shardedRedis) SetEx(key string, seconds int, value string) (reply string, err error) {
+	err = s.with(s.shardFor(key), func(r *godis.Redis) error {
+		reply, err = r.SetEx(key, seconds, value)
+		return err
+	})
+	return reply, err
+}
+
+// Del deletes keys that may span several shards, issuing one DEL per shard in parallel.
+func (s *shardedRedis) Del(keys ...string) (int64, error) {
+	byShard := make(map[int][]string)
+	for _, key := range keys {
+		i := s.shardFor(key)
+		byShard[i] = append(byShard[i], key)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		deleted  int64
+		firstErr error
+	)
+	for i, shardKeys := range byShard {
+		wg.Add(1)
+		go func(i int, shardKeys []string) {
+			defer wg.Done()
+			var n int64
+			err := s.with(i, func(r *godis.Redis) (err error) {
+				n, err = r.Del(shardKeys...)
+				return err
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			deleted += n
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}(i, shardKeys)
+	}
+	wg.Wait()
+
+	return deleted, firstErr
+}
+
+// close destroys every shard pool.
+func (s *shardedRedis) close() {
+	for _, shard := range s.shards {
+		shard.Destroy()
+	}
+}
+
+// keepWarm connects every shard up front and pings them periodically until ctx is done.
+func (s *shardedRedis) keepWarm(ctx context.Context) {
+	ping := func() {
+		for i := range s.shards {
+			err := s.with(i, func(r *godis.Redis) error {
+				_, err := r.Ping()
+				return err
+			})
+			if err != nil {
+				s.logger.Error("redis 分片心跳失败", logFields{"shard": i, "error": err})
+			}
+		}
+	}
+	ping()
+
+	go func() {
+		ticker := time.NewTicker(shardKeepAliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ping()
+			}
+		}
+	}()
+}
@@ -0,0 +1,119 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/piaohao/godis"
+)
+
+func newTestShards(t *testing.T, f *fakeRedis, count int) *shardedRedis {
+	t.Helper()
+	s := newShardedRedis(f.option(0), godis.PoolConfig{MaxTotal: 8}, 0, count, newLogger(io.Discard, "error"))
+	t.Cleanup(s.close)
+	return s
+}
+
+func TestShardFor(t *testing.T) {
+	s := &shardedRedis{shards: make([]redisPool, 4)}
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"00ff", 0},
+		{"01ff", 1},
+		{"ab00", 0xab % 4},
+		{"ff", 0xff % 4},
+		{"not-hex", int(sm3Sum([]byte("not-hex"))[0]) % 4},
+	}
+	for _, tt := range tests {
+		if got := s.shardFor(tt.key); got != tt.want {
+			t.Errorf("shardFor(%q) = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestShardedRedisRouting(t *testing.T) {
+	f := newFakeRedis(t)
+	s := newTestShards(t, f, 4)
+
+	for i := 0; i < 16; i++ {
+		hash := hex.EncodeToString(sm3Sum([]byte(fmt.Sprint(i))))
+		if _, err := s.Set(hash, "1"); err != nil {
+			t.Fatal(err)
+		}
+		shard := s.shardFor(hash)
+		for db := 0; db < 4; db++ {
+			if _, ok := f.get(db, hash); ok != (db == shard) {
+				t.Errorf("hash %s in db %d = %v, want it only in db %d", hash, db, ok, shard)
+			}
+		}
+		if got, err := s.Get(hash); err != nil || got != "1" {
+			t.Errorf("Get(%s) = %q, %v, want it read back from its shard", hash, got, err)
+		}
+	}
+}
+
+func TestShardedRedisSetEx(t *testing.T) {
+	f := newFakeRedis(t)
+	s := newTestShards(t, f, 3)
+
+	if _, err := s.SetEx("02aa", 60, "v"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.get(2, "02aa"); !ok {
+		t.Errorf("SetEx did not write to shard 2")
+	}
+}
+
+func TestShardedRedisDelAcrossShards(t *testing.T) {
+	f := newFakeRedis(t)
+	s := newTestShards(t, f, 4)
+
+	keys := []string{"00aa", "01aa", "02aa", "03aa", "04aa"}
+	for _, key := range keys {
+		if _, err := s.Set(key, "1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := s.Del(append(keys, "05aa")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(keys)) {
+		t.Errorf("Del() = %d, want %d", n, len(keys))
+	}
+	for _, key := range keys {
+		if _, ok := f.get(s.shardFor(key), key); ok {
+			t.Errorf("%s was not deleted", key)
+		}
+	}
+}
+
+// Concurrent requests must each get their own reply: a shared connection interleaves them.
+func TestShardedRedisConcurrent(t *testing.T) {
+	f := newFakeRedis(t)
+	s := newTestShards(t, f, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := hex.EncodeToString([]byte{byte(i)}) + "00"
+			value := fmt.Sprint(i)
+			if _, err := s.Set(key, value); err != nil {
+				t.Error(err)
+				return
+			}
+			if got, err := s.Get(key); err != nil || got != value {
+				t.Errorf("Get(%s) = %q, %v, want %q", key, got, err, value)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,130 @@
+package gmsmPlugin
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// parseSM2PrivateKey parses an unencrypted PKCS#8 PEM encoded SM2 private key.
+func parseSM2PrivateKey(pemStr string) (*sm2.PrivateKey, error) {
+	return x509.ReadPrivateKeyFromPem([]byte(pemStr), nil)
+}
+
+// marshalPoint encodes a point on the SM2 curve in SEC1 compressed form.
+func marshalPoint(x, y *big.Int) []byte {
+	out := make([]byte, 33)
+	out[0] = 0x02 + byte(y.Bit(0))
+	x.FillBytes(out[1:])
+	return out
+}
+
+// unmarshalPoint decodes a SEC1 compressed point on the SM2 curve.
+// It returns nil, nil if data is not a valid point.
+func unmarshalPoint(data []byte) (*big.Int, *big.Int) {
+	if len(data) != 33 || (data[0] != 0x02 && data[0] != 0x03) {
+		return nil, nil
+	}
+	params := sm2.P256Sm2().Params()
+	x := new(big.Int).SetBytes(data[1:])
+	if x.Cmp(params.P) >= 0 {
+		return nil, nil
+	}
+
+	// y² = x³ - 3x + b
+	y2 := new(big.Int).Exp(x, big.NewInt(3), params.P)
+	threeX := new(big.Int).Mul(x, big.NewInt(3))
+	y2.Sub(y2, threeX)
+	y2.Add(y2, params.B)
+	y2.Mod(y2, params.P)
+
+	y := new(big.Int).ModSqrt(y2, params.P)
+	if y == nil {
+		return nil, nil
+	}
+	if y.Bit(0) != uint(data[0]&1) {
+		y.Sub(params.P, y)
+	}
+	return x, y
+}
+
+// scalarBytes encodes k as a 32-byte big-endian scalar.
+func scalarBytes(k *big.Int) []byte {
+	return k.FillBytes(make([]byte, 32))
+}
+
+// toSM2PublicKey converts a public key parsed by the gmsm x509 package to *sm2.PublicKey.
+// SM2 keys in certificates and CSRs are parsed as *ecdsa.PublicKey on the SM2 curve.
+func toSM2PublicKey(pub interface{}) (*sm2.PublicKey, error) {
+	switch key := pub.(type) {
+	case *sm2.PublicKey:
+		return key, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != sm2.P256Sm2() || !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("not an SM2 public key")
+		}
+		return &sm2.PublicKey{Curve: key.Curve, X: key.X, Y: key.Y}, nil
+	default:
+		return nil, errors.New("not an SM2 public key")
+	}
+}
+
+// serveSM2Encrypt encrypts the body with the SM2 public key (C1C3C2) and answers with the
+// base64 ciphertext.
+func (p *MyPlugin) serveSM2Encrypt(rw http.ResponseWriter, body []byte) {
+	ciphertext, err := sm2.Encrypt(p.sm2PublicKey, body, rand.Reader, sm2.C1C3C2)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(rw, http.StatusOK, map[string]interface{}{
+		"result":  base64.StdEncoding.EncodeToString(ciphertext),
+		"code":    0,
+		"message": "ok",
+	})
+}
+
+// forwardSM2Decrypt decrypts a base64 SM2 ciphertext body with the private key and passes the
+// plaintext to the next handler.
+func (p *MyPlugin) forwardSM2Decrypt(rw http.ResponseWriter, req *http.Request, body []byte) {
+	ciphertext, err := base64.StdEncoding.DecodeString(string(body))
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, "body must be base64 encoded ciphertext")
+		return
+	}
+	// 0x04 || C1(64) || C3(32) || C2
+	if len(ciphertext) < 97 {
+		p.writeError(rw, http.StatusBadRequest, "ciphertext too short")
+		return
+	}
+	plaintext, err := sm2.Decrypt(p.sm2PrivateKey, ciphertext, sm2.C1C3C2)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, "decryption failed")
+		return
+	}
+
+	restoreBody(req, plaintext)
+	req.Header.Set("Content-Length", strconv.Itoa(len(plaintext)))
+	p.next.ServeHTTP(rw, req)
+}
+
+// rejectBodyTooLarge answers 413 for a body that went past the size limit, logs the client
+// and closes the body. MaxBytesReader has already told the server to close the connection.
+func (p *MyPlugin) rejectBodyTooLarge(rw http.ResponseWriter, req *http.Request) {
+	p.logger.Warn("请求体超过大小限制", logFields{"ip": clientIP(req), "read": p.maxBodyBytes})
+	req.Body.Close()
+	p.writeError(rw, http.StatusRequestEntityTooLarge, "body too large")
+}
+
+// isBodyTooLarge reports whether err comes from reading past the body size limit.
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
@@ -0,0 +1,234 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// sm2SignatureFormats lists the SM2SignatureFormat values:
+// "der" hex of the ASN.1 DER signature, "raw64" base64url of R || S (32 bytes each),
+// "raw_hex" hex of R || S and "cms" base64 of a detached CMS SignedData.
+var sm2SignatureFormats = map[string]bool{
+	"der":     true,
+	"raw64":   true,
+	"raw_hex": true,
+	"cms":     true,
+}
+
+var (
+	oidData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidSM3           = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 401}
+	oidSM2WithSM3    = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}
+	errSM2SigInvalid = errors.New("unrecognised SM2 signature encoding")
+)
+
+// sm2Signature is the ASN.1 form of an SM2 signature.
+type sm2Signature struct {
+	R, S *big.Int
+}
+
+type cmsAlgorithm struct {
+	Algorithm asn1.ObjectIdentifier
+}
+
+type cmsContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type cmsEncapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+}
+
+type cmsSignerInfo struct {
+	Version            int
+	SubjectKeyID       []byte `asn1:"tag:0"`
+	DigestAlgorithm    cmsAlgorithm
+	SignatureAlgorithm cmsAlgorithm
+	Signature          []byte
+}
+
+type cmsSignedData struct {
+	Version          int
+	DigestAlgorithms []cmsAlgorithm `asn1:"set"`
+	EncapContentInfo cmsEncapContentInfo
+	SignerInfos      []cmsSignerInfo `asn1:"set"`
+}
+
+// sm2SubjectKeyID identifies the signer in CMS by the SM3 of its uncompressed public key point.
+func sm2SubjectKeyID(pub *sm2.PublicKey) []byte {
+	point := make([]byte, 65)
+	point[0] = 0x04
+	pub.X.FillBytes(point[1:33])
+	pub.Y.FillBytes(point[33:])
+	return sm3Sum(point)
+}
+
+// encodeSM2Signature encodes a DER SM2 signature made by pub in the given format.
+func encodeSM2Signature(der []byte, pub *sm2.PublicKey, format string) (string, error) {
+	switch format {
+	case "raw64", "raw_hex":
+		var sig sm2Signature
+		if _, err := asn1.Unmarshal(der, &sig); err != nil {
+			return "", err
+		}
+		raw := make([]byte, 64)
+		sig.R.FillBytes(raw[:32])
+		sig.S.FillBytes(raw[32:])
+		if format == "raw64" {
+			return base64.RawURLEncoding.EncodeToString(raw), nil
+		}
+		return hex.EncodeToString(raw), nil
+	case "cms":
+		signedData, err := asn1.Marshal(cmsSignedData{
+			Version:          3,
+			DigestAlgorithms: []cmsAlgorithm{{Algorithm: oidSM3}},
+			EncapContentInfo: cmsEncapContentInfo{EContentType: oidData},
+			SignerInfos: []cmsSignerInfo{{
+				Version:            3,
+				SubjectKeyID:       sm2SubjectKeyID(pub),
+				DigestAlgorithm:    cmsAlgorithm{Algorithm: oidSM3},
+				SignatureAlgorithm: cmsAlgorithm{Algorithm: oidSM2WithSM3},
+				Signature:          der,
+			}},
+		})
+		if err != nil {
+			return "", err
+		}
+		contentInfo, err := asn1.Marshal(cmsContentInfo{
+			ContentType: oidSignedData,
+			Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+		})
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(contentInfo), nil
+	default:
+		return hex.EncodeToString(der), nil
+	}
+}
+
+// cmsSignature extracts the DER signature of the first signer of a CMS SignedData.
+func cmsSignature(data []byte) ([]byte, error) {
+	var contentInfo cmsContentInfo
+	if rest, err := asn1.Unmarshal(data, &contentInfo); err != nil || len(rest) > 0 {
+		return nil, errSM2SigInvalid
+	}
+	if !contentInfo.ContentType.Equal(oidSignedData) {
+		return nil, errSM2SigInvalid
+	}
+	var signedData cmsSignedData
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil || len(signedData.SignerInfos) == 0 {
+		return nil, errSM2SigInvalid
+	}
+	return signedData.SignerInfos[0].Signature, nil
+}
+
+// rawToDER converts a 64-byte R || S signature to DER.
+func rawToDER(raw []byte) ([]byte, error) {
+	return asn1.Marshal(sm2Signature{R: new(big.Int).SetBytes(raw[:32]), S: new(big.Int).SetBytes(raw[32:])})
+}
+
+// isDERSignature reports whether data is exactly an ASN.1 SEQUENCE of two INTEGERs.
+func isDERSignature(data []byte) bool {
+	var sig sm2Signature
+	rest, err := asn1.Unmarshal(data, &sig)
+	return err == nil && len(rest) == 0
+}
+
+// decodeSM2Signature detects the encoding of signature (any of sm2SignatureFormats) by its
+// length and prefix and returns the DER signature.
+func decodeSM2Signature(signature string) ([]byte, error) {
+	if raw, err := hex.DecodeString(signature); err == nil {
+		if len(raw) == 64 {
+			return rawToDER(raw)
+		}
+		if isDERSignature(raw) {
+			return raw, nil
+		}
+	}
+	if len(signature) == 86 {
+		if raw, err := base64.RawURLEncoding.DecodeString(signature); err == nil && len(raw) == 64 {
+			return rawToDER(raw)
+		}
+	}
+	if data, err := base64.StdEncoding.DecodeString(signature); err == nil && len(data) > 0 && data[0] == 0x30 {
+		if isDERSignature(data) {
+			return data, nil
+		}
+		return cmsSignature(data)
+	}
+	return nil, errSM2SigInvalid
+}
+
+// VerifySM2Signature verifies an SM2 signature over msg in any supported encoding.
+func VerifySM2Signature(pub *sm2.PublicKey, msg []byte, signature string) bool {
+	der, err := decodeSM2Signature(signature)
+	if err != nil {
+		return false
+	}
+	return pub.Verify(msg, der)
+}
+
+// signSM2 signs msg with the SM2 key and encodes the signature in the configured format.
+func (p *MyPlugin) signSM2(msg []byte) (string, error) {
+	der, err := p.sm2PrivateKey.Sign(rand.Reader, msg, nil)
+	if err != nil {
+		return "", err
+	}
+	return encodeSM2Signature(der, &p.sm2PrivateKey.PublicKey, p.sm2SignatureFormat)
+}
+
+// serveSM2Sign signs the body and returns the encoded signature.
+func (p *MyPlugin) serveSM2Sign(rw http.ResponseWriter, body []byte) {
+	signature, err := p.signSM2(body)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"signature": signature, "format": p.sm2SignatureFormat, "code": 0})
+}
+
+// signResponse signs the captured response body with the SM2 key, sets the signature encoded in
+// the configured format in X-SM2-Signature and sends the response on.
+func (p *MyPlugin) signResponse(capture *responseCapture) {
+	signature, err := p.signSM2(capture.body.Bytes())
+	if err != nil {
+		p.logger.Error("响应签名失败", logFields{"error": err})
+		capture.rw.Header().Del("Content-Length")
+		p.writeError(capture.rw, http.StatusInternalServerError, "response signing failed")
+		return
+	}
+	capture.Header().Set("X-SM2-Signature", signature)
+	capture.flush()
+}
+
+// verifyRequestSignature checks the SM2 signature in X-SM2-Signature, in any of
+// sm2SignatureFormats, over the raw body with the trusted public key. It answers 400 when the
+// header is missing or malformed and 401 when the signature does not verify, and returns false
+// in both cases.
+func (p *MyPlugin) verifyRequestSignature(rw http.ResponseWriter, req *http.Request, body []byte) bool {
+	header := req.Header.Get("X-SM2-Signature")
+	if header == "" {
+		p.writeError(rw, http.StatusBadRequest, "missing signature")
+		return false
+	}
+	signature, err := decodeSM2Signature(header)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, "malformed signature")
+		return false
+	}
+	if !p.sm2TrustedPublicKey.Verify(body, signature) {
+		p.writeError(rw, http.StatusUnauthorized, "signature mismatch")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,296 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+func testSM2Key(t *testing.T) *sm2.PrivateKey {
+	t.Helper()
+	key, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestSM2SignatureFormats(t *testing.T) {
+	key, other := testSM2Key(t), testSM2Key(t)
+	msg := []byte("transfer 100 CNY")
+
+	tests := []struct {
+		format string
+		valid  func(signature string) bool
+	}{
+		{"der", func(s string) bool { raw, err := hex.DecodeString(s); return err == nil && isDERSignature(raw) }},
+		{"raw_hex", func(s string) bool { return len(s) == 128 }},
+		{"raw64", func(s string) bool { return len(s) == 86 && !strings.ContainsAny(s, "+/=") }},
+		{"cms", func(s string) bool { _, err := base64.StdEncoding.DecodeString(s); return err == nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			p := &MyPlugin{sm2PrivateKey: key, sm2SignatureFormat: tt.format}
+			signature, err := p.signSM2(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.valid(signature) {
+				t.Errorf("signature %q is not in the %s encoding", signature, tt.format)
+			}
+			if !VerifySM2Signature(&key.PublicKey, msg, signature) {
+				t.Error("signature does not verify")
+			}
+			if VerifySM2Signature(&key.PublicKey, []byte("transfer 900 CNY"), signature) {
+				t.Error("signature verifies over another message")
+			}
+			if VerifySM2Signature(&other.PublicKey, msg, signature) {
+				t.Error("signature verifies under another key")
+			}
+		})
+	}
+}
+
+// A CMS signature unwrapped to DER must verify as an ordinary "der" signature.
+func TestSM2SignatureCMSToDER(t *testing.T) {
+	key := testSM2Key(t)
+	msg := []byte("transfer 100 CNY")
+
+	cms, err := (&MyPlugin{sm2PrivateKey: key, sm2SignatureFormat: "cms"}).signSM2(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(cms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := cmsSignature(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.PublicKey.Verify(msg, der) {
+		t.Error("DER signature extracted from CMS does not verify")
+	}
+	if !VerifySM2Signature(&key.PublicKey, msg, hex.EncodeToString(der)) {
+		t.Error("DER signature extracted from CMS is not accepted in the der format")
+	}
+}
+
+func TestDecodeSM2SignatureInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+	}{
+		{"empty", ""},
+		{"short hex", "abcd"},
+		{"not a signature", base64.StdEncoding.EncodeToString([]byte("0 not asn.1"))},
+		{"CMS of another type", base64.StdEncoding.EncodeToString([]byte{0x30, 0x03, 0x06, 0x01, 0x00})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeSM2Signature(tt.signature); err == nil {
+				t.Errorf("decodeSM2Signature(%q) succeeded", tt.signature)
+			}
+		})
+	}
+}
+
+func TestSignResponse(t *testing.T) {
+	key := testSM2Key(t)
+	p := &MyPlugin{sm2PrivateKey: key, sm2SignatureFormat: "raw64", logger: newLogger(io.Discard, "error")}
+
+	rw := httptest.NewRecorder()
+	capture := newResponseCapture(rw)
+	capture.WriteHeader(http.StatusCreated)
+	capture.Write([]byte(`{"id":1,`))
+	capture.Write([]byte(`"status":"created"}`))
+	p.signResponse(capture)
+
+	if rw.Code != http.StatusCreated {
+		t.Errorf("status = %d, want 201", rw.Code)
+	}
+	body := rw.Body.Bytes()
+	if string(body) != `{"id":1,"status":"created"}` {
+		t.Errorf("body = %s, want both writes", body)
+	}
+	signature := rw.Header().Get("X-SM2-Signature")
+	if len(signature) != 86 {
+		t.Errorf("X-SM2-Signature = %q, want the raw64 encoding", signature)
+	}
+	if !VerifySM2Signature(&key.PublicKey, body, signature) {
+		t.Error("X-SM2-Signature does not verify over the response body")
+	}
+	if VerifySM2Signature(&key.PublicKey, []byte(`{"id":2,"status":"created"}`), signature) {
+		t.Error("X-SM2-Signature verifies over another body")
+	}
+}
+
+// Through ServeHTTP the signature covers what the next handler wrote, in the configured format.
+func TestServeHTTPSignResponse(t *testing.T) {
+	key := testSM2Key(t)
+	f := newFakeRedis(t)
+
+	for format := range sm2SignatureFormats {
+		t.Run(format, func(t *testing.T) {
+			p := newTestPlugin(t, f, func(c *Config) {
+				c.SM2SignResponse = true
+				c.SM2PrivateKeyPEM = testSM2PrivateKeyPEM(t, key)
+				c.SM2SignatureFormat = format
+				c.SMAlgorithm = ""
+				c.DuplicateAction = "passthrough"
+			})
+
+			rw := httptest.NewRecorder()
+			p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("signed by the gateway")))
+
+			if rw.Body.String() != "signed by the gateway" {
+				t.Fatalf("body = %q", rw.Body)
+			}
+			signature := rw.Header().Get("X-SM2-Signature")
+			want, err := encodeSM2Signature(mustDecodeSM2Signature(t, signature), &key.PublicKey, format)
+			if err != nil || want != signature {
+				t.Errorf("X-SM2-Signature = %q is not in the %s encoding", signature, format)
+			}
+			if !VerifySM2Signature(&key.PublicKey, rw.Body.Bytes(), signature) {
+				t.Error("X-SM2-Signature does not verify with the matching public key")
+			}
+		})
+	}
+}
+
+func mustDecodeSM2Signature(t *testing.T, signature string) []byte {
+	t.Helper()
+	der, err := decodeSM2Signature(signature)
+	if err != nil {
+		t.Fatalf("decodeSM2Signature(%q): %v", signature, err)
+	}
+	return der
+}
+
+// A request signature is accepted in any of the formats the gateway signs with, whatever
+// SM2SignatureFormat is configured.
+func TestServeHTTPVerifyRequestSignature(t *testing.T) {
+	key, other := testSM2Key(t), testSM2Key(t)
+	publicKeyPEM, err := x509.WritePublicKeyToPem(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeRedis(t)
+	p := newTestPlugin(t, f, func(c *Config) {
+		c.SM2VerifyRequest = true
+		c.SM2TrustedPublicKeyPEM = string(publicKeyPEM)
+		c.SM2SignatureFormat = "der"
+		c.SMAlgorithm = ""
+		c.DuplicateAction = "passthrough"
+	})
+	body := "signed by the client"
+	sign := func(key *sm2.PrivateKey, format, msg string) string {
+		signature, err := (&MyPlugin{sm2PrivateKey: key, sm2SignatureFormat: format}).signSM2([]byte(msg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+	der, err := key.Sign(rand.Reader, []byte(body), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		signature  string
+		wantStatus int
+	}{
+		{"der", sign(key, "der", body), http.StatusOK},
+		{"raw64", sign(key, "raw64", body), http.StatusOK},
+		{"raw_hex", sign(key, "raw_hex", body), http.StatusOK},
+		{"cms", sign(key, "cms", body), http.StatusOK},
+		{"base64 der", base64.StdEncoding.EncodeToString(der), http.StatusOK},
+		{"raw64 over another body", sign(key, "raw64", "signed by someone else"), http.StatusUnauthorized},
+		{"raw64 by another key", sign(other, "raw64", body), http.StatusUnauthorized},
+		{"missing", "", http.StatusBadRequest},
+		{"malformed", "not a signature", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set("X-SM2-Signature", tt.signature)
+			}
+			rw := httptest.NewRecorder()
+			p.ServeHTTP(rw, req)
+
+			if rw.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rw.Code, tt.wantStatus, rw.Body)
+			}
+			if tt.wantStatus == http.StatusOK && rw.Body.String() != body {
+				t.Errorf("next handler got %q, want %q", rw.Body, body)
+			}
+		})
+	}
+}
+
+// SM2VERIFY answers the verification itself, here for requests routed to it by content type.
+func TestServeHTTPSM2Verify(t *testing.T) {
+	key := testSM2Key(t)
+	publicKeyPEM, err := x509.WritePublicKeyToPem(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeRedis(t)
+	p := newTestPlugin(t, f, func(c *Config) {
+		c.SM2TrustedPublicKeyPEM = string(publicKeyPEM)
+		c.MIMEAlgorithmRouting = map[string]string{"application/x-sm2-signed": "SM2VERIFY"}
+		c.DuplicateAction = "passthrough"
+		c.ForwardToNext = false
+	})
+	body := "signed by the client"
+	signer := &MyPlugin{sm2PrivateKey: key, sm2SignatureFormat: "raw64"}
+	signature, err := signer.signSM2([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSignature, err := signer.signSM2([]byte("signed by someone else"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		signature   string
+		wantStatus  int
+		wantBody    string
+	}{
+		{"valid signature", "application/x-sm2-signed", signature, http.StatusOK, `"code":0`},
+		{"another body's signature", "application/x-sm2-signed", otherSignature, http.StatusUnauthorized, "signature mismatch"},
+		{"missing signature", "application/x-sm2-signed", "", http.StatusBadRequest, "missing signature"},
+		{"other content type", "text/plain", "", http.StatusOK, `"result"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.signature != "" {
+				req.Header.Set("X-SM2-Signature", tt.signature)
+			}
+			rw := httptest.NewRecorder()
+			p.ServeHTTP(rw, req)
+
+			if rw.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rw.Code, tt.wantStatus, rw.Body)
+			}
+			if !strings.Contains(rw.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rw.Body, tt.wantBody)
+			}
+		})
+	}
+}
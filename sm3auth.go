@@ -0,0 +1,46 @@
+package gmsmPlugin
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// originalAuthorizationHeader keeps the caller's Authorization header once InjectSM3Auth replaces it.
+const originalAuthorizationHeader = "X-Original-Authorization"
+
+// setSM3Authorization sets "Authorization: <scheme> <hex SM3 of body>" on the request passed to the
+// next handler, saving any existing Authorization header as X-Original-Authorization.
+// Empty bodies are left alone unless HashEmptyBody is set.
+func (p *MyPlugin) setSM3Authorization(req *http.Request, body []byte) {
+	if len(body) == 0 && !p.hashEmptyBody {
+		return
+	}
+	if original := req.Header.Get("Authorization"); original != "" {
+		req.Header.Set(originalAuthorizationHeader, original)
+	}
+	req.Header.Set("Authorization", p.sm3AuthScheme+" "+hex.EncodeToString(sm3Sum(body)))
+}
+
+// challengeSM3Auth answers 401 with a WWW-Authenticate challenge for the SM3 scheme.
+func (p *MyPlugin) challengeSM3Auth(rw http.ResponseWriter, message string) {
+	rw.Header().Set("WWW-Authenticate", p.sm3AuthScheme+` realm="`+p.realm+`"`)
+	p.writeError(rw, http.StatusUnauthorized, message)
+}
+
+// verifySM3Authorization checks "Authorization: <scheme> <hex SM3 of body>" against the body
+// actually received. The scheme is matched case-insensitively and the hash in constant time.
+func (p *MyPlugin) verifySM3Authorization(rw http.ResponseWriter, req *http.Request, body []byte) bool {
+	scheme, credentials, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, p.sm3AuthScheme) {
+		p.challengeSM3Auth(rw, "unsupported authorization scheme")
+		return false
+	}
+	provided, err := hex.DecodeString(strings.TrimSpace(credentials))
+	if err != nil || subtle.ConstantTimeCompare(provided, sm3Sum(body)) != 1 {
+		p.challengeSM3Auth(rw, "body hash mismatch")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+
+	"github.com/tjfoc/gmsm/sm3"
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+// errDecryptionFailed is the only error SM4 decryption returns for bad input, whether the tag,
+// the padding or the key is wrong, so that callers cannot be used as a padding oracle.
+var errDecryptionFailed = errors.New("decryption failed")
+
+// sm4CBCTagSize is the length of the HMAC-SM3 tag (an SM3 digest) appended to SM4-CBC ciphertext.
+const sm4CBCTagSize = 32
+
+// sm4CBCEncrypt encrypts plaintext with SM4-CBC and PKCS#7 padding.
+// sm4.Sm4Cbc uses a package-level IV, so the cipher.BlockMode is built here instead.
+func sm4CBCEncrypt(key, iv, plaintext []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != sm4.BlockSize {
+		return nil, errors.New("SM4: invalid iv size")
+	}
+
+	padded := pkcs7Pad(plaintext)
+	out := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, padded)
+	return out, nil
+}
+
+// randomIV returns a fresh IV read from crypto/rand.
+func randomIV() ([]byte, error) {
+	iv := make([]byte, sm4.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	return iv, nil
+}
+
+// deriveIV derives a deterministic SM4 IV from the request metadata:
+// the first 16 bytes of SM3(key || method || path || clientID || seqNo).
+// seqNo is encoded as 8 bytes big-endian.
+func deriveIV(key []byte, method, path, clientID string, seqNo uint64) []byte {
+	seq := make([]byte, 8)
+	binary.BigEndian.PutUint64(seq, seqNo)
+
+	hasher := sm3.New()
+	hasher.Write(key)
+	hasher.Write([]byte(method))
+	hasher.Write([]byte(path))
+	hasher.Write([]byte(clientID))
+	hasher.Write(seq)
+	return hasher.Sum(nil)[:sm4.BlockSize]
+}
+
+// VerifyIV reports whether iv is the deterministic IV derived from the given request metadata.
+func VerifyIV(key []byte, method, path, clientID string, seqNo uint64, iv []byte) bool {
+	return subtle.ConstantTimeCompare(deriveIV(key, method, path, clientID, seqNo), iv) == 1
+}
+
+// passphraseSM4Key derives an SM4 key as the first 16 bytes of SM3(salt || passphrase).
+// A single SM3 has no work factor, so weak passphrases are cheap to brute-force: this is only
+// meant for moderate security needs, production keys belong in an HSM.
+func passphraseSM4Key(passphrase string, salt []byte) []byte {
+	return sm3Sum(append(append([]byte(nil), salt...), passphrase...))[:sm4.BlockSize]
+}
+
+// sm4CBCDecrypt decrypts SM4-CBC ciphertext and removes the PKCS#7 padding.
+// All failures are reported as errDecryptionFailed.
+func sm4CBCDecrypt(key, iv, ciphertext []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil || len(iv) != sm4.BlockSize {
+		return nil, errDecryptionFailed
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%sm4.BlockSize != 0 {
+		return nil, errDecryptionFailed
+	}
+
+	out := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, ciphertext)
+	return pkcs7Unpad(out)
+}
+
+// sm4CBCTag returns HMAC-SM3(macKey, iv || data) over the ciphertext as sent, including any
+// compression flag.
+func sm4CBCTag(macKey, iv, data []byte) []byte {
+	return sm3HMAC(macKey, append(append([]byte(nil), iv...), data...))
+}
+
+// appendCBCTag appends the HMAC-SM3 tag of iv and data to data (encrypt-then-MAC).
+func appendCBCTag(macKey, iv, data []byte) []byte {
+	return append(data, sm4CBCTag(macKey, iv, data)...)
+}
+
+// checkCBCTag verifies and strips the tag appended by appendCBCTag. It runs before any
+// decryption, so tampered ciphertext never reaches the unpadding.
+func checkCBCTag(macKey, iv, data []byte) ([]byte, error) {
+	if len(data) < sm4CBCTagSize {
+		return nil, errDecryptionFailed
+	}
+	body, tag := data[:len(data)-sm4CBCTagSize], data[len(data)-sm4CBCTagSize:]
+	if subtle.ConstantTimeCompare(tag, sm4CBCTag(macKey, iv, body)) != 1 {
+		return nil, errDecryptionFailed
+	}
+	return body, nil
+}
+
+// sm4ECBEncrypt encrypts plaintext with SM4-ECB and PKCS#7 padding.
+func sm4ECBEncrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := pkcs7Pad(plaintext)
+	for i := 0; i < len(out); i += sm4.BlockSize {
+		block.Encrypt(out[i:i+sm4.BlockSize], out[i:i+sm4.BlockSize])
+	}
+	return out, nil
+}
+
+// sm4ECBDecrypt decrypts SM4-ECB ciphertext and removes the PKCS#7 padding.
+func sm4ECBDecrypt(key, ciphertext []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%sm4.BlockSize != 0 {
+		return nil, errDecryptionFailed
+	}
+
+	out := make([]byte, len(ciphertext))
+	for i := 0; i < len(out); i += sm4.BlockSize {
+		block.Decrypt(out[i:i+sm4.BlockSize], ciphertext[i:i+sm4.BlockSize])
+	}
+	return pkcs7Unpad(out)
+}
+
+// pkcs7Pad returns a copy of data padded to a multiple of the SM4 block size.
+func pkcs7Pad(data []byte) []byte {
+	padding := sm4.BlockSize - len(data)%sm4.BlockSize
+	return append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+}
+
+// pkcs7Unpad removes and checks PKCS#7 padding. data must be a non-empty multiple of the block
+// size. The whole last block is examined whatever the padding value, so the time taken does not
+// depend on where the padding goes wrong.
+func pkcs7Unpad(data []byte) ([]byte, error) {
+	last := data[len(data)-sm4.BlockSize:]
+	padding := last[sm4.BlockSize-1]
+
+	// 1 <= padding <= BlockSize
+	good := subtle.ConstantTimeLessOrEq(1, int(padding)) & subtle.ConstantTimeLessOrEq(int(padding), sm4.BlockSize)
+	for i := 0; i < sm4.BlockSize; i++ {
+		// 最后 padding 个字节必须都等于 padding
+		inPadding := subtle.ConstantTimeLessOrEq(sm4.BlockSize-i, int(padding))
+		match := subtle.ConstantTimeByteEq(last[i], padding)
+		good &= subtle.ConstantTimeSelect(inPadding, match, 1)
+	}
+	if good != 1 {
+		return nil, errDecryptionFailed
+	}
+	return data[:len(data)-int(padding)], nil
+}
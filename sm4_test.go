@@ -0,0 +1,221 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+func TestDeriveIV(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	iv := deriveIV(key, "POST", "/pay", "client-1", 1)
+
+	tests := []struct {
+		name     string
+		key      []byte
+		method   string
+		path     string
+		clientID string
+		seq      uint64
+		want     bool
+	}{
+		{"same metadata", key, "POST", "/pay", "client-1", 1, true},
+		{"next sequence number", key, "POST", "/pay", "client-1", 2, false},
+		{"other method", key, "PUT", "/pay", "client-1", 1, false},
+		{"other path", key, "POST", "/refund", "client-1", 1, false},
+		{"other client", key, "POST", "/pay", "client-2", 1, false},
+		{"other key", []byte("fedcba9876543210"), "POST", "/pay", "client-1", 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyIV(tt.key, tt.method, tt.path, tt.clientID, tt.seq, iv); got != tt.want {
+				t.Errorf("VerifyIV() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Each request takes the next number from the counter under the request's key prefix, so two
+// identical requests still get different IVs.
+func TestServeSM4DeterministicIV(t *testing.T) {
+	f := newFakeRedis(t)
+	key := []byte("0123456789abcdef")
+	p := &MyPlugin{
+		redisKeyPrefix:     "gmsm",
+		sm4DeterministicIV: true,
+		logger:             newLogger(io.Discard, "error"),
+	}
+	p.keys.Store(&runtimeKeys{sm4Key: key})
+	conn := f.conn(t, 0)
+
+	ivs := make(map[string]bool)
+	for seq := int64(1); seq <= 2; seq++ {
+		req := httptest.NewRequest(http.MethodPost, "/pay", strings.NewReader("amount=1"))
+		req.Header.Set("X-Client-ID", "client-1")
+		req = req.WithContext(context.WithValue(req.Context(), namespaceContextKey{}, "tenant"))
+		rw := httptest.NewRecorder()
+		p.serveSM4(conn, rw, req, []byte("amount=1"))
+
+		var response struct {
+			IV  string `json:"iv"`
+			Seq int64  `json:"seq"`
+		}
+		if err := json.Unmarshal(rw.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: %v", rw.Body, err)
+		}
+		if response.Seq != seq {
+			t.Errorf("seq = %d, want %d", response.Seq, seq)
+		}
+		iv, _ := hex.DecodeString(response.IV)
+		if !VerifyIV(key, http.MethodPost, "/pay", "client-1", uint64(seq), iv) {
+			t.Errorf("iv %s does not verify for seq %d", response.IV, seq)
+		}
+		ivs[response.IV] = true
+	}
+	if len(ivs) != 2 {
+		t.Errorf("two requests produced %d distinct IVs, want 2", len(ivs))
+	}
+	if v, _ := f.get(0, "tenant:gmsm:sm4:seq"); v != "2" {
+		t.Errorf("tenant:gmsm:sm4:seq = %q, want 2", v)
+	}
+	if _, ok := f.get(0, "gmsm:sm4:seq"); ok {
+		t.Error("sequence number kept under the unprefixed key")
+	}
+}
+
+// Bad padding under a valid tag, a bad tag and the wrong key must all produce the same response,
+// or the decrypt path is a padding oracle.
+func TestForwardSM4DecryptFailures(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	macKey := []byte("fedcba9876543210")
+	iv := make([]byte, sm4.BlockSize)
+	p := &MyPlugin{
+		sm4IV:  iv,
+		logger: newLogger(io.Discard, "error"),
+		next: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			rw.Write(body)
+		}),
+	}
+	p.keys.Store(&runtimeKeys{sm4Key: key, sm4CBCMACKey: macKey})
+
+	// 最后一个字节为 0, 不是合法的 PKCS#7 填充
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	badPadding := make([]byte, sm4.BlockSize)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(badPadding, []byte("fourteen bytes\x00\x00"))
+
+	valid, err := sm4CBCEncrypt(key, iv, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	badMAC := appendCBCTag(macKey, iv, append([]byte(nil), valid...))
+	badMAC[len(badMAC)-1] ^= 1
+	wrongKey, err := sm4CBCEncrypt([]byte("another-sm4-key!"), iv, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decrypt := func(ciphertext []byte) *httptest.ResponseRecorder {
+		body := base64.StdEncoding.EncodeToString(ciphertext)
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		rw := httptest.NewRecorder()
+		p.forwardSM4(rw, req, []byte(body), "SM4-CBC-DECRYPT")
+		return rw
+	}
+
+	if rw := decrypt(appendCBCTag(macKey, iv, append([]byte(nil), valid...))); rw.Code != http.StatusOK || rw.Body.String() != "hello" {
+		t.Fatalf("valid ciphertext: status %d, body %q", rw.Code, rw.Body)
+	}
+
+	want := decrypt(badMAC)
+	tests := []struct {
+		name       string
+		ciphertext []byte
+	}{
+		{"bad padding with a valid tag", appendCBCTag(macKey, iv, badPadding)},
+		{"wrong key with a valid tag", appendCBCTag(macKey, iv, wrongKey)},
+		{"truncated tag", appendCBCTag(macKey, iv, append([]byte(nil), valid...))[:len(valid)+10]},
+		{"empty", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := decrypt(tt.ciphertext)
+			if rw.Code != want.Code || rw.Body.String() != want.Body.String() {
+				t.Errorf("got %d %s, want the bad-MAC response %d %s", rw.Code, rw.Body, want.Code, want.Body)
+			}
+		})
+	}
+	if want.Code != http.StatusBadRequest || !strings.Contains(want.Body.String(), errDecryptionFailed.Error()) {
+		t.Errorf("bad MAC: got %d %s, want 400 %q", want.Code, want.Body, errDecryptionFailed)
+	}
+}
+
+// The expected keys are the first 16 bytes of the SM3 example digests in GB/T 32905-2016,
+// with the example message split into salt and passphrase.
+func TestPassphraseSM4Key(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		salt       string
+		want       string
+	}{
+		{"abc", "c", "ab", "66c7f0f462eeedd9d1f2d46bdc10e4e2"},
+		{"abc without salt", "abc", "", "66c7f0f462eeedd9d1f2d46bdc10e4e2"},
+		{"abcd x16", strings.Repeat("abcd", 8), strings.Repeat("abcd", 8), "debe9ff92275b8a138604889c18e5a4d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(passphraseSM4Key(tt.passphrase, []byte(tt.salt))); got != tt.want {
+				t.Errorf("passphraseSM4Key() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSM4PassphraseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		salt    string
+		want    string
+		wantErr bool
+	}{
+		{"passphrase", "", hex.EncodeToString([]byte("ab")), "66c7f0f462eeedd9d1f2d46bdc10e4e2", false},
+		{"passphrase over sm4Key", "00112233445566778899aabbccddeeff", hex.EncodeToString([]byte("ab")), "66c7f0f462eeedd9d1f2d46bdc10e4e2", false},
+		{"salt not hex", "", "salt", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := CreateConfig()
+			config.SMAlgorithm = "SM4"
+			config.SM4Key = tt.key
+			config.SM4Passphrase = "c"
+			config.SM4Salt = tt.salt
+
+			handler, err := New(context.Background(), http.NotFoundHandler(), config, "test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			p := handler.(*MyPlugin)
+			defer p.Close()
+			if got := hex.EncodeToString(p.keys.Load().sm4Key); got != tt.want {
+				t.Errorf("sm4Key = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// sm3TrailerHashHeader carries the SM3 hex of a streamed request body, as a trailer when the
+// response is chunked and as a header when it had to be buffered.
+const sm3TrailerHashHeader = "SM3-Trailer-Hash"
+
+// hashingBody feeds everything the next handler reads from the request body to hasher.
+type hashingBody struct {
+	io.Reader
+	io.Closer
+}
+
+func newHashingBody(body io.ReadCloser, hasher hash.Hash) *hashingBody {
+	return &hashingBody{Reader: io.TeeReader(body, hasher), Closer: body}
+}
+
+// trailerWriter passes the response through with the hash trailer declared when the response is
+// chunked. A response with a Content-Length, or to an HTTP/1.0 client, cannot carry trailers, so
+// it is buffered and the hash is sent as a header instead.
+type trailerWriter struct {
+	rw          http.ResponseWriter
+	chunked     bool
+	wroteHeader bool
+	buffering   bool
+	status      int
+	body        bytes.Buffer
+}
+
+func (t *trailerWriter) Header() http.Header {
+	return t.rw.Header()
+}
+
+func (t *trailerWriter) WriteHeader(code int) {
+	if t.wroteHeader {
+		return
+	}
+	t.wroteHeader = true
+	if !t.chunked || t.rw.Header().Get("Content-Length") != "" {
+		t.buffering = true
+		t.status = code
+		return
+	}
+	t.rw.Header().Add("Trailer", sm3TrailerHashHeader)
+	t.rw.WriteHeader(code)
+}
+
+func (t *trailerWriter) Write(b []byte) (int, error) {
+	if !t.wroteHeader {
+		t.WriteHeader(http.StatusOK)
+	}
+	if t.buffering {
+		return t.body.Write(b)
+	}
+	return t.rw.Write(b)
+}
+
+// finish sets the hash, as a trailer or, for a buffered response, as a header before the
+// buffered body is sent.
+func (t *trailerWriter) finish(hashHex string) {
+	if !t.wroteHeader {
+		t.WriteHeader(http.StatusOK)
+	}
+	t.rw.Header().Set(sm3TrailerHashHeader, hashHex)
+	if t.buffering {
+		t.rw.WriteHeader(t.status)
+		t.rw.Write(t.body.Bytes())
+	}
+}
+
+// streamable reports whether req is an SM3 request whose body is large enough to stream. Stream
+// signing, JSON field hashing, timestamp binding, URL hashing, hash chaining and the SM3 auth
+// challenge need the whole body, and the plugin's own endpoints parse it.
+func (p *MyPlugin) streamable(req *http.Request) bool {
+	if p.streamingThreshold <= 0 || req.ContentLength <= p.streamingThreshold || p.algorithmFor(req) != "SM3" {
+		return false
+	}
+	if p.streamSigning || p.sm3AuthChallenge || p.timestampBinding || p.hashURL || p.hashChaining || (len(p.jsonHashFields) > 0 && isJSONRequest(req)) {
+		return false
+	}
+	switch req.URL.Path {
+	case p.batchHashPath, p.verifyPath, p.merklePath:
+		return false
+	}
+	return true
+}
+
+// serveStreamingHash forwards a large body to the next handler while hashing it with SM3, so it
+// is never held in memory. Whatever the next handler leaves unread is drained before the hash
+// is finalized, stored in redis and sent in SM3-Trailer-Hash.
+func (p *MyPlugin) serveStreamingHash(conn redisConn, rw http.ResponseWriter, req *http.Request, requestID string) {
+	hasher := sm3.New()
+	req.Body = newHashingBody(req.Body, hasher)
+
+	writer := &trailerWriter{rw: rw, chunked: req.ProtoAtLeast(1, 1)}
+	p.next.ServeHTTP(writer, req)
+
+	if _, err := io.Copy(io.Discard, req.Body); err != nil {
+		// 请求体不完整, hash 没有意义
+		p.logger.Error("读取流式请求体失败", logFields{"error": err})
+		writer.finish("")
+		return
+	}
+	hashHex := hex.EncodeToString(hasher.Sum(nil))
+	p.logger.Info("加密后的值", hashLogFields(req, hashHex))
+
+	key := p.hashKey(req, hashHex)
+	var err error
+	if p.hashTTL > 0 {
+		_, err = conn.SetEx(key, p.hashTTL, "1")
+	} else {
+		_, err = conn.Set(key, "1")
+	}
+	if err != nil {
+		p.logger.Error("记录请求 hash 失败", logFields{"error": err})
+	}
+	p.recordRequestHash(conn, req, requestID, "SM3", hashHex)
+	if p.publisher != nil {
+		p.publisher.publish("SM3", hashHex, requestID)
+	}
+	p.countAlgorithm(req, "SM3")
+
+	writer.finish(hashHex)
+}
@@ -0,0 +1,7 @@
+module github.com/piaohao/godis
+
+require (
+	github.com/fortytw2/leaktest v1.3.0 // indirect
+	github.com/jolestar/go-commons-pool v2.0.0+incompatible
+	github.com/stretchr/testify v1.3.0
+)
@@ -0,0 +1,38 @@
+package gmsmPlugin
+
+import (
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// timestampWindowHeader lets a client ask for the hash of an earlier time window.
+const timestampWindowHeader = "X-Timestamp-Window"
+
+// timestampWindow returns the window the request's hash is bound to: the current one, or the
+// one in X-Timestamp-Window when it is at most AcceptedWindowDrift windows old.
+func (p *MyPlugin) timestampWindow(req *http.Request) (int64, error) {
+	current := time.Now().Unix() / p.timestampGranularity
+	value := req.Header.Get(timestampWindowHeader)
+	if value == "" {
+		return current, nil
+	}
+	window, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid " + timestampWindowHeader + " header")
+	}
+	if window > current || current-window > int64(p.acceptedWindowDrift) {
+		return 0, errors.New(timestampWindowHeader + " is outside the accepted windows")
+	}
+	return window, nil
+}
+
+// timeBoundInput returns body || BigEndian(window), the input of a time-bound hash.
+func timeBoundInput(body []byte, window int64) []byte {
+	out := make([]byte, len(body)+8)
+	copy(out, body)
+	binary.BigEndian.PutUint64(out[len(body):], uint64(window))
+	return out
+}
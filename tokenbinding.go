@@ -0,0 +1,37 @@
+package gmsmPlugin
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkTokenBinding compares the SM3 fingerprint of the TLS client certificate with the
+// fingerprint the token issuer placed in the token binding header.
+// It writes the rejection and returns false when the binding does not hold.
+func (p *MyPlugin) checkTokenBinding(conn redisConn, rw http.ResponseWriter, req *http.Request) bool {
+	if req.TLS == nil {
+		p.writeError(rw, http.StatusUpgradeRequired, "tls required")
+		return false
+	}
+	if len(req.TLS.PeerCertificates) == 0 {
+		p.writeError(rw, http.StatusUnauthorized, "client certificate required")
+		return false
+	}
+
+	fingerprint := fmt.Sprintf("%x", sm3Sum(req.TLS.PeerCertificates[0].Raw))
+	bound := strings.ToLower(req.Header.Get(p.tokenBindingHeader))
+	if subtle.ConstantTimeCompare([]byte(fingerprint), []byte(bound)) != 1 {
+		p.writeError(rw, http.StatusUnauthorized, "token binding mismatch")
+		return false
+	}
+
+	// 记录成功的绑定, 用于审计
+	if _, err := conn.HSet(p.keyPrefix(req)+":tokenbinding", fingerprint, strconv.FormatInt(time.Now().Unix(), 10)); err != nil {
+		p.logger.Error("记录 token 绑定失败", logFields{"error": err})
+	}
+	return true
+}
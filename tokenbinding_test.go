@@ -0,0 +1,96 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testClientCertificate returns a freshly generated self-signed client certificate.
+func testClientCertificate(t *testing.T, commonName string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestCheckTokenBinding(t *testing.T) {
+	f := newFakeRedis(t)
+	conn := f.conn(t, 0)
+	p := &MyPlugin{
+		redisKeyPrefix:     "gmsm",
+		tokenBindingHeader: "X-Token-Binding",
+		logger:             newLogger(io.Discard, "error"),
+	}
+	cert := testClientCertificate(t, "client-1")
+	other := testClientCertificate(t, "client-2")
+	fingerprint := fmt.Sprintf("%x", sm3Sum(cert.Raw))
+
+	tests := []struct {
+		name       string
+		tls        *tls.ConnectionState
+		binding    string
+		wantOK     bool
+		wantStatus int
+	}{
+		{"plain http", nil, fingerprint, false, http.StatusUpgradeRequired},
+		{"no client certificate", &tls.ConnectionState{}, fingerprint, false, http.StatusUnauthorized},
+		{"bound certificate", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, fingerprint, true, http.StatusOK},
+		{"upper-case fingerprint", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, fmt.Sprintf("%X", sm3Sum(cert.Raw)), true, http.StatusOK},
+		{"other certificate", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{other}}, fingerprint, false, http.StatusUnauthorized},
+		{"missing header", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, "", false, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.TLS = tt.tls
+			if tt.binding != "" {
+				req.Header.Set("X-Token-Binding", tt.binding)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), namespaceContextKey{}, "tenant"))
+			rw := httptest.NewRecorder()
+
+			if got := p.checkTokenBinding(conn, rw, req); got != tt.wantOK {
+				t.Fatalf("checkTokenBinding() = %v, want %v", got, tt.wantOK)
+			}
+			if rw.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rw.Code, tt.wantStatus)
+			}
+		})
+	}
+
+	if _, ok := f.hget(0, "tenant:gmsm:tokenbinding", fingerprint); !ok {
+		t.Error("successful binding not recorded under tenant:gmsm:tokenbinding")
+	}
+	if _, ok := f.hget(0, "gmsm:tokenbinding", fingerprint); ok {
+		t.Error("binding recorded under the unprefixed key")
+	}
+}
@@ -0,0 +1,76 @@
+package gmsmPlugin
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// tokenBucketScript refills the bucket in KEYS[1] (a hash of tokens and last refill time in ms)
+// by ARGV[2] tokens per second up to ARGV[1], then takes one token. It returns {1, 0} when the
+// request is allowed and {0, wait ms until a token is available} otherwise. The clock is the
+// redis server's, so every plugin instance refills the same bucket at the same rate.
+const tokenBucketScript = `
+redis.replicate_commands()
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) / 1000 * rate)
+	ts = now
+end
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', ts)
+-- 桶补满之后的状态与不存在等价, 不必保留
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate * 1000) + 1000)
+return {allowed, wait}
+`
+
+// checkTokenBucket takes one token from the client's bucket. Once the bucket is empty it writes
+// 429 with Retry-After set to the seconds until the next token and returns false.
+// Redis errors are logged and the request is let through.
+func (p *MyPlugin) checkTokenBucket(conn redisConn, rw http.ResponseWriter, req *http.Request) bool {
+	key := p.keyPrefix(req) + ":tokenbucket:" + p.rateLimitClient(req)
+	reply, err := conn.Eval(tokenBucketScript, 1, key,
+		strconv.FormatFloat(p.tokenBucketCapacity, 'g', -1, 64),
+		strconv.FormatFloat(p.tokenBucketRefillRate, 'g', -1, 64))
+	if err != nil {
+		p.logger.Error("令牌桶限流失败", logFields{"error": err})
+		return true
+	}
+	values, _ := reply.([]interface{})
+	if len(values) != 2 {
+		p.logger.Error("令牌桶脚本返回值无效", nil)
+		return true
+	}
+	allowed, _ := values[0].(int64)
+	if allowed == 1 {
+		return true
+	}
+
+	waitMs, _ := values[1].(int64)
+	retryAfter := (waitMs + 999) / 1000
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	rw.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	p.writeError(rw, http.StatusTooManyRequests, "rate limit exceeded")
+	return false
+}
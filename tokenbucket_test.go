@@ -0,0 +1,108 @@
+package gmsmPlugin
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeTokenBucket is the fakeRedis stand-in for tokenBucketScript. *now is the redis clock in
+// milliseconds.
+func fakeTokenBucket(now *int64) fakeScript {
+	return func(call func(args ...string) interface{}, keys, argv []string) interface{} {
+		capacity, _ := strconv.ParseFloat(argv[0], 64)
+		rate, _ := strconv.ParseFloat(argv[1], 64)
+		ms := atomic.LoadInt64(now)
+
+		tokens, ts := capacity, ms
+		storedTokens, _ := call("HGET", keys[0], "tokens").([]byte)
+		storedTS, _ := call("HGET", keys[0], "ts").([]byte)
+		if storedTokens != nil && storedTS != nil {
+			tokens, _ = strconv.ParseFloat(string(storedTokens), 64)
+			ts, _ = strconv.ParseInt(string(storedTS), 10, 64)
+		}
+		if ms > ts {
+			tokens = math.Min(capacity, tokens+float64(ms-ts)/1000*rate)
+			ts = ms
+		}
+
+		allowed, wait := int64(0), int64(0)
+		if tokens >= 1 {
+			tokens--
+			allowed = 1
+		} else {
+			wait = int64(math.Ceil((1 - tokens) / rate * 1000))
+		}
+		call("HSET", keys[0], "tokens", strconv.FormatFloat(tokens, 'g', -1, 64), "ts", strconv.FormatInt(ts, 10))
+		return []interface{}{allowed, wait}
+	}
+}
+
+// A full bucket lets capacity requests through at once, after which traffic at the refill rate
+// keeps passing for good and anything faster is turned away until the next token is due.
+func TestCheckTokenBucket(t *testing.T) {
+	tests := []struct {
+		name           string
+		capacity       float64
+		rate           float64
+		wantRetryAfter string
+	}{
+		{"two per second", 5, 2, "1"},
+		{"one every four seconds", 3, 0.25, "4"},
+		{"fractional rate", 2, 0.4, "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := int64(1_700_000_000_000)
+			f := newFakeRedis(t)
+			f.script(tokenBucketScript, fakeTokenBucket(&now))
+			conn := f.conn(t, 0)
+			p := &MyPlugin{
+				redisKeyPrefix:        "gmsm",
+				tokenBucketCapacity:   tt.capacity,
+				tokenBucketRefillRate: tt.rate,
+				logger:                newLogger(io.Discard, "error"),
+			}
+			take := func() *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, "/", nil)
+				req.RemoteAddr = "203.0.113.7:4000"
+				rw := httptest.NewRecorder()
+				if ok := p.checkTokenBucket(conn, rw, req); ok != (rw.Code == http.StatusOK) {
+					t.Fatalf("checkTokenBucket() = %v with status %d", ok, rw.Code)
+				}
+				return rw
+			}
+			interval := int64(math.Ceil(1000 / tt.rate))
+
+			for i := 0; i < int(tt.capacity); i++ {
+				if rw := take(); rw.Code != http.StatusOK {
+					t.Fatalf("burst request %d: status = %d, want 200", i+1, rw.Code)
+				}
+			}
+			for i := 0; i < 200; i++ {
+				atomic.AddInt64(&now, interval)
+				if rw := take(); rw.Code != http.StatusOK {
+					t.Fatalf("request %d at the refill rate: status = %d, want 200", i+1, rw.Code)
+				}
+			}
+
+			rw := take()
+			if rw.Code != http.StatusTooManyRequests {
+				t.Fatalf("request above the refill rate: status = %d, want 429", rw.Code)
+			}
+			retryAfter := rw.Header().Get("Retry-After")
+			if retryAfter != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %s, want %s", retryAfter, tt.wantRetryAfter)
+			}
+			seconds, _ := strconv.ParseInt(retryAfter, 10, 64)
+			atomic.AddInt64(&now, seconds*1000)
+			if rw := take(); rw.Code != http.StatusOK {
+				t.Errorf("request after Retry-After: status = %d, want 200", rw.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package gmsmPlugin
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/tjfoc/gmsm/sm3"
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+const (
+	tokenizePath   = "/tokenize"
+	detokenizePath = "/detokenize"
+	panLength      = 16
+)
+
+// tokenFormats lists the supported TokenFormat values:
+// "luhn" encrypts the first 15 digits and appends a fresh Luhn check digit,
+// "digits" encrypts all 16 digits and the token may not pass the Luhn check.
+var tokenFormats = map[string]bool{
+	"luhn":   true,
+	"digits": true,
+}
+
+// tokenizePAN maps a PAN to its token with SM4-FF1.
+func (p *MyPlugin) tokenizePAN(pan string) (string, error) {
+	block, err := sm4.NewCipher(p.keys.Load().sm4Key)
+	if err != nil {
+		return "", err
+	}
+	if p.tokenFormat == "digits" {
+		return ff1Decimal(block, nil, pan, false)
+	}
+
+	payload, err := ff1Decimal(block, nil, pan[:panLength-1], false)
+	if err != nil {
+		return "", err
+	}
+	return payload + string(luhnCheckDigit(payload)), nil
+}
+
+// tokenAuth returns the hex HMAC-SM3 of the token under the token auth key.
+func (p *MyPlugin) tokenAuth(token string) string {
+	mac := hmac.New(sm3.New, p.tokenAuthKey)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// serveTokenize replaces a card number with a format-preserving token and keeps the
+// SM4-CBC encrypted card number (hex of iv || ciphertext) in the token vault.
+func (p *MyPlugin) serveTokenize(conn redisConn, rw http.ResponseWriter, body []byte) {
+	var request struct {
+		PAN string `json:"pan"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		p.writeError(rw, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(request.PAN) != panLength || !luhnValid(request.PAN) {
+		p.writeError(rw, http.StatusBadRequest, "invalid pan")
+		return
+	}
+
+	token, err := p.tokenizePAN(request.PAN)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	iv, err := randomIV()
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ciphertext, err := sm4CBCEncrypt(p.keys.Load().sm4Key, iv, []byte(request.PAN))
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if _, err := conn.HSet(p.tokenVaultKey, token, hex.EncodeToString(append(iv, ciphertext...))); err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"token": token})
+}
+
+// serveDetokenize returns the card number for a token when auth is the HMAC-SM3 of the token.
+func (p *MyPlugin) serveDetokenize(conn redisConn, rw http.ResponseWriter, body []byte) {
+	var request struct {
+		Token string `json:"token"`
+		Auth  string `json:"auth"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		p.writeError(rw, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !hmac.Equal([]byte(p.tokenAuth(request.Token)), []byte(request.Auth)) {
+		p.writeError(rw, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	stored, err := conn.HGet(p.tokenVaultKey, request.Token)
+	if err != nil || stored == "" {
+		p.writeError(rw, http.StatusNotFound, "unknown token")
+		return
+	}
+	raw, err := hex.DecodeString(stored)
+	if err != nil || len(raw) <= sm4.BlockSize {
+		p.writeError(rw, http.StatusInternalServerError, "corrupt vault entry")
+		return
+	}
+	pan, err := sm4CBCDecrypt(p.keys.Load().sm4Key, raw[:sm4.BlockSize], raw[sm4.BlockSize:])
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, "corrupt vault entry")
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"pan": string(pan)})
+}
@@ -0,0 +1,105 @@
+package gmsmPlugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testTokenAuthKey = "0f1e2d3c4b5a69788796a5b4c3d2e1f0"
+
+// postJSON sends body to path and decodes the JSON answer into out.
+func postJSON(t *testing.T, p *MyPlugin, path, body string, out interface{}) int {
+	t.Helper()
+	rw := httptest.NewRecorder()
+	p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if err := json.Unmarshal(rw.Body.Bytes(), out); err != nil {
+		t.Fatalf("%s answered %d %s: %v", path, rw.Code, rw.Body, err)
+	}
+	return rw.Code
+}
+
+func TestServeHTTPTokenization(t *testing.T) {
+	pans := []string{"4111111111111111", "5500005555555559", "6011000990139424"}
+
+	for _, format := range []string{"luhn", "digits"} {
+		t.Run(format, func(t *testing.T) {
+			f := newFakeRedis(t)
+			p := newTestPlugin(t, f, func(c *Config) {
+				c.TokenizationEnabled = true
+				c.TokenFormat = format
+				c.SM4Key = testMultiSM4Key
+				c.TokenAuthKey = testTokenAuthKey
+				c.DuplicateAction = "passthrough"
+			})
+
+			for _, pan := range pans {
+				var tokenized struct {
+					Token string `json:"token"`
+				}
+				if code := postJSON(t, p, tokenizePath, `{"pan":"`+pan+`"}`, &tokenized); code != http.StatusOK {
+					t.Fatalf("tokenize %s: status = %d", pan, code)
+				}
+				token := tokenized.Token
+				if len(token) != panLength || strings.Trim(token, "0123456789") != "" || token == pan {
+					t.Errorf("token %q for %s is not a different 16-digit number", token, pan)
+				}
+				if format == "luhn" && !luhnValid(token) {
+					t.Errorf("token %s fails the Luhn check", token)
+				}
+
+				var detokenized struct {
+					PAN string `json:"pan"`
+				}
+				request := `{"token":"` + token + `","auth":"` + p.tokenAuth(token) + `"}`
+				if code := postJSON(t, p, detokenizePath, request, &detokenized); code != http.StatusOK || detokenized.PAN != pan {
+					t.Errorf("detokenize %s = %d %q, want %s", token, code, detokenized.PAN, pan)
+				}
+			}
+		})
+	}
+}
+
+func TestServeHTTPTokenizationErrors(t *testing.T) {
+	f := newFakeRedis(t)
+	p := newTestPlugin(t, f, func(c *Config) {
+		c.TokenizationEnabled = true
+		c.SM4Key = testMultiSM4Key
+		c.TokenAuthKey = testTokenAuthKey
+		c.DuplicateAction = "passthrough"
+	})
+	var tokenized struct {
+		Token string `json:"token"`
+	}
+	if code := postJSON(t, p, tokenizePath, `{"pan":"4111111111111111"}`, &tokenized); code != http.StatusOK {
+		t.Fatalf("tokenize: status = %d", code)
+	}
+	token := tokenized.Token
+	unknown := "4000000000000002"
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"PAN failing the Luhn check", tokenizePath, `{"pan":"4111111111111112"}`, http.StatusBadRequest},
+		{"short PAN", tokenizePath, `{"pan":"411111111111"}`, http.StatusBadRequest},
+		{"not JSON", tokenizePath, `pan=4111111111111111`, http.StatusBadRequest},
+		{"wrong auth", detokenizePath, `{"token":"` + token + `","auth":"` + p.tokenAuth(unknown) + `"}`, http.StatusUnauthorized},
+		{"missing auth", detokenizePath, `{"token":"` + token + `"}`, http.StatusUnauthorized},
+		{"unknown token", detokenizePath, `{"token":"` + unknown + `","auth":"` + p.tokenAuth(unknown) + `"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response struct {
+				Code int `json:"code"`
+			}
+			if code := postJSON(t, p, tt.path, tt.body, &response); code != tt.wantStatus || response.Code != tt.wantStatus {
+				t.Errorf("status = %d, code = %d, want %d", code, response.Code, tt.wantStatus)
+			}
+		})
+	}
+}
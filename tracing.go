@@ -0,0 +1,97 @@
+package gmsmPlugin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context headers.
+const (
+	traceparentHeader = "traceparent"
+	tracestateHeader  = "tracestate"
+)
+
+// traceContextKey carries the request's trace ID in its context.
+type traceContextKey struct{}
+
+// isLowerHex reports whether s is lowercase hex.
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isTraceID reports whether s is a valid trace or span ID: lowercase hex, not all zeros.
+func isTraceID(s string) bool {
+	return isLowerHex(s) && strings.Trim(s, "0") != ""
+}
+
+// parseTraceparent splits a version-traceId-parentId-flags traceparent header. Versions after
+// 00 may append fields, which are ignored; version ff is invalid.
+func parseTraceparent(s string) (traceID, parentID, flags string, ok bool) {
+	if len(s) < 55 || (len(s) > 55 && s[55] != '-') {
+		return "", "", "", false
+	}
+	version := s[:2]
+	if version == "ff" || (version == "00" && len(s) != 55) || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return "", "", "", false
+	}
+	traceID, parentID, flags = s[3:35], s[36:52], s[53:55]
+	if !isLowerHex(version) || !isTraceID(traceID) || !isTraceID(parentID) || !isLowerHex(flags) {
+		return "", "", "", false
+	}
+	return traceID, parentID, flags, true
+}
+
+// randomHex returns n random bytes in hex, never all zeros.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	for {
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		if s := hex.EncodeToString(b); strings.Trim(s, "0") != "" {
+			return s, nil
+		}
+	}
+}
+
+// withTrace continues the request's trace, or starts a sampled one when traceparent is missing or
+// invalid, and gives the request passed on a new span ID as its parent. tracestate is passed
+// through unchanged for a continued trace and dropped for a new one.
+func (p *MyPlugin) withTrace(req *http.Request) *http.Request {
+	traceID, _, flags, ok := parseTraceparent(req.Header.Get(traceparentHeader))
+	if !ok {
+		var err error
+		if traceID, err = randomHex(16); err != nil {
+			p.logger.Error("生成 trace ID 失败", logFields{"error": err})
+			return req
+		}
+		flags = "01"
+		req.Header.Del(tracestateHeader)
+	}
+	spanID, err := randomHex(8)
+	if err != nil {
+		p.logger.Error("生成 span ID 失败", logFields{"error": err})
+		return req
+	}
+
+	req.Header.Set(traceparentHeader, "00-"+traceID+"-"+spanID+"-"+flags)
+	return req.WithContext(context.WithValue(req.Context(), traceContextKey{}, traceID))
+}
+
+// hashLogFields are the log fields of a hash operation: the hash and, with tracing enabled, the
+// trace ID.
+func hashLogFields(req *http.Request, hashHex string) logFields {
+	fields := logFields{"hash": hashHex}
+	if traceID, ok := req.Context().Value(traceContextKey{}).(string); ok {
+		fields["traceId"] = traceID
+	}
+	return fields
+}
@@ -0,0 +1,135 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	testTraceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testParentID = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		wantOK bool
+	}{
+		{"sampled", "00-" + testTraceID + "-" + testParentID + "-01", true},
+		{"not sampled", "00-" + testTraceID + "-" + testParentID + "-00", true},
+		{"future version with extra fields", "01-" + testTraceID + "-" + testParentID + "-01-extra", true},
+		{"version 00 with extra fields", "00-" + testTraceID + "-" + testParentID + "-01-extra", false},
+		{"version ff", "ff-" + testTraceID + "-" + testParentID + "-01", false},
+		{"zero trace ID", "00-" + strings.Repeat("0", 32) + "-" + testParentID + "-01", false},
+		{"zero parent ID", "00-" + testTraceID + "-" + strings.Repeat("0", 16) + "-01", false},
+		{"upper case", "00-" + strings.ToUpper(testTraceID) + "-" + testParentID + "-01", false},
+		{"short", "00-" + testTraceID[:30] + "-" + testParentID + "-01", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceID, parentID, _, ok := parseTraceparent(tt.header)
+			if ok != tt.wantOK {
+				t.Fatalf("parseTraceparent(%q) ok = %v, want %v", tt.header, ok, tt.wantOK)
+			}
+			if ok && (traceID != testTraceID || parentID != testParentID) {
+				t.Errorf("parseTraceparent() = %s, %s", traceID, parentID)
+			}
+		})
+	}
+}
+
+func TestWithTrace(t *testing.T) {
+	p := &MyPlugin{logger: newLogger(io.Discard, "error")}
+
+	tests := []struct {
+		name        string
+		traceparent string
+		// wantTraceID is empty when a new trace should be started
+		wantTraceID string
+		wantFlags   string
+		wantState   bool
+	}{
+		{"continued", "00-" + testTraceID + "-" + testParentID + "-00", testTraceID, "00", true},
+		{"missing", "", "", "01", false},
+		{"invalid", "00-" + testTraceID + "-" + testParentID, "", "01", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := make(map[string]bool)
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/", nil)
+				if tt.traceparent != "" {
+					req.Header.Set(traceparentHeader, tt.traceparent)
+				}
+				req.Header.Set(tracestateHeader, "vendor=1")
+				req = p.withTrace(req)
+
+				traceID, spanID, flags, ok := parseTraceparent(req.Header.Get(traceparentHeader))
+				if !ok {
+					t.Fatalf("traceparent %q is not valid", req.Header.Get(traceparentHeader))
+				}
+				if tt.wantTraceID != "" && traceID != tt.wantTraceID || tt.wantTraceID == "" && traceID == testTraceID {
+					t.Errorf("trace ID = %s, want %s", traceID, tt.wantTraceID)
+				}
+				if spanID == testParentID || spans[spanID] {
+					t.Errorf("span ID %s was not regenerated", spanID)
+				}
+				spans[spanID] = true
+				if flags != tt.wantFlags {
+					t.Errorf("flags = %s, want %s", flags, tt.wantFlags)
+				}
+				if got := req.Header.Get(tracestateHeader) != ""; got != tt.wantState {
+					t.Errorf("tracestate kept = %v, want %v", got, tt.wantState)
+				}
+				if got := hashLogFields(req, "h")["traceId"]; got != traceID {
+					t.Errorf("log traceId = %v, want %s", got, traceID)
+				}
+			}
+		})
+	}
+}
+
+// The next handler sees the trace continued under a new span, and the hash is logged with the
+// trace ID.
+func TestServeHTTPTracing(t *testing.T) {
+	f := newFakeRedis(t)
+	p := newTestPlugin(t, f, func(c *Config) {
+		c.TracingEnabled = true
+		c.HashOutputMode = "header"
+	})
+	var logs bytes.Buffer
+	p.logger = newLogger(&logs, "info")
+	var forwarded string
+	p.next = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) { forwarded = req.Header.Get(traceparentHeader) })
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("traced"))
+	req.Header.Set(traceparentHeader, "00-"+testTraceID+"-"+testParentID+"-01")
+	p.ServeHTTP(httptest.NewRecorder(), req)
+
+	traceID, spanID, _, ok := parseTraceparent(forwarded)
+	if !ok || traceID != testTraceID || spanID == testParentID {
+		t.Errorf("forwarded traceparent = %q, want trace %s under a new span", forwarded, testTraceID)
+	}
+	var logged bool
+	for _, line := range strings.Split(logs.String(), "\n") {
+		var entry struct {
+			Fields struct {
+				Hash    string `json:"hash"`
+				TraceID string `json:"traceId"`
+			} `json:"fields"`
+		}
+		if json.Unmarshal([]byte(line), &entry) == nil && entry.Fields.Hash != "" {
+			logged = entry.Fields.TraceID == testTraceID
+		}
+	}
+	if !logged {
+		t.Errorf("hash was not logged with trace ID %s: %s", testTraceID, logs.String())
+	}
+}
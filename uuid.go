@@ -0,0 +1,30 @@
+package gmsmPlugin
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// parseUUID decodes a UUID in the 8-4-4-4-12 hex form to its 16 bytes.
+func parseUUID(s string) ([]byte, error) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return nil, errors.New("invalid UUID")
+	}
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil {
+		return nil, errors.New("invalid UUID")
+	}
+	return b, nil
+}
+
+// SM3UUID is the SM3 counterpart of a version 5 UUID: the first 16 bytes of
+// SM3(namespace || name) with the RFC 9562 version 8 (custom) and variant bits set. For the
+// DNS namespace 6ba7b810-9dad-11d1-80b4-00c04fd430c8 and the name "hello" it is
+// 409104de-5a40-81e5-85f5-690d9a05343a.
+func SM3UUID(namespace, name []byte) string {
+	b := sm3Sum(append(append([]byte(nil), namespace...), name...))[:16]
+	b[6] = b[6]&0x0f | 0x80 // version 8
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return formatUUID(b)
+}
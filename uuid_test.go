@@ -0,0 +1,89 @@
+package gmsmPlugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testDNSNamespace is the RFC 4122 name space ID for fully-qualified domain names.
+const testDNSNamespace = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+func TestSM3UUID(t *testing.T) {
+	namespace, err := parseUUID(testDNSNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"hello", "409104de-5a40-81e5-85f5-690d9a05343a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SM3UUID(namespace, []byte(tt.name)); got != tt.want {
+				t.Errorf("SM3UUID(%s, %q) = %s, want %s", testDNSNamespace, tt.name, got, tt.want)
+			}
+		})
+	}
+
+	for _, name := range []string{"", "world", strings.Repeat("x", 1000)} {
+		got := SM3UUID(namespace, []byte(name))
+		if got[14] != '8' || !strings.ContainsRune("89ab", rune(got[19])) {
+			t.Errorf("SM3UUID(%q) = %s lacks the version 8 and RFC 4122 variant bits", name, got)
+		}
+	}
+}
+
+func TestParseUUID(t *testing.T) {
+	tests := []struct {
+		s       string
+		wantErr bool
+	}{
+		{testDNSNamespace, false},
+		{strings.ToUpper(testDNSNamespace), false},
+		{strings.ReplaceAll(testDNSNamespace, "-", ""), true},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c", true},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430cg", true},
+		{"6ba7b8109-dad-11d1-80b4-00c04fd430c8", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			b, err := parseUUID(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseUUID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && formatUUID(b) != strings.ToLower(tt.s) {
+				t.Errorf("formatUUID(parseUUID(%s)) = %s", tt.s, formatUUID(b))
+			}
+		})
+	}
+}
+
+// The uuid is returned next to the SM3 result.
+func TestServeHTTPUUID(t *testing.T) {
+	f := newFakeRedis(t)
+	p := newTestPlugin(t, f, func(c *Config) {
+		c.GenerateUUID = true
+		c.UUIDNamespace = testDNSNamespace
+		c.ForwardToNext = false
+	})
+
+	rw := httptest.NewRecorder()
+	p.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello")))
+
+	var response struct {
+		Result string `json:"result"`
+		UUID   string `json:"uuid"`
+	}
+	if err := json.Unmarshal(rw.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%v: %s", err, rw.Body)
+	}
+	if response.UUID != "409104de-5a40-81e5-85f5-690d9a05343a" || response.Result == "" {
+		t.Errorf("response = %s, want the SM3 result with uuid 409104de-5a40-81e5-85f5-690d9a05343a", rw.Body)
+	}
+}
@@ -0,0 +1,106 @@
+package gmsmPlugin
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// verifyRequest is the body of a request to VerifyPath.
+type verifyRequest struct {
+	Data *string `json:"data"`
+	Key  string  `json:"key"`
+	Hash string  `json:"hash"`
+
+	// 校验 SM2 签名时使用: 公钥为 hex 的曲线点, 或者 AcceptCertAsPublicKey 时的 PEM 证书
+	Signature string `json:"signature"`
+	PublicKey string `json:"publicKey"`
+	Cert      string `json:"cert"`
+}
+
+// serveVerify compares a supplied SM3 hex hash with the SM3 of base64 "data" or, with
+// VerifyFromRedis, with the hash stored at <prefix>:<key>. The comparison is constant-time and
+// a missing key compares like a wrong hash, so the answer time does not depend on the result.
+// A request with a "signature" verifies an SM2 signature over "data" instead.
+func (p *MyPlugin) serveVerify(conn redisConn, rw http.ResponseWriter, req *http.Request, body []byte) {
+	var request verifyRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		p.writeError(rw, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if request.Signature != "" {
+		p.verifySignature(rw, &request)
+		return
+	}
+
+	expected, err := hex.DecodeString(request.Hash)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, "hash must be a hex string")
+		return
+	}
+
+	var actual []byte
+	switch {
+	case request.Data != nil:
+		data, err := base64.StdEncoding.DecodeString(*request.Data)
+		if err != nil {
+			p.writeError(rw, http.StatusBadRequest, "data must be base64")
+			return
+		}
+		actual = sm3Sum(data)
+	case request.Key != "" && p.verifyFromRedis:
+		stored, err := conn.Get(p.keyPrefix(req) + ":" + request.Key)
+		if err != nil {
+			p.logger.Error("读取已存储的 hash 失败", logFields{"error": err})
+			p.writeError(rw, http.StatusServiceUnavailable, "redis unavailable")
+			return
+		}
+		// 不存在或格式不对时与 32 个零字节比较, 和不匹配的 hash 走相同的路径
+		actual = make([]byte, 32)
+		if decoded, err := hex.DecodeString(stored); err == nil && len(decoded) == len(actual) {
+			actual = decoded
+		}
+	default:
+		p.writeError(rw, http.StatusBadRequest, "data or key is required")
+		return
+	}
+
+	match := subtle.ConstantTimeCompare(actual, expected) == 1
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"code": 0, "match": match})
+}
+
+// verifySignature checks an SM2 signature (any encoding VerifySM2Signature accepts) over base64
+// "data" with the hex "publicKey" or, with AcceptCertAsPublicKey, the key of the PEM "cert".
+func (p *MyPlugin) verifySignature(rw http.ResponseWriter, request *verifyRequest) {
+	if request.Data == nil {
+		p.writeError(rw, http.StatusBadRequest, "data is required")
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(*request.Data)
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, "data must be base64")
+		return
+	}
+
+	var pub *sm2.PublicKey
+	switch {
+	case request.Cert != "" && p.acceptCertAsPublicKey:
+		pub, err = ParseSM2PublicKeyFromCertPEM([]byte(request.Cert))
+	case request.PublicKey != "":
+		pub, err = parseSM2PublicKeyHex(request.PublicKey)
+	default:
+		p.writeError(rw, http.StatusBadRequest, "publicKey is required")
+		return
+	}
+	if err != nil {
+		p.writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	match := VerifySM2Signature(pub, data, request.Signature)
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"code": 0, "match": match})
+}
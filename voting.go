@@ -0,0 +1,96 @@
+package gmsmPlugin
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+const (
+	votePath  = "/vote"
+	tallyPath = "/tally"
+)
+
+// serveVote stores an SM2 encrypted vote and returns a receipt SM3(commitment || timestamp).
+// Receipts are kept in the <VotingTallyKey>:receipts hash so voters can check their vote was recorded.
+func (p *MyPlugin) serveVote(conn redisConn, rw http.ResponseWriter, body []byte) {
+	var request struct {
+		Commitment string `json:"commitment"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		p.writeError(rw, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	commitment := strings.ToLower(request.Commitment)
+	// 0x04 || C1(64) || C3(32) || C2
+	if raw, err := hex.DecodeString(commitment); err != nil || len(raw) <= 97 {
+		p.writeError(rw, http.StatusBadRequest, "invalid commitment")
+		return
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+	receipt := hex.EncodeToString(sm3Sum([]byte(commitment + timestamp)))
+
+	if _, err := conn.RPush(p.votingTallyKey, commitment); err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if _, err := conn.HSet(p.votingTallyKey+":receipts", receipt, commitment); err != nil {
+		p.logger.Error("保存投票回执失败", logFields{"error": err})
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"receipt": receipt, "timestamp": timestamp, "code": 0})
+}
+
+// serveTally decrypts every stored vote and returns the per-choice counts together with the
+// SM3 Merkle root over the commitments in the order they were counted.
+func (p *MyPlugin) serveTally(conn redisConn, rw http.ResponseWriter, req *http.Request) {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if p.votingAdminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(p.votingAdminToken)) != 1 {
+		p.writeError(rw, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if p.votingPrivateKey == nil {
+		p.writeError(rw, http.StatusServiceUnavailable, "voting private key not configured")
+		return
+	}
+
+	commitments, err := conn.LRange(p.votingTallyKey, 0, -1)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tally := map[string]int{}
+	invalid := 0
+	leaves := make([][]byte, 0, len(commitments))
+	for _, commitment := range commitments {
+		raw, err := hex.DecodeString(commitment)
+		if err != nil {
+			invalid++
+			continue
+		}
+		leaves = append(leaves, raw)
+
+		vote, err := sm2.Decrypt(p.votingPrivateKey, raw, sm2.C1C3C2)
+		if err != nil {
+			invalid++
+			continue
+		}
+		tally[string(vote)]++
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{
+		"tally":      tally,
+		"total":      len(commitments),
+		"invalid":    invalid,
+		"merkleRoot": hex.EncodeToString(merkleTreeRoot(buildMerkleTree(leaves))),
+		"code":       0,
+	})
+}
@@ -0,0 +1,146 @@
+package gmsmPlugin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// vrfSuite is the suite string mixed into every VRF hash, in the spirit of draft-goldbe-vrf-01.
+var vrfSuite = []byte("SM2-SM3-VRF")
+
+// VRFProof is the proof pi = (gamma, c, s) of an SM2 VRF evaluation.
+type VRFProof struct {
+	Gamma string `json:"gamma"`
+	C     string `json:"c"`
+	S     string `json:"s"`
+}
+
+// vrfHashToCurve maps alpha to a point on the SM2 curve using try-and-increment:
+// the first SM3(suite || 0x01 || pk || alpha || ctr) that is a valid x coordinate wins.
+func vrfHashToCurve(pub *sm2.PublicKey, alpha []byte) (*big.Int, *big.Int, error) {
+	pk := marshalPoint(pub.X, pub.Y)
+	for ctr := 0; ctr < 256; ctr++ {
+		hasher := sm3.New()
+		hasher.Write(vrfSuite)
+		hasher.Write([]byte{0x01})
+		hasher.Write(pk)
+		hasher.Write(alpha)
+		hasher.Write([]byte{byte(ctr)})
+
+		if x, y := unmarshalPoint(append([]byte{0x02}, hasher.Sum(nil)...)); x != nil {
+			return x, y, nil
+		}
+	}
+	return nil, nil, errors.New("VRF: hash to curve failed")
+}
+
+// vrfChallenge computes c = SM3(suite || 0x02 || H || gamma || U || V) mod n.
+func vrfChallenge(points ...*big.Int) *big.Int {
+	hasher := sm3.New()
+	hasher.Write(vrfSuite)
+	hasher.Write([]byte{0x02})
+	for i := 0; i < len(points); i += 2 {
+		hasher.Write(marshalPoint(points[i], points[i+1]))
+	}
+	c := new(big.Int).SetBytes(hasher.Sum(nil))
+	return c.Mod(c, sm2.P256Sm2().Params().N)
+}
+
+// vrfProve evaluates the VRF on alpha and returns the output beta = SM3(gamma) and its proof.
+func vrfProve(priv *sm2.PrivateKey, alpha []byte) ([]byte, *VRFProof, error) {
+	curve := sm2.P256Sm2()
+	n := curve.Params().N
+
+	hx, hy, err := vrfHashToCurve(&priv.PublicKey, alpha)
+	if err != nil {
+		return nil, nil, err
+	}
+	gx, gy := curve.ScalarMult(hx, hy, scalarBytes(priv.D))
+
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(n, big.NewInt(1)))
+	if err != nil {
+		return nil, nil, err
+	}
+	k.Add(k, big.NewInt(1))
+	ux, uy := curve.ScalarBaseMult(scalarBytes(k))
+	vx, vy := curve.ScalarMult(hx, hy, scalarBytes(k))
+
+	// s = k + c*x mod n
+	c := vrfChallenge(hx, hy, gx, gy, ux, uy, vx, vy)
+	s := new(big.Int).Mul(c, priv.D)
+	s.Add(s, k)
+	s.Mod(s, n)
+
+	gamma := marshalPoint(gx, gy)
+	proof := &VRFProof{
+		Gamma: hex.EncodeToString(gamma),
+		C:     hex.EncodeToString(scalarBytes(c)),
+		S:     hex.EncodeToString(scalarBytes(s)),
+	}
+	return sm3Sum(gamma), proof, nil
+}
+
+// VerifyVRF checks the proof of a VRF evaluation on alpha with the prover's public key
+// and returns the VRF output beta when the proof is valid.
+func VerifyVRF(pub *sm2.PublicKey, alpha []byte, proof *VRFProof) ([]byte, bool) {
+	curve := sm2.P256Sm2()
+	n := curve.Params().N
+
+	gamma, err := hex.DecodeString(proof.Gamma)
+	if err != nil {
+		return nil, false
+	}
+	gx, gy := unmarshalPoint(gamma)
+	if gx == nil {
+		return nil, false
+	}
+	cBytes, err := hex.DecodeString(proof.C)
+	if err != nil || len(cBytes) != 32 {
+		return nil, false
+	}
+	sBytes, err := hex.DecodeString(proof.S)
+	if err != nil || len(sBytes) != 32 {
+		return nil, false
+	}
+	c := new(big.Int).SetBytes(cBytes)
+	s := new(big.Int).SetBytes(sBytes)
+	if c.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return nil, false
+	}
+
+	hx, hy, err := vrfHashToCurve(pub, alpha)
+	if err != nil {
+		return nil, false
+	}
+
+	// U = s*G - c*Y, V = s*H - c*gamma
+	negC := scalarBytes(new(big.Int).Sub(n, c))
+	sgx, sgy := curve.ScalarBaseMult(scalarBytes(s))
+	cyx, cyy := curve.ScalarMult(pub.X, pub.Y, negC)
+	ux, uy := curve.Add(sgx, sgy, cyx, cyy)
+	shx, shy := curve.ScalarMult(hx, hy, scalarBytes(s))
+	cgx, cgy := curve.ScalarMult(gx, gy, negC)
+	vx, vy := curve.Add(shx, shy, cgx, cgy)
+
+	if vrfChallenge(hx, hy, gx, gy, ux, uy, vx, vy).Cmp(c) != 0 {
+		return nil, false
+	}
+	return sm3Sum(gamma), true
+}
+
+// serveVRF evaluates the SM2 VRF on the body.
+func (p *MyPlugin) serveVRF(rw http.ResponseWriter, body []byte) {
+	beta, proof, err := vrfProve(p.sm2PrivateKey, body)
+	if err != nil {
+		p.writeError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, map[string]interface{}{"output": hex.EncodeToString(beta), "proof": proof})
+}
@@ -0,0 +1,83 @@
+package gmsmPlugin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifyVRF(t *testing.T) {
+	key, other := testSM2Key(t), testSM2Key(t)
+	alpha := []byte("lottery round 42")
+	beta, proof, err := vrfProve(key, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flip flips the low bit of byte i of the hex field s
+	flip := func(s string, i int) string {
+		raw, _ := hex.DecodeString(s)
+		raw[i] ^= 1
+		return hex.EncodeToString(raw)
+	}
+
+	tests := []struct {
+		name     string
+		useOther bool
+		alpha    []byte
+		proof    VRFProof
+		wantOK   bool
+	}{
+		{"valid proof", false, alpha, *proof, true},
+		{"different alpha", false, []byte("lottery round 43"), *proof, false},
+		{"gamma negated", false, alpha, VRFProof{flip(proof.Gamma, 0), proof.C, proof.S}, false},
+		{"gamma tampered", false, alpha, VRFProof{flip(proof.Gamma, 32), proof.C, proof.S}, false},
+		{"c tampered", false, alpha, VRFProof{proof.Gamma, flip(proof.C, 31), proof.S}, false},
+		{"s tampered", false, alpha, VRFProof{proof.Gamma, proof.C, flip(proof.S, 31)}, false},
+		{"c truncated", false, alpha, VRFProof{proof.Gamma, proof.C[:62], proof.S}, false},
+		{"not hex", false, alpha, VRFProof{"zz", proof.C, proof.S}, false},
+		{"different public key", true, alpha, *proof, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := &key.PublicKey
+			if tt.useOther {
+				pub = &other.PublicKey
+			}
+			got, ok := VerifyVRF(pub, tt.alpha, &tt.proof)
+			if ok != tt.wantOK {
+				t.Fatalf("VerifyVRF() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !bytes.Equal(got, beta) {
+				t.Errorf("VerifyVRF() beta = %x, want %x", got, beta)
+			}
+		})
+	}
+}
+
+// The output is a function of the key and alpha only, whatever nonce the proof used.
+func TestVRFDeterministic(t *testing.T) {
+	key := testSM2Key(t)
+	alpha := []byte("lottery round 42")
+
+	beta1, proof1, err := vrfProve(key, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta2, proof2, err := vrfProve(key, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(beta1, beta2) || proof1.Gamma != proof2.Gamma {
+		t.Error("two evaluations on the same alpha differ")
+	}
+	if proof1.S == proof2.S {
+		t.Error("two proofs share their nonce")
+	}
+	other, _, err := vrfProve(key, []byte("lottery round 43"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(beta1, other) {
+		t.Error("different alphas give the same output")
+	}
+}